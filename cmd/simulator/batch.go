@@ -0,0 +1,120 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/metrics"
+	"auction-simulator/internal/simulation"
+	"auction-simulator/internal/store"
+)
+
+// runBatch repeats the simulation with different seeds and prints the
+// aggregated metrics with confidence intervals
+func runBatch(args []string) error {
+	fs := flag.NewFlagSet("batch", flag.ExitOnError)
+	runs := fs.Int("runs", 10, "number of simulation runs")
+	parallel := fs.Int("parallel", 0, "runs executing at once (0 uses one per vCPU)")
+	seed := fs.Int64("seed", 0, "base seed; each run derives its own (0 picks one from the clock)")
+	confidence := fs.Float64("confidence", 0.95, "confidence level for the aggregated intervals")
+	dbPath := fs.String("db", store.DefaultPath, "SQLite run history database for per-run drill-down (empty disables)")
+	verbose := fs.Bool("verbose", false, "keep per-auction log output")
+	var run runFlags
+	run.register(fs)
+	fs.Parse(args)
+
+	cfg := config.DefaultConfig()
+	if *seed != 0 {
+		cfg.Seed = *seed
+	}
+	if _, _, err := run.apply(cfg); err != nil {
+		return err
+	}
+
+	if err := validateEnvironment(cfg); err != nil {
+		return fmt.Errorf("environment validation failed: %w", err)
+	}
+	applyResourceLimits(cfg)
+
+	fmt.Printf("Running batch of %d simulations (base seed %d)...\n", *runs, cfg.Seed)
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	batch, err := simulation.RunBatch(ctx, cfg, simulation.BatchOptions{
+		Runs:       *runs,
+		Parallel:   *parallel,
+		Confidence: *confidence,
+		Log:        runLog(*verbose),
+	})
+	if batch == nil {
+		return err
+	}
+
+	// An interrupted batch still saves the runs that finished
+	printBatchSummary(batch.Summary)
+
+	reporter := metrics.NewReporter("output")
+	if err := reporter.SaveBatchSummary(batch.Summary); err != nil {
+		log.Printf("Warning: Could not save batch summary: %v", err)
+	}
+
+	if *dbPath != "" {
+		if err := saveBatchHistory(*dbPath, cfg, batch); err != nil {
+			log.Printf("Warning: Could not save batch history: %v", err)
+		}
+	}
+	return err
+}
+
+// printBatchSummary prints per-metric mean, spread and confidence interval
+func printBatchSummary(summary *metrics.BatchSummary) {
+	separator := strings.Repeat("=", 96)
+	lineSeparator := strings.Repeat("-", 96)
+
+	fmt.Printf("\n%s\n", separator)
+	fmt.Printf("BATCH %s: %d runs\n", summary.BatchID, summary.Runs)
+	if summary.Incomplete {
+		fmt.Printf("INCOMPLETE: interrupted before every run finished\n")
+	}
+	fmt.Printf("%s\n", separator)
+	fmt.Printf("%-22s %-12s %-12s %-28s %-10s %-10s\n",
+		"Metric", "Mean", "Std Dev", fmt.Sprintf("%.0f%% CI", summary.Confidence*100), "Min", "Max")
+	fmt.Printf("%s\n", lineSeparator)
+
+	for _, m := range summary.Metrics {
+		fmt.Printf("%-22s %-12.3f %-12.3f %-28s %-10.3f %-10.3f\n",
+			m.Name, m.Mean, m.StdDev, fmt.Sprintf("[%.3f, %.3f]", m.CI.Lower, m.CI.Upper), m.Min, m.Max)
+	}
+
+	fmt.Printf("%s\n", lineSeparator)
+	fmt.Printf("Individual runs:\n")
+	for _, run := range summary.RunMetrics {
+		fmt.Printf("   %s  seed=%-20d revenue=$%-10.2f success=%d/%d\n",
+			run.RunID, run.Seed, run.TotalRevenue, run.SuccessfulAuctions, run.TotalAuctions)
+	}
+}
+
+// saveBatchHistory stores every run of a batch and the batch summary
+func saveBatchHistory(dbPath string, cfg *config.Config, batch *simulation.Batch) error {
+	st, err := store.Open(dbPath)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	for _, run := range batch.Runs {
+		if err := st.SaveRun(run); err != nil {
+			return err
+		}
+	}
+	if err := st.SaveBatch(batch.Summary, cfg); err != nil {
+		return err
+	}
+
+	log.Printf("Batch %s and %d runs saved to: %s", batch.Summary.BatchID, len(batch.Runs), dbPath)
+	return nil
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"auction-simulator/internal/compare"
+	"auction-simulator/internal/metrics"
+	"auction-simulator/internal/store"
+)
+
+// exitRegression is the process exit code when a comparison finds a regression
+const exitRegression = 3
+
+// runCompare compares one or more candidate runs against a baseline run and
+// reports whether any candidate regressed
+func runCompare(args []string) (bool, error) {
+	defaults := compare.DefaultOptions()
+
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	dbPath := fs.String("db", store.DefaultPath, "SQLite run history database for run IDs")
+	threshold := fs.Float64("threshold", defaults.Threshold, "relative change in the bad direction that fails the comparison")
+	confidence := fs.Float64("confidence", defaults.Confidence, "confidence level for bootstrap intervals")
+	resamples := fs.Int("resamples", defaults.Resamples, "bootstrap resamples per metric")
+	seed := fs.Int64("seed", defaults.Seed, "seed for bootstrap resampling")
+	asJSON := fs.Bool("json", false, "print comparisons as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: simulator compare [flags] <baseline> <candidate> [candidate...]\n\n")
+		fmt.Fprintf(fs.Output(), "Each run is a run ID from the history database or a saved metrics JSON file.\n")
+		fmt.Fprintf(fs.Output(), "Exits with status %d when any candidate regresses.\n\n", exitRegression)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return false, fmt.Errorf("compare needs a baseline and at least one candidate")
+	}
+
+	opts := compare.Options{
+		Threshold:  *threshold,
+		Confidence: *confidence,
+		Resamples:  *resamples,
+		Seed:       *seed,
+	}
+
+	loader := &runLoader{dbPath: *dbPath}
+	defer loader.Close()
+
+	baseline, err := loader.Load(fs.Arg(0))
+	if err != nil {
+		return false, err
+	}
+
+	var comparisons []*compare.Comparison
+	regressed := false
+	for _, arg := range fs.Args()[1:] {
+		candidate, err := loader.Load(arg)
+		if err != nil {
+			return false, err
+		}
+
+		comparison := compare.Compare(baseline, candidate, opts)
+		comparisons = append(comparisons, comparison)
+		regressed = regressed || comparison.Regressed()
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return regressed, encoder.Encode(comparisons)
+	}
+
+	for _, comparison := range comparisons {
+		printComparison(comparison, opts)
+	}
+	return regressed, nil
+}
+
+// runLoader resolves run references from files or the history database
+type runLoader struct {
+	dbPath string
+	st     *store.Store
+}
+
+// Load reads a saved metrics file if arg is a path, otherwise a stored run
+func (l *runLoader) Load(arg string) (*metrics.RunReport, error) {
+	if _, err := os.Stat(arg); err == nil {
+		return loadMetricsFile(arg)
+	}
+
+	if l.st == nil {
+		st, err := store.Open(l.dbPath)
+		if err != nil {
+			return nil, err
+		}
+		l.st = st
+	}
+	return l.st.LoadRun(arg)
+}
+
+// Close releases the history database if it was opened
+func (l *runLoader) Close() {
+	if l.st != nil {
+		l.st.Close()
+	}
+}
+
+// loadMetricsFile reads a SimulationMetrics JSON file written by SaveMetrics
+func loadMetricsFile(path string) (*metrics.RunReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read metrics file: %w", err)
+	}
+
+	var simulationMetrics metrics.SimulationMetrics
+	if err := json.Unmarshal(data, &simulationMetrics); err != nil {
+		return nil, fmt.Errorf("could not decode metrics file %s: %w", path, err)
+	}
+	if simulationMetrics.RunID == "" {
+		simulationMetrics.RunID = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	return &metrics.RunReport{Metrics: &simulationMetrics}, nil
+}
+
+// printComparison prints one candidate's deltas as a table
+func printComparison(c *compare.Comparison, opts compare.Options) {
+	separator := strings.Repeat("=", 100)
+	lineSeparator := strings.Repeat("-", 100)
+
+	fmt.Printf("\n%s\n", separator)
+	fmt.Printf("Baseline:  %s\n", c.BaselineID)
+	fmt.Printf("Candidate: %s\n", c.CandidateID)
+	fmt.Printf("%s\n", separator)
+	fmt.Printf("%-22s %-12s %-12s %-10s %-26s %-8s\n",
+		"Metric", "Baseline", "Candidate", "Change", fmt.Sprintf("%.0f%% CI of delta", opts.Confidence*100), "Status")
+	fmt.Printf("%s\n", lineSeparator)
+
+	for _, m := range c.Metrics {
+		ci := "n/a"
+		if m.CI != nil {
+			ci = fmt.Sprintf("[%.3f, %.3f]", m.CI.Lower, m.CI.Upper)
+		}
+		fmt.Printf("%-22s %-12.3f %-12.3f %-10s %-26s %-8s\n",
+			m.Name, m.Baseline, m.Candidate, fmt.Sprintf("%+.1f%%", m.RelDelta*100), ci,
+			metricStatus(m))
+	}
+
+	if c.WinShift != nil {
+		fmt.Printf("%-22s TV distance %.3f, chi2=%.1f (df=%d), p=%.4f %s\n",
+			"win_distribution", c.WinShift.TotalVariation, c.WinShift.ChiSquare, c.WinShift.DF,
+			c.WinShift.PValue, comparisonStatus(c.WinShift.Significant, false))
+	}
+
+	fmt.Printf("%s\n", lineSeparator)
+	if c.Regressed() {
+		fmt.Printf("Result: REGRESSION (threshold %.1f%%)\n", opts.Threshold*100)
+	} else {
+		fmt.Printf("Result: OK (threshold %.1f%%)\n", opts.Threshold*100)
+	}
+}
+
+// metricStatus labels a metric delta for the comparison table
+func metricStatus(m compare.MetricDelta) string {
+	if m.Missing {
+		return "missing"
+	}
+	return comparisonStatus(m.Significant, m.Regressed)
+}
+
+// comparisonStatus labels a comparison row
+func comparisonStatus(significant, regressed bool) string {
+	switch {
+	case regressed:
+		return "REGRESSED"
+	case significant:
+		return "changed"
+	default:
+		return "-"
+	}
+}
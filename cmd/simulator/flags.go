@@ -0,0 +1,216 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"auction-simulator/internal/arrival"
+	"auction-simulator/internal/catalog"
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/fault"
+)
+
+// runFlags are the flags that shape one simulation run, shared by the
+// main command and batch
+type runFlags struct {
+	mechanism     string
+	reserve       float64
+	reservePolicy string
+	population    string
+	attributes    string
+	catalog       string
+	sampling      string
+	tieBreak      string
+	auctionFormat string
+	softClose     time.Duration
+	extension     time.Duration
+	maxExtensions int
+	snipers       float64
+	learners      float64
+	learning      string
+	remote        stringList
+	groups        stringList
+	budget        float64
+	faults        string
+	minBid        float64
+	maxBid        float64
+	duplicates    string
+	maxMemoryMB   int
+	limiter       string
+	arrival       string
+	rate          float64
+	duration      time.Duration
+	trace         string
+	speed         float64
+	maxInFlight   int
+}
+
+// register defines the run flags on fs
+func (f *runFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.mechanism, "mechanism", config.FirstPrice, "pricing rule: first-price or second-price")
+	fs.Float64Var(&f.reserve, "reserve", 0, "flat reserve price for every auction")
+	fs.StringVar(&f.attributes, "attributes", "", "JSON attribute schema of the items auctioned")
+	fs.StringVar(&f.catalog, "catalog", "", "CSV or JSON catalog of the items to auction, with id, attributes, reserve and quantity")
+	fs.StringVar(&f.sampling, "sampling", config.SampleSequential, "how auctions pick catalog items: sequential, uniform or stock")
+	fs.StringVar(&f.reservePolicy, "reserve-policy", "", "reserve policy JSON written by optimize-reserve")
+	fs.StringVar(&f.auctionFormat, "auction-format", config.FormatSealed, "sealed (one round of sealed bids) or open (rounds that raise the standing bid)")
+	fs.DurationVar(&f.softClose, "soft-close", 0, "open auctions: bids this close to the deadline extend it (0 is a hard close)")
+	fs.DurationVar(&f.extension, "extension", 0, "how far a soft-close bid extends the deadline (0 keeps the default)")
+	fs.IntVar(&f.maxExtensions, "max-extensions", 0, "most soft-close extensions per auction (0 keeps the default)")
+	fs.Float64Var(&f.snipers, "snipers", 0, "share of bidders that bid at the last moment of open auctions")
+	fs.Float64Var(&f.learners, "learners", 0, "share of bidders that learn how far to shade their bids")
+	fs.StringVar(&f.learning, "learning", config.LearnEpsilonGreedy, "how learners pick a shade: epsilon-greedy, ucb or thompson")
+	fs.StringVar(&f.population, "population", "", "bidder population JSON of parameter distributions, as fit-population writes")
+	fs.Var(&f.groups, "group", "add bidders acting together as kind:size[:strategy], kind rotation, suppression or shill (repeatable)")
+	fs.Var(&f.remote, "remote-bidder", "add a bidder served over HTTP as id=url (repeatable)")
+	fs.StringVar(&f.tieBreak, "tie-break", config.TieEarliest, "tied top bids: earliest, random, lowest-id or split")
+	fs.Float64Var(&f.budget, "budget", 0, "what each bidder may spend over the run (0 is unlimited)")
+	fs.StringVar(&f.faults, "faults", "", `inject bidder faults: "chaos" or e.g. latency=0.05:1s,error=0.02,malformed=0.02,panic=0.01,outage=5s:500ms`)
+	fs.Float64Var(&f.minBid, "min-bid", 0, "reject bids below this amount")
+	fs.Float64Var(&f.maxBid, "max-bid", 0, "reject bids above this amount (0 is unlimited)")
+	fs.StringVar(&f.duplicates, "duplicates", config.DuplicateFirst, "repeated bids from one bidder: first, last, highest or reject")
+	fs.StringVar(&f.limiter, "limiter", config.LimiterFixed, "bidder concurrency limiter: fixed, aimd or gradient")
+	fs.StringVar(&f.arrival, "arrival", "", "stream auctions as they arrive: poisson, constant, bursty, trace or replay (default runs all at once)")
+	fs.Float64Var(&f.rate, "rate", 0, "streaming arrival rate in auctions per second (0 keeps the default)")
+	fs.DurationVar(&f.duration, "duration", 0, "streaming arrival window (0 keeps the default)")
+	fs.StringVar(&f.trace, "trace", "", "arrival times file for -arrival trace, or JSONL bid request log for -arrival replay")
+	fs.Float64Var(&f.speed, "speed", 0, "speed-up of -arrival trace and replay timing, e.g. 2 replays twice as fast (0 keeps the recorded timing)")
+	fs.IntVar(&f.maxInFlight, "max-inflight", 0, "streamed auctions running at once; later arrivals queue (0 is unlimited)")
+	fs.IntVar(&f.maxMemoryMB, "max-memory-mb", 0, "memory limit in MB (0 derives it from the container limit)")
+}
+
+// apply validates the run flags and sets them on cfg. It returns the
+// number of catalog items and of replayed requests, zero when unused
+func (f *runFlags) apply(cfg *config.Config) (catalogItems, requests int, err error) {
+	if f.mechanism != config.FirstPrice && f.mechanism != config.SecondPrice {
+		return 0, 0, fmt.Errorf("Unknown pricing mechanism: %s", f.mechanism)
+	}
+	cfg.Mechanism = f.mechanism
+	switch f.tieBreak {
+	case config.TieEarliest, config.TieRandom, config.TieLowestID, config.TieSplit:
+	default:
+		return 0, 0, fmt.Errorf("Unknown tie-break policy: %s", f.tieBreak)
+	}
+	cfg.TieBreak = f.tieBreak
+	if f.auctionFormat != config.FormatSealed && f.auctionFormat != config.FormatOpen {
+		return 0, 0, fmt.Errorf("Unknown auction format: %s", f.auctionFormat)
+	}
+	cfg.Format = f.auctionFormat
+	cfg.Open.Window = f.softClose
+	if f.extension > 0 {
+		cfg.Open.Extension = f.extension
+	}
+	if f.maxExtensions > 0 {
+		cfg.Open.MaxExtensions = f.maxExtensions
+	}
+	cfg.Bidders.SniperShare = f.snipers
+	switch f.learning {
+	case config.LearnEpsilonGreedy, config.LearnUCB, config.LearnThompson:
+	default:
+		return 0, 0, fmt.Errorf("Unknown learning strategy: %s", f.learning)
+	}
+	cfg.Bidders.LearnerShare = f.learners
+	for _, spec := range f.groups {
+		group, err := parseGroup(spec)
+		if err != nil {
+			return 0, 0, fmt.Errorf("Invalid -group: %w", err)
+		}
+		cfg.Bidders.Groups = append(cfg.Bidders.Groups, group)
+	}
+	if f.population != "" {
+		population, err := config.LoadPopulation(f.population)
+		if err != nil {
+			return 0, 0, err
+		}
+		cfg.Bidders.Population = population
+	}
+	for _, spec := range f.remote {
+		id, endpoint, ok := strings.Cut(spec, "=")
+		if !ok || id == "" || endpoint == "" {
+			return 0, 0, fmt.Errorf("Remote bidder %q: want id=url", spec)
+		}
+		cfg.Remote = append(cfg.Remote, config.RemoteBidder{ID: id, Endpoint: endpoint})
+	}
+	cfg.Bidders.Learning = f.learning
+	cfg.Bidders.Budget = f.budget
+	faults, err := fault.Parse(f.faults)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Invalid -faults: %w", err)
+	}
+	cfg.Faults = faults
+	switch f.duplicates {
+	case config.DuplicateFirst, config.DuplicateLast, config.DuplicateHighest, config.DuplicateReject:
+	default:
+		return 0, 0, fmt.Errorf("Unknown duplicate bid policy: %s", f.duplicates)
+	}
+	cfg.Validation.MinBid = f.minBid
+	cfg.Validation.MaxBid = f.maxBid
+	cfg.Validation.Duplicates = f.duplicates
+	cfg.Limiter.Algorithm = f.limiter
+	cfg.Arrival.Process = f.arrival
+	cfg.Arrival.TracePath = f.trace
+	cfg.Arrival.Speed = f.speed
+	cfg.Arrival.MaxInFlight = f.maxInFlight
+	if f.rate > 0 {
+		cfg.Arrival.Rate = f.rate
+	}
+	if f.duration > 0 {
+		cfg.Arrival.Duration = f.duration
+	}
+	if cfg.Arrival.Recorded() && f.duration == 0 {
+		cfg.Arrival.Duration = 0 // replay the whole trace
+	}
+	if f.maxMemoryMB > 0 {
+		cfg.ResourceLimits.MaxMemoryMB = f.maxMemoryMB
+	}
+	if f.attributes != "" {
+		schema, err := config.LoadAttributeSchema(f.attributes)
+		if err != nil {
+			return 0, 0, err
+		}
+		cfg.Attributes = schema
+		cfg.AttributesPerAuction = len(schema)
+	}
+	if f.catalog != "" {
+		switch f.sampling {
+		case config.SampleSequential, config.SampleUniform, config.SampleStock:
+		default:
+			return 0, 0, fmt.Errorf("Unknown catalog sampling: %s", f.sampling)
+		}
+		items, err := catalog.Load(f.catalog)
+		if err != nil {
+			return 0, 0, err
+		}
+		schema := catalog.Schema(items, cfg.Attributes)
+		if _, err := catalog.New(items, schema, f.sampling); err != nil {
+			return 0, 0, fmt.Errorf("Catalog %s does not match the attribute schema: %w", f.catalog, err)
+		}
+		cfg.Catalog = config.CatalogConfig{Path: f.catalog, Sampling: f.sampling}
+		cfg.Attributes = schema
+		cfg.AttributesPerAuction = len(schema)
+		catalogItems = len(items)
+	}
+	if f.arrival == config.ArrivalReplay {
+		if f.catalog != "" {
+			return 0, 0, fmt.Errorf("-catalog cannot be combined with -arrival replay, whose requests give the items")
+		}
+		replay, schema, err := arrival.LoadReplay(f.trace, cfg.Attributes, f.speed)
+		if err != nil {
+			return 0, 0, err
+		}
+		cfg.Attributes = schema
+		cfg.AttributesPerAuction = len(schema)
+		requests = replay.Len()
+	}
+	cfg.Reserve.Price = f.reserve
+	if f.reservePolicy != "" {
+		policy, err := loadReservePolicy(f.reservePolicy)
+		if err != nil {
+			return 0, 0, err
+		}
+		cfg.Reserve = policy
+	}
+	return catalogItems, requests, nil
+}
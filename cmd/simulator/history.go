@@ -0,0 +1,159 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"auction-simulator/internal/metrics"
+	"auction-simulator/internal/store"
+)
+
+// historyTopBidders is how many bidders "history show" lists
+const historyTopBidders = 10
+
+// runHistory dispatches the "history list" and "history show" commands
+func runHistory(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: simulator history <list|show> [flags]")
+	}
+
+	switch args[0] {
+	case "list":
+		return historyList(args[1:])
+	case "show":
+		return historyShow(args[1:])
+	default:
+		return fmt.Errorf("unknown history command: %s", args[0])
+	}
+}
+
+// historyList prints the most recent runs in the history database
+func historyList(args []string) error {
+	fs := flag.NewFlagSet("history list", flag.ExitOnError)
+	dbPath := fs.String("db", store.DefaultPath, "SQLite run history database")
+	limit := fs.Int("limit", 20, "maximum number of runs to list (0 for all)")
+	fs.Parse(args)
+
+	st, err := store.Open(*dbPath)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	runs, err := st.ListRuns(*limit)
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		fmt.Printf("No runs recorded in %s\n", *dbPath)
+		return nil
+	}
+
+	lineSeparator := strings.Repeat("-", 100)
+	fmt.Printf("%s\n", lineSeparator)
+	fmt.Printf("%-28s %-20s %-10s %-9s %-9s %-8s %-12s\n",
+		"Run ID", "Started", "Duration", "Auctions", "Success", "Bids", "Revenue")
+	fmt.Printf("%s\n", lineSeparator)
+
+	for _, run := range runs {
+		successRate := 0.0
+		if run.TotalAuctions > 0 {
+			successRate = float64(run.SuccessfulAuctions) / float64(run.TotalAuctions) * 100
+		}
+		fmt.Printf("%-28s %-20s %-10v %-9d %-9s %-8d $%-11.2f\n",
+			run.RunID,
+			run.StartTime.Local().Format("2006-01-02 15:04:05"),
+			run.TotalDuration.Round(time.Millisecond),
+			run.TotalAuctions,
+			fmt.Sprintf("%.1f%%", successRate),
+			run.TotalBids,
+			run.TotalRevenue)
+	}
+
+	fmt.Printf("%s\n", lineSeparator)
+	return nil
+}
+
+// historyShow prints the config, metrics and results of one stored run
+func historyShow(args []string) error {
+	fs := flag.NewFlagSet("history show", flag.ExitOnError)
+	dbPath := fs.String("db", store.DefaultPath, "SQLite run history database")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: simulator history show [-db path] <run-id>")
+	}
+
+	st, err := store.Open(*dbPath)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	report, err := st.LoadRun(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Run %s\n", report.Metrics.RunID)
+	fmt.Printf("\nConfiguration:\n")
+	fmt.Printf("   Auctions: %d\n", report.Config.TotalAuctions)
+	fmt.Printf("   Bidders: %d\n", report.Config.TotalBidders)
+	fmt.Printf("   Attributes: %s\n", describeAttributes(report.Config))
+	fmt.Printf("   Auction Timeout: %v\n", report.Config.AuctionTimeout)
+	fmt.Printf("   Max vCPUs: %d\n", report.Config.ResourceLimits.MaxVCPUs)
+	fmt.Printf("   Max Concurrent Bidders: %d\n", report.Config.ResourceLimits.MaxConcurrentBidders)
+
+	reporter := &metrics.Reporter{}
+	reporter.ReportSummary(report.Metrics)
+	reporter.ReportRisk(metrics.NewRiskReport(report))
+
+	printTopBidders(metrics.ComputeBidderStats(report.Results))
+	printAuctionDetails(report.Results)
+	return nil
+}
+
+// printTopBidders lists the bidders that won the most auctions
+func printTopBidders(stats []metrics.BidderStats) {
+	sort.SliceStable(stats, func(i, j int) bool {
+		if stats[i].Wins != stats[j].Wins {
+			return stats[i].Wins > stats[j].Wins
+		}
+		return stats[i].TotalSpend > stats[j].TotalSpend
+	})
+	if len(stats) > historyTopBidders {
+		stats = stats[:historyTopBidders]
+	}
+
+	fmt.Printf("\nTop Bidders:\n")
+	lineSeparator := strings.Repeat("-", 80)
+	fmt.Printf("%s\n", lineSeparator)
+	fmt.Printf("%-12s %-8s %-8s %-10s %-12s %-12s\n",
+		"Bidder", "Bids", "Wins", "Win Rate", "Spend", "Avg Bid")
+	fmt.Printf("%s\n", lineSeparator)
+
+	for _, s := range stats {
+		fmt.Printf("%-12s %-8d %-8d %-10s $%-11.2f $%-11.2f\n",
+			s.BidderID, s.Bids, s.Wins, fmt.Sprintf("%.1f%%", s.WinRate*100), s.TotalSpend, s.AverageBid)
+	}
+}
+
+// saveRunHistory records a completed run in the history database
+func saveRunHistory(dbPath string, report *metrics.RunReport) error {
+	st, err := store.Open(dbPath)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	if err := st.SaveRun(report); err != nil {
+		return err
+	}
+
+	log.Printf("Run %s saved to: %s", report.Metrics.RunID, dbPath)
+	return nil
+}
@@ -2,24 +2,104 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"go/version"
+	"io"
 	"log"
 	"os"
+	"os/signal"
 	"runtime"
+	"runtime/debug"
+	"slices"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
-	"auction-simulator/internal/auction"
-	"auction-simulator/internal/bidder"
+	"auction-simulator/internal/checkpoint"
 	"auction-simulator/internal/config"
+	"auction-simulator/internal/fault"
 	"auction-simulator/internal/metrics"
+	"auction-simulator/internal/simulation"
+	"auction-simulator/internal/store"
 	"auction-simulator/internal/types"
-	"auction-simulator/pkg/utils"
 )
 
+// options holds command-line settings that are not part of the simulation config
+type options struct {
+	format string
+	dbPath string
+	seed   int64
+	runFlags
+
+	workers int
+
+	checkpoint      string
+	checkpointEvery time.Duration
+	resume          *checkpoint.Checkpoint
+}
+
 func main() {
-	// Initialize random seed
-	utils.InitRandom()
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "history":
+			if err := runHistory(os.Args[2:]); err != nil {
+				exitOnError("History command", err)
+			}
+			return
+		case "compare":
+			regressed, err := runCompare(os.Args[2:])
+			if err != nil {
+				exitOnError("Compare command", err)
+			}
+			if regressed {
+				os.Exit(exitRegression)
+			}
+			return
+		case "batch":
+			if err := runBatch(os.Args[2:]); err != nil {
+				exitOnError("Batch command", err)
+			}
+			return
+		case "sweep":
+			if err := runSweep(os.Args[2:]); err != nil {
+				exitOnError("Sweep command", err)
+			}
+			return
+		case "worker":
+			if err := runWorker(os.Args[2:]); err != nil {
+				exitOnError("Worker", err)
+			}
+			return
+		case "resume":
+			if err := runResume(os.Args[2:]); err != nil {
+				exitOnError("Resume", err)
+			}
+			return
+		case "optimize-reserve":
+			if err := runOptimizeReserve(os.Args[2:]); err != nil {
+				exitOnError("Reserve optimization", err)
+			}
+			return
+		case "fit-population":
+			if err := runFitPopulation(os.Args[2:]); err != nil {
+				exitOnError("Population fit", err)
+			}
+			return
+		}
+	}
+
+	opts := options{}
+	flag.StringVar(&opts.format, "format", "json", "output format for auction results: json, csv or parquet")
+	flag.StringVar(&opts.dbPath, "db", store.DefaultPath, "SQLite run history database (empty writes a metrics JSON file instead)")
+	flag.Int64Var(&opts.seed, "seed", 0, "random seed for a reproducible run (0 picks one from the clock)")
+	opts.register(flag.CommandLine)
+	flag.IntVar(&opts.workers, "workers", 0, "split the auctions between this many local worker processes (0 runs in this process)")
+	flag.StringVar(&opts.checkpoint, "checkpoint", "", "save progress to this file so the resume command can finish the run (empty disables)")
+	flag.DurationVar(&opts.checkpointEvery, "checkpoint-every", checkpoint.DefaultInterval, "time between checkpoints")
+	flag.Parse()
 
 	// Display environment information
 	fmt.Printf("Auction Simulator - Go %s\n", runtime.Version())
@@ -28,26 +108,83 @@ func main() {
 
 	// Load configuration with resource standardization
 	cfg := config.DefaultConfig()
+	if opts.seed != 0 {
+		cfg.Seed = opts.seed
+	}
+	catalogItems, requests, err := opts.apply(cfg)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
 
 	fmt.Printf("\nSimulation Configuration:\n")
-	fmt.Printf("   Auctions: %d (concurrent)\n", cfg.TotalAuctions)
+	if cfg.Arrival.Streaming() {
+		window := "the whole trace"
+		if cfg.Arrival.Duration > 0 {
+			window = cfg.Arrival.Duration.String()
+		}
+		fmt.Printf("   Auctions: streamed (%s arrivals for %s)\n", cfg.Arrival.Process, window)
+	} else {
+		fmt.Printf("   Auctions: %d (concurrent)\n", cfg.TotalAuctions)
+	}
 	fmt.Printf("   Bidders: %d\n", cfg.TotalBidders)
-	fmt.Printf("   Attributes: %d per auction\n", cfg.AttributesPerAuction)
+	for _, remote := range cfg.Remote {
+		fmt.Printf("   Remote Bidder: %s at %s\n", remote.ID, remote.Endpoint)
+	}
+	fmt.Printf("   Attributes: %s\n", describeAttributes(cfg))
+	if cfg.Catalog.Path != "" {
+		fmt.Printf("   Catalog: %s (%d items, %s sampling)\n", cfg.Catalog.Path, catalogItems, cfg.Catalog.Sampling)
+	}
+	if requests > 0 {
+		speed := "recorded timing"
+		if cfg.Arrival.Speed > 0 {
+			speed = fmt.Sprintf("%gx speed", cfg.Arrival.Speed)
+		}
+		fmt.Printf("   Replay: %s (%d bid requests, %s)\n", cfg.Arrival.TracePath, requests, speed)
+	}
 	fmt.Printf("   Auction Timeout: %v\n", cfg.AuctionTimeout)
+	fmt.Printf("   Pricing: %s (ties: %s)\n", cfg.Mechanism, cfg.TieBreak)
+	if cfg.Format == config.FormatOpen {
+		closing := "hard close"
+		if cfg.Open.SoftClose() {
+			closing = fmt.Sprintf("soft close: %v window, +%v up to %d times",
+				cfg.Open.Window, cfg.Open.Extension, cfg.Open.MaxExtensions)
+		}
+		fmt.Printf("   Format: open, %s\n", closing)
+	}
+	if cfg.Bidders.LearnerShare > 0 {
+		fmt.Printf("   Learners: %.0f%% of bidders (%s)\n", cfg.Bidders.LearnerShare*100, cfg.Bidders.Learning)
+	}
+	if cfg.Bidders.SniperShare > 0 {
+		fmt.Printf("   Snipers: %.0f%% of bidders\n", cfg.Bidders.SniperShare*100)
+	}
+	if cfg.Bidders.Population != nil {
+		fmt.Printf("   Population: %s\n", describePopulation(cfg.Bidders.Population))
+	}
+	for _, group := range cfg.Bidders.Groups {
+		fmt.Printf("   Group: %d %s bidders (%s)\n", group.Size, group.Kind, group.Strategy)
+	}
+	if cfg.Faults.Enabled() {
+		fmt.Printf("   Faults: %s\n", fault.Describe(cfg.Faults))
+	}
+	if opts.workers > 0 {
+		fmt.Printf("   Workers: %d local processes\n", opts.workers)
+	}
 
 	fmt.Printf("\nResource Standardization:\n")
 	fmt.Printf("   Max vCPUs: %d\n", cfg.ResourceLimits.MaxVCPUs)
 	fmt.Printf("   Max Memory: %d MB\n", cfg.ResourceLimits.MaxMemoryMB)
-	fmt.Printf("   Max Concurrent Bidders: %d\n", cfg.ResourceLimits.MaxConcurrentBidders)
+	fmt.Printf("   Max Concurrent Bidders: %d (%s limiter)\n", cfg.ResourceLimits.MaxConcurrentBidders, cfg.Limiter.Algorithm)
+	fmt.Printf("   Container: %s\n", cfg.ResourceLimits.Container)
 
 	// Validate environment
 	if err := validateEnvironment(cfg); err != nil {
 		log.Fatalf("Environment validation failed: %v", err)
 	}
 
-	// Set CPU limit
-	runtime.GOMAXPROCS(cfg.ResourceLimits.MaxVCPUs)
-	fmt.Printf("\nResource limits applied: GOMAXPROCS=%d\n", runtime.GOMAXPROCS(0))
+	// Apply CPU and memory limits
+	applyResourceLimits(cfg)
+	fmt.Printf("\nResource limits applied: GOMAXPROCS=%d, memory limit=%d MB\n",
+		runtime.GOMAXPROCS(0), debug.SetMemoryLimit(-1)>>20)
 
 	// Use constant strings for the separators
 	separator := strings.Repeat("=", 60)
@@ -55,18 +192,68 @@ func main() {
 	fmt.Println("Starting auction simulation...")
 	fmt.Printf("%s\n", separator)
 
-	// Run the simulation
-	if err := runSimulation(cfg); err != nil {
-		log.Fatalf("Simulation failed: %v", err)
+	// Run the simulation; SIGINT or SIGTERM stops it and keeps partial output
+	ctx, stop := signalContext()
+	defer stop()
+	if err := runSimulation(ctx, cfg, opts); err != nil {
+		stop()
+		exitOnError("Simulation", err)
 	}
 
 	log.Println("Simulation completed successfully")
 	os.Exit(0)
 }
 
+// exitInterrupted is the process exit code after SIGINT or SIGTERM, following
+// the shell convention of 128 + SIGINT
+const exitInterrupted = 130
+
+// signalContext returns a context that is cancelled on SIGINT or SIGTERM.
+// After the first signal the default handling is restored, so a second one
+// terminates the process immediately.
+func signalContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		defer signal.Stop(signals)
+		select {
+		case sig := <-signals:
+			fmt.Fprintf(os.Stderr, "\nReceived %v, stopping and saving partial results (repeat to quit immediately)...\n", sig)
+			cancel(fmt.Errorf("received %v", sig))
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() { cancel(nil) }
+}
+
+// runLog returns where simulations log their progress: standard error, or
+// nowhere unless verbose
+func runLog(verbose bool) io.Writer {
+	if verbose {
+		return os.Stderr
+	}
+	return io.Discard
+}
+
+// exitOnError terminates the process after a command failed. Interrupted
+// commands have already saved their partial output and exit with
+// exitInterrupted.
+func exitOnError(command string, err error) {
+	if errors.Is(err, context.Canceled) {
+		log.Printf("%s interrupted: %v", command, err)
+		os.Exit(exitInterrupted)
+	}
+	log.Fatalf("%s failed: %v", command, err)
+}
+
 func validateEnvironment(cfg *config.Config) error {
-	if runtime.Version() < "go1.25" {
-		return fmt.Errorf("requires Go 1.25 or later, current: %s", runtime.Version())
+	// go.mod requires 1.26 for the SQLite driver; development builds have
+	// no comparable version and are let through
+	if v := runtime.Version(); version.IsValid(v) && version.Compare(v, "go1.26") < 0 {
+		return fmt.Errorf("requires Go 1.26 or later, current: %s", v)
 	}
 
 	if cfg.ResourceLimits.MaxVCPUs < 1 {
@@ -80,106 +267,163 @@ func validateEnvironment(cfg *config.Config) error {
 	return nil
 }
 
-func runSimulation(cfg *config.Config) error {
-	// Record overall simulation start time
-	simulationStart := time.Now()
+// describeAttributes summarizes the attributes of each auction
+func describeAttributes(cfg *config.Config) string {
+	if len(cfg.Attributes) == 0 {
+		return fmt.Sprintf("%d per auction", cfg.AttributesPerAuction)
+	}
+	names := make([]string, len(cfg.Attributes))
+	for i, spec := range cfg.Attributes {
+		names[i] = fmt.Sprintf("%s (%s)", spec.Name, spec.Type)
+	}
+	return strings.Join(names, ", ")
+}
 
-	// Initialize components
-	auctionManager := auction.NewManager(cfg)
-	bidderManager := bidder.NewManager(cfg)
-	metricsCollector := metrics.NewCollector()
-	reporter := metrics.NewReporter("output")
+// parseGroup parses a group given as kind:size[:strategy]. Rotation
+// cartels and suppression rings cover by default, shills are aggressive.
+func parseGroup(spec string) (config.GroupConfig, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return config.GroupConfig{}, fmt.Errorf("group %q: want kind:size[:strategy]", spec)
+	}
+	size, err := strconv.Atoi(parts[1])
+	if err != nil || size < 1 {
+		return config.GroupConfig{}, fmt.Errorf("group %q: invalid size %q", spec, parts[1])
+	}
+	group := config.GroupConfig{Kind: parts[0], Size: size}
+
+	var strategies []string
+	switch group.Kind {
+	case config.GroupRotation, config.GroupSuppression:
+		strategies = []string{config.StrategyCover, config.StrategyAbstain}
+	case config.GroupShill:
+		strategies = []string{config.StrategyAggressive, config.StrategyCautious}
+	default:
+		return config.GroupConfig{}, fmt.Errorf("group %q: unknown kind %q", spec, group.Kind)
+	}
+	group.Strategy = strategies[0]
+	if len(parts) == 3 {
+		group.Strategy = parts[2]
+	}
+	if !slices.Contains(strategies, group.Strategy) {
+		return config.GroupConfig{}, fmt.Errorf("group %q: %s groups use %s", spec, group.Kind, strings.Join(strategies, " or "))
+	}
+	return group, nil
+}
 
-	// Start metrics collection
-	metricsCollector.Start()
+// applyResourceLimits caps the scheduler at the configured vCPUs and sets the
+// runtime soft memory limit, so the garbage collector works to stay within
+// MaxMemoryMB
+func applyResourceLimits(cfg *config.Config) {
+	runtime.GOMAXPROCS(cfg.ResourceLimits.MaxVCPUs)
+	debug.SetMemoryLimit(int64(cfg.ResourceLimits.MaxMemoryMB) << 20)
+}
 
-	fmt.Println("Initializing simulation components...")
+func runSimulation(ctx context.Context, cfg *config.Config, opts options) error {
+	reporter := metrics.NewReporter("output")
 
-	// Initialize auctions and bidders
-	if err := auctionManager.InitializeAuctions(); err != nil {
-		return fmt.Errorf("failed to initialize auctions: %w", err)
+	if opts.format != "json" {
+		exporter, err := metrics.NewExporter(opts.format)
+		if err != nil {
+			return err
+		}
+		reporter.SetExporter(exporter)
 	}
 
-	if err := bidderManager.InitializeBidders(); err != nil {
-		return fmt.Errorf("failed to initialize bidders: %w", err)
+	if cfg.Arrival.Streaming() {
+		fmt.Printf("\nStreaming auctions with %d bidders each...\n", cfg.TotalBidders)
+	} else {
+		fmt.Printf("\nStarting %d concurrent auctions with %d bidders each...\n",
+			cfg.TotalAuctions, cfg.TotalBidders)
 	}
-
-	fmt.Println("Initialization complete:")
-	fmt.Printf("   Auctions: %d\n", len(auctionManager.GetAuctions()))
-	fmt.Printf("   Bidders: %d\n", len(bidderManager.GetBidders()))
-
-	// Create orchestrator for concurrent auction execution
-	orchestrator := auction.NewOrchestrator(cfg, auctionManager, bidderManager)
-
-	fmt.Printf("\nStarting %d concurrent auctions with %d bidders each...\n",
-		cfg.TotalAuctions, cfg.TotalBidders)
 	fmt.Printf("Auction timeout: %v\n", cfg.AuctionTimeout)
 	fmt.Printf("Resource limit: %d concurrent bidders\n", cfg.ResourceLimits.MaxConcurrentBidders)
+	fmt.Printf("Seed: %d\n", cfg.Seed)
 
-	// Run all auctions concurrently
-	auctionResults, err := runConcurrentAuctions(orchestrator, cfg)
-	if err != nil {
+	// Run all auctions concurrently. An interrupt or memory limit breach
+	// still yields a partial report, which is saved, marked incomplete, before
+	// the error is returned.
+	runReport, err := runConcurrentAuctions(ctx, cfg, opts)
+	if runReport == nil {
 		return fmt.Errorf("auction execution failed: %w", err)
 	}
-
-	// Calculate total simulation duration
-	totalDuration := time.Since(simulationStart)
-
-	// Stop metrics and report results
-	simulationMetrics := metricsCollector.Stop()
-	simulationMetrics.TotalDuration = totalDuration
-	simulationMetrics.TotalAuctions = cfg.TotalAuctions
-	simulationMetrics.TotalBidders = cfg.TotalBidders
-
-	// Calculate auction statistics
-	successfulAuctions := 0
-	totalBidsReceived := 0
-	for _, result := range auctionResults {
-		if result.Error == nil {
-			successfulAuctions++
-		}
-		totalBidsReceived += result.TotalBids
-	}
-
-	simulationMetrics.SuccessfulAuctions = successfulAuctions
-	simulationMetrics.FailedAuctions = len(auctionResults) - successfulAuctions
-	simulationMetrics.TotalBidsReceived = totalBidsReceived
-	if len(auctionResults) > 0 {
-		simulationMetrics.AverageBidsPerAuction = float64(totalBidsReceived) / float64(len(auctionResults))
-	}
+	simulationMetrics := runReport.Metrics
 
 	// Report results using constant strings
 	separator := strings.Repeat("=", 60)
 	fmt.Printf("\n%s\n", separator)
 	reporter.ReportSummary(simulationMetrics)
+	risk := metrics.NewRiskReport(runReport)
+	reporter.ReportRisk(risk)
 
-	// Save results to files
-	if err := reporter.SaveMetrics(simulationMetrics); err != nil {
+	// Persist the run to history, or fall back to a metrics file
+	if opts.dbPath != "" {
+		if err := saveRunHistory(opts.dbPath, runReport); err != nil {
+			log.Printf("Warning: Could not save run history: %v", err)
+		}
+	} else if err := reporter.SaveMetrics(simulationMetrics); err != nil {
 		log.Printf("Warning: Could not save metrics: %v", err)
 	}
 
-	if err := reporter.SaveAuctionResults(auctionResults); err != nil {
+	// Save results to files
+	if err := reporter.SaveAuctionResults(runReport.Results); err != nil {
 		log.Printf("Warning: Could not save auction results: %v", err)
 	}
 
+	if err := reporter.SaveRiskReport(risk); err != nil {
+		log.Printf("Warning: Could not save risk report: %v", err)
+	}
+
+	if err := reporter.SaveHTMLReport(runReport); err != nil {
+		log.Printf("Warning: Could not save HTML report: %v", err)
+	}
+
 	// Print detailed auction results
-	printAuctionDetails(auctionResults)
+	printAuctionDetails(runReport.Results)
 
-	fmt.Printf("\nSimulation completed in %v\n", totalDuration)
+	var breach *metrics.MemoryBreach
+	if errors.As(err, &breach) {
+		printMemoryBreach(breach)
+	}
+	if err != nil {
+		return fmt.Errorf("auction execution stopped early, partial results saved: %w", err)
+	}
+
+	fmt.Printf("\nSimulation completed in %v\n", simulationMetrics.TotalDuration)
 	return nil
 }
 
-func runConcurrentAuctions(orchestrator *auction.Orchestrator, cfg *config.Config) ([]*types.AuctionResult, error) {
-	// Create context for the entire simulation
-	ctx := context.Background()
-
-	// Run all auctions concurrently
-	results, err := orchestrator.RunAllAuctions(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("error running auctions: %w", err)
+func runConcurrentAuctions(ctx context.Context, cfg *config.Config, opts options) (*metrics.RunReport, error) {
+	ckptOpts := simulation.CheckpointOptions{Path: opts.checkpoint, Interval: opts.checkpointEvery}
+	switch {
+	case opts.workers > 0:
+		if opts.checkpoint != "" {
+			return nil, fmt.Errorf("checkpoints are not supported for distributed runs")
+		}
+		coordinator, err := startWorkers(opts.workers, cfg, os.Stderr)
+		if err != nil {
+			return nil, err
+		}
+		defer coordinator.Close()
+		return coordinator.Run(ctx, cfg)
+	case opts.resume != nil:
+		return simulation.Resume(ctx, opts.resume, ckptOpts)
+	case opts.checkpoint != "":
+		return simulation.RunWithCheckpoints(ctx, cfg, ckptOpts)
+	default:
+		return simulation.Run(ctx, cfg)
 	}
+}
 
-	return results, nil
+// printMemoryBreach prints the diagnostics captured when the memory limit was exceeded
+func printMemoryBreach(breach *metrics.MemoryBreach) {
+	fmt.Printf("\nMemory limit breached at %s:\n", breach.At.Format("15:04:05.000"))
+	fmt.Printf("   Limit:      %d MB\n", breach.LimitMB)
+	fmt.Printf("   In use:     %.1f MB\n", breach.UsedMB)
+	fmt.Printf("   Heap:       %.1f MB\n", breach.HeapMB)
+	fmt.Printf("   Stacks:     %.1f MB\n", breach.StackMB)
+	fmt.Printf("   Goroutines: %d\n", breach.Goroutines)
+	fmt.Printf("   GC cycles:  %d\n", breach.NumGC)
 }
 
 func printAuctionDetails(results []*types.AuctionResult) {
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/population"
+	"auction-simulator/internal/simulation"
+	"auction-simulator/internal/store"
+)
+
+// runFitPopulation fits bidder parameter distributions to a recorded bid
+// log and writes them as a population the simulator can draw bidders from
+func runFitPopulation(args []string) error {
+	fs := flag.NewFlagSet("fit-population", flag.ExitOnError)
+	bidsPath := fs.String("bids", "", "CSV bid log with auction_id, bidder_id, amount and latency_ms columns")
+	runID := fs.String("run", "", "fit to the bids of a stored run instead")
+	dbPath := fs.String("db", store.DefaultPath, "SQLite run history database for -run")
+	trainRuns := fs.Int("train-runs", 3, "fresh runs to fit to when neither -bids nor -run is given")
+	dist := fs.String("dist", population.FitAuto, "distribution fitted to every parameter: auto, uniform, normal, lognormal, beta, empirical or mixture")
+	seed := fs.Int64("seed", 0, "base seed for fresh runs (0 picks one from the clock)")
+	out := fs.String("out", "", "write the population JSON to this path (default output/population_<timestamp>.json)")
+	verbose := fs.Bool("verbose", false, "keep per-auction log output")
+	fs.Parse(args)
+
+	// Bidders are profiled per log: fresh runs reuse bidder IDs for
+	// different bidders
+	var profiles []population.Profile
+	switch {
+	case *bidsPath != "":
+		bids, err := population.LoadBidLog(*bidsPath)
+		if err != nil {
+			return err
+		}
+		profiles = population.Profiles(bids)
+		fmt.Printf("Fitting to bid log %s (%d bids)\n", *bidsPath, len(bids))
+	case *runID != "":
+		st, err := store.Open(*dbPath)
+		if err != nil {
+			return err
+		}
+		report, err := st.LoadRun(*runID)
+		st.Close()
+		if err != nil {
+			return err
+		}
+		bids := population.Bids(report.Results)
+		profiles = population.Profiles(bids)
+		fmt.Printf("Fitting to run %s (%d bids)\n", *runID, len(bids))
+	default:
+		cfg := config.DefaultConfig()
+		if *seed != 0 {
+			cfg.Seed = *seed
+		}
+		if err := validateEnvironment(cfg); err != nil {
+			return fmt.Errorf("environment validation failed: %w", err)
+		}
+		applyResourceLimits(cfg)
+
+		ctx, stop := signalContext()
+		defer stop()
+
+		fmt.Printf("Fitting to %d fresh runs (seed %d)...\n", *trainRuns, cfg.Seed)
+		batch, err := simulation.RunBatch(ctx, cfg, simulation.BatchOptions{
+			Runs:       *trainRuns,
+			Confidence: 0.95,
+			Log:        runLog(*verbose),
+		})
+		if err != nil {
+			return err
+		}
+		for _, run := range batch.Runs {
+			profiles = append(profiles, population.Profiles(population.Bids(run.Results))...)
+		}
+	}
+
+	result, err := population.Fit(profiles, *dist)
+	if err != nil {
+		return err
+	}
+	printPopulationFit(result)
+	return savePopulation(*out, &result.Population)
+}
+
+// printPopulationFit prints the distribution fitted to every parameter and
+// the correlations between them
+func printPopulationFit(result *population.Result) {
+	separator := strings.Repeat("=", 72)
+	lineSeparator := strings.Repeat("-", 72)
+
+	fmt.Printf("\n%s\n", separator)
+	fmt.Printf("BIDDER POPULATION (%d bidders)\n", result.Bidders)
+	fmt.Printf("%s\n", separator)
+	fmt.Printf("%-12s %-9s %-11s %-16s %-12s\n", "Parameter", "Bidders", "Fit", "Log-likelihood", "BIC")
+	fmt.Printf("%s\n", lineSeparator)
+	for _, fit := range result.Fits {
+		fmt.Printf("%-12s %-9d %-11s %-16.2f %-12.2f\n", fit.Param, fit.Bidders, fit.Kind, fit.LogLikelihood, fit.BIC)
+	}
+	fmt.Printf("%s\n", lineSeparator)
+	for _, c := range result.Population.Correlations {
+		fmt.Printf("Correlation %s / %s: %+.3f\n", c.A, c.B, c.Rho)
+	}
+}
+
+// savePopulation writes a population that can be passed back to the
+// simulator with -population
+func savePopulation(path string, p *config.PopulationConfig) error {
+	if path == "" {
+		if err := os.MkdirAll("output", 0755); err != nil {
+			return fmt.Errorf("could not create output directory: %w", err)
+		}
+		path = fmt.Sprintf("output/population_%s.json", time.Now().Format("20060102_150405"))
+	}
+
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode population: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("could not write population: %w", err)
+	}
+
+	fmt.Printf("\nPopulation saved to: %s\n", path)
+	return nil
+}
+
+// describePopulation summarizes the distributions of a population
+func describePopulation(p *config.PopulationConfig) string {
+	var parts []string
+	for _, param := range config.PopulationParams {
+		if spec, ok := p.Params[param]; ok {
+			parts = append(parts, fmt.Sprintf("%s %s", param, spec.Kind))
+		}
+	}
+	description := strings.Join(parts, ", ")
+	if len(p.Correlations) > 2 {
+		return fmt.Sprintf("%s; %d correlations", description, len(p.Correlations))
+	}
+	for _, c := range p.Correlations {
+		description += fmt.Sprintf("; %s/%s %+.2f", c.A, c.B, c.Rho)
+	}
+	return description
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/reserve"
+	"auction-simulator/internal/simulation"
+	"auction-simulator/internal/store"
+	"auction-simulator/internal/types"
+	"auction-simulator/pkg/utils"
+)
+
+// runOptimizeReserve fits revenue-maximizing reserve prices to recorded bid
+// books and validates them by re-simulating on held-out seeds
+func runOptimizeReserve(args []string) error {
+	defaults := reserve.DefaultOptions()
+
+	fs := flag.NewFlagSet("optimize-reserve", flag.ExitOnError)
+	runID := fs.String("run", "", "optimize over a stored run instead of fresh training runs")
+	dbPath := fs.String("db", store.DefaultPath, "SQLite run history database for -run")
+	trainRuns := fs.Int("train-runs", 3, "fresh runs to train on when -run is not given")
+	mechanism := fs.String("mechanism", defaults.Mechanism, "pricing of fresh training and validation runs: first-price or second-price (-run uses the stored run's)")
+	method := fs.String("method", defaults.Method, "search method: grid, golden or myerson")
+	scope := fs.String("scope", defaults.Scope, "reserve granularity: global, segment or auction")
+	segments := fs.Int("segments", defaults.Segments, "quantile buckets in segment scope")
+	segmentAttr := fs.Int("segment-attribute", defaults.SegmentAttribute, "attribute ID that defines segments")
+	gridSteps := fs.Int("grid-steps", defaults.GridSteps, "candidates evaluated by grid search")
+	validate := fs.Int("validate", 3, "held-out runs re-simulated with and without the reserves (0 skips)")
+	seed := fs.Int64("seed", 0, "base seed for training and validation runs (0 picks one from the clock)")
+	out := fs.String("out", "", "write the reserve policy JSON to this path (default output/reserve_<timestamp>.json)")
+	verbose := fs.Bool("verbose", false, "keep per-auction log output")
+	fs.Parse(args)
+
+	opts := reserve.Options{
+		Method:           *method,
+		Scope:            *scope,
+		Segments:         *segments,
+		SegmentAttribute: *segmentAttr,
+		GridSteps:        *gridSteps,
+	}
+
+	if *mechanism != config.FirstPrice && *mechanism != config.SecondPrice {
+		return fmt.Errorf("unknown pricing mechanism: %s", *mechanism)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Mechanism = *mechanism
+	if *seed != 0 {
+		cfg.Seed = *seed
+	}
+	if err := validateEnvironment(cfg); err != nil {
+		return fmt.Errorf("environment validation failed: %w", err)
+	}
+	applyResourceLimits(cfg)
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	var results []*types.AuctionResult
+	if *runID != "" {
+		st, err := store.Open(*dbPath)
+		if err != nil {
+			return err
+		}
+		report, err := st.LoadRun(*runID)
+		st.Close()
+		if err != nil {
+			return err
+		}
+		if report.Config != nil {
+			cfg = report.Config
+		}
+		results = report.Results
+		fmt.Printf("Optimizing over run %s (%d auctions)\n", *runID, len(results))
+	} else {
+		train := *cfg
+		train.Reserve = config.ReservePolicy{}
+		fmt.Printf("Training on %d %s runs (seed %d)...\n", *trainRuns, cfg.Mechanism, cfg.Seed)
+		batch, err := simulation.RunBatch(ctx, &train, simulation.BatchOptions{
+			Runs:       *trainRuns,
+			Confidence: 0.95,
+			Log:        runLog(*verbose),
+		})
+		if err != nil {
+			return err
+		}
+		for _, run := range batch.Runs {
+			results = append(results, run.Results...)
+		}
+	}
+
+	// Revenue is priced as the recorded auctions were
+	opts.Mechanism = cfg.Mechanism
+	result, err := reserve.Optimize(results, opts)
+	if err != nil {
+		return err
+	}
+	printReserveResult(result)
+
+	if *validate > 0 {
+		if err := validateReserves(ctx, cfg, result.Policy, *validate, runLog(*verbose)); err != nil {
+			return err
+		}
+	}
+
+	return saveReservePolicy(*out, result)
+}
+
+// printReserveResult prints the recommended reserve of every group
+func printReserveResult(result *reserve.Result) {
+	separator := strings.Repeat("=", 96)
+	lineSeparator := strings.Repeat("-", 96)
+
+	fmt.Printf("\n%s\n", separator)
+	fmt.Printf("RESERVE PRICES (%s search, %s scope, %s)\n", result.Method, result.Scope, result.Mechanism)
+	fmt.Printf("%s\n", separator)
+	fmt.Printf("%-28s %-9s %-7s %-10s %-14s %-14s\n",
+		"Group", "Auctions", "Bids", "Reserve", "Baseline", "Expected")
+	fmt.Printf("%s\n", lineSeparator)
+
+	limit := len(result.Groups)
+	if limit > 20 {
+		limit = 20
+	}
+	for _, g := range result.Groups[:limit] {
+		fmt.Printf("%-28s %-9d %-7d $%-9.2f $%-13.2f $%-13.2f\n",
+			g.Group, g.Auctions, g.Bids, g.Reserve, g.Baseline, g.Expected)
+		if g.Distribution != "" {
+			fmt.Printf("   fitted %s\n", g.Distribution)
+		}
+	}
+	if len(result.Groups) > limit {
+		fmt.Printf("   ... %d more groups\n", len(result.Groups)-limit)
+	}
+
+	fmt.Printf("%s\n", lineSeparator)
+	fmt.Printf("In-sample revenue: $%.2f without reserves, $%.2f with (%+.1f%%)\n",
+		result.Baseline, result.Expected, percentChange(result.Baseline, result.Expected))
+}
+
+// validateReserves re-simulates held-out seeds under the base mechanism
+// with and without the policy. Both arms share seeds, so the difference comes
+// from the reserves alone.
+func validateReserves(ctx context.Context, base *config.Config, policy config.ReservePolicy, runs int, logOutput io.Writer) error {
+	holdout := *base
+	holdout.Seed = utils.DeriveSeed(base.Seed, "holdout")
+	holdout.Reserve = config.ReservePolicy{}
+
+	withReserve := holdout
+	withReserve.Reserve = policy
+
+	fmt.Printf("\nValidating on %d held-out runs (seed %d)...\n", runs, holdout.Seed)
+
+	revenue := make([]float64, 2)
+	sold := make([]float64, 2)
+	for i, cfg := range []*config.Config{&holdout, &withReserve} {
+		batch, err := simulation.RunBatch(ctx, cfg, simulation.BatchOptions{
+			Runs:       runs,
+			Confidence: 0.95,
+			Log:        logOutput,
+		})
+		if err != nil {
+			return fmt.Errorf("validation run failed: %w", err)
+		}
+		for _, run := range batch.Runs {
+			revenue[i] += run.Metrics.TotalRevenue
+			for _, result := range run.Results {
+				if result.Winner != nil {
+					sold[i]++
+				}
+			}
+		}
+	}
+
+	fmt.Printf("   Revenue without reserves: $%.2f (%.0f auctions sold)\n", revenue[0], sold[0])
+	fmt.Printf("   Revenue with reserves:    $%.2f (%.0f auctions sold)\n", revenue[1], sold[1])
+	fmt.Printf("   Out-of-sample lift:       %+.1f%%\n", percentChange(revenue[0], revenue[1]))
+	return nil
+}
+
+// saveReservePolicy writes the optimization result, including the policy
+// that can be passed back to the simulator with -reserve-policy
+func saveReservePolicy(path string, result *reserve.Result) error {
+	if path == "" {
+		if err := os.MkdirAll("output", 0755); err != nil {
+			return fmt.Errorf("could not create output directory: %w", err)
+		}
+		path = fmt.Sprintf("output/reserve_%s.json", time.Now().Format("20060102_150405"))
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode reserve policy: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("could not write reserve policy: %w", err)
+	}
+
+	fmt.Printf("\nReserve policy saved to: %s\n", path)
+	return nil
+}
+
+// loadReservePolicy reads the policy of a saved optimization result
+func loadReservePolicy(path string) (config.ReservePolicy, error) {
+	var result reserve.Result
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return result.Policy, fmt.Errorf("could not read reserve policy: %w", err)
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result.Policy, fmt.Errorf("could not parse reserve policy: %w", err)
+	}
+	return result.Policy, nil
+}
+
+// percentChange returns the relative change from before to after in percent
+func percentChange(before, after float64) float64 {
+	if before == 0 {
+		return 0
+	}
+	return (after - before) / before * 100
+}
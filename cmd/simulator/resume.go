@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"auction-simulator/internal/checkpoint"
+	"auction-simulator/internal/store"
+)
+
+// runResume finishes a checkpointed run and writes the same output the
+// uninterrupted run would have, unless it has learning bidders; see
+// simulation.Resume
+func runResume(args []string) error {
+	fs := flag.NewFlagSet("resume", flag.ExitOnError)
+	path := fs.String("checkpoint", checkpoint.DefaultPath, "checkpoint file written by a run with -checkpoint")
+	every := fs.Duration("checkpoint-every", checkpoint.DefaultInterval, "time between checkpoints")
+	format := fs.String("format", "json", "output format for auction results: json, csv or parquet")
+	dbPath := fs.String("db", store.DefaultPath, "SQLite run history database (empty writes a metrics JSON file instead)")
+	maxMemoryMB := fs.Int("max-memory-mb", 0, "memory limit in MB (0 keeps the checkpointed run's limit)")
+	fs.Parse(args)
+
+	cp, err := checkpoint.Load(*path)
+	if err != nil {
+		return err
+	}
+	cfg := cp.Config
+	if *maxMemoryMB > 0 {
+		cfg.ResourceLimits.MaxMemoryMB = *maxMemoryMB
+	}
+
+	fmt.Printf("Resuming run %s from %s\n", cp.RunID, *path)
+	fmt.Printf("   Saved: %s\n", cp.SavedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("   Completed: %d/%d auctions\n", len(cp.Results), cfg.TotalAuctions)
+	fmt.Printf("   Elapsed: %v\n", cp.Elapsed)
+
+	if err := validateEnvironment(cfg); err != nil {
+		return fmt.Errorf("environment validation failed: %w", err)
+	}
+	applyResourceLimits(cfg)
+
+	separator := strings.Repeat("=", 60)
+	fmt.Printf("\n%s\n", separator)
+	fmt.Println("Resuming auction simulation...")
+	fmt.Printf("%s\n", separator)
+
+	ctx, stop := signalContext()
+	defer stop()
+	return runSimulation(ctx, cfg, options{
+		format:          *format,
+		dbPath:          *dbPath,
+		checkpoint:      *path,
+		checkpointEvery: *every,
+		resume:          cp,
+	})
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/sweep"
+)
+
+// stringList collects a repeatable string flag
+type stringList []string
+
+// String implements flag.Value
+func (l *stringList) String() string { return strings.Join(*l, " ") }
+
+// Set implements flag.Value
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// runSweep evaluates the simulation over a grid or Latin-hypercube sample of
+// configuration values and writes one tidy table
+func runSweep(args []string) error {
+	var params stringList
+
+	fs := flag.NewFlagSet("sweep", flag.ExitOnError)
+	fs.Var(&params, "param", "swept field as Field=v1,v2,... or Field=min..max:steps[:log] (repeatable)")
+	mode := fs.String("mode", sweep.ModeGrid, "how to combine parameters: grid or lhs")
+	samples := fs.Int("samples", 20, "points to draw in lhs mode")
+	reps := fs.Int("reps", 1, "runs per parameter combination")
+	parallel := fs.Int("parallel", 0, "runs executing at once within a point (0 uses one per vCPU)")
+	seed := fs.Int64("seed", 0, "base seed shared by every point (0 picks one from the clock)")
+	confidence := fs.Float64("confidence", 0.95, "confidence level for per-point intervals")
+	out := fs.String("out", "", "output CSV path (default output/sweep_<timestamp>.csv)")
+	workers := fs.Int("workers", 0, "evaluate points on this many local worker processes at once (0 runs them in this process)")
+	verbose := fs.Bool("verbose", false, "keep per-auction log output")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: simulator sweep -param Field=spec [-param ...] [flags]\n\n")
+		fmt.Fprintf(fs.Output(), "Examples:\n")
+		fmt.Fprintf(fs.Output(), "  -param TotalBidders=10..1000:5:log\n")
+		fmt.Fprintf(fs.Output(), "  -param AuctionTimeout=50ms..2s:4 -param MaxBaseBid=100,150,200\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if len(params) == 0 {
+		fs.Usage()
+		return fmt.Errorf("sweep needs at least one -param")
+	}
+
+	dims := make([]*sweep.Dimension, len(params))
+	for i, param := range params {
+		dim, err := sweep.ParseDimension(param)
+		if err != nil {
+			return err
+		}
+		dims[i] = dim
+	}
+
+	cfg := config.DefaultConfig()
+	if *seed != 0 {
+		cfg.Seed = *seed
+	}
+	if err := validateEnvironment(cfg); err != nil {
+		return fmt.Errorf("environment validation failed: %w", err)
+	}
+	applyResourceLimits(cfg)
+
+	opts := sweep.Options{
+		Mode:        *mode,
+		Samples:     *samples,
+		Repetitions: *reps,
+		Parallel:    *parallel,
+		Confidence:  *confidence,
+	}
+	points, err := sweep.Points(dims, opts, cfg.Seed)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Sweeping %d points x %d runs (%s mode, seed %d)...\n", len(points), *reps, *mode, cfg.Seed)
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	opts.Log = runLog(*verbose)
+	if *workers > 0 {
+		coordinator, err := startWorkers(*workers, cfg, opts.Log)
+		if err != nil {
+			return err
+		}
+		defer coordinator.Close()
+		opts.Concurrency = coordinator.Workers()
+		opts.RunBatch = coordinator.RunBatch
+	}
+	rows, runErr := sweep.Run(ctx, cfg, points, opts)
+	if runErr != nil && ctx.Err() == nil {
+		return runErr
+	}
+
+	path := *out
+	if path == "" {
+		if err := os.MkdirAll("output", 0755); err != nil {
+			return fmt.Errorf("could not create output directory: %w", err)
+		}
+		path = fmt.Sprintf("output/sweep_%s.csv", time.Now().Format("20060102_150405"))
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("could not create sweep table: %w", err)
+	}
+	defer file.Close()
+
+	if err := sweep.WriteCSV(file, dims, rows); err != nil {
+		return err
+	}
+
+	// An interrupted sweep keeps the points that finished
+	if runErr != nil {
+		log.Printf("Sweep interrupted after %d of %d points; incomplete table saved to: %s", len(rows), len(points), path)
+		return runErr
+	}
+	invalid := 0
+	for _, row := range rows {
+		if row.Invalid != nil {
+			invalid++
+		}
+	}
+	if invalid > 0 {
+		log.Printf("Warning: %d invalid points were not run; see the error column", invalid)
+	}
+	log.Printf("Sweep table with %d rows saved to: %s", len(rows), path)
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+
+	"auction-simulator/internal/cluster"
+	"auction-simulator/internal/config"
+)
+
+// runWorker serves simulation requests from a coordinator until it
+// disconnects. Coordinators start workers themselves with -workers.
+func runWorker(args []string) error {
+	fs := flag.NewFlagSet("worker", flag.ExitOnError)
+	listen := fs.String("listen", "127.0.0.1:0", "address to serve the coordinator on")
+	maxMemoryMB := fs.Int("max-memory-mb", 0, "memory limit in MB (0 derives it from the container limit)")
+	fs.Parse(args)
+
+	cfg := config.DefaultConfig()
+	if *maxMemoryMB > 0 {
+		cfg.ResourceLimits.MaxMemoryMB = *maxMemoryMB
+	}
+	applyResourceLimits(cfg)
+
+	listener, err := net.Listen("tcp", *listen)
+	if err != nil {
+		return fmt.Errorf("could not listen: %w", err)
+	}
+	fmt.Printf("%s%s\n", cluster.ReadyPrefix, listener.Addr())
+	log.SetPrefix(fmt.Sprintf("[worker %d] ", os.Getpid()))
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	// The coordinator closes our input when it is done with us, or when it
+	// exits without doing so
+	go func() {
+		io.Copy(io.Discard, os.Stdin)
+		stop()
+	}()
+
+	return cluster.Serve(ctx, listener)
+}
+
+// startWorkers launches n local worker processes of this executable,
+// splitting the memory limit between them
+func startWorkers(n int, cfg *config.Config, logOutput io.Writer) (*cluster.Coordinator, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("could not locate the simulator executable: %w", err)
+	}
+	memoryMB := max(100, cfg.ResourceLimits.MaxMemoryMB/n)
+	return cluster.StartWorkers(n, logOutput, executable, "worker", "-max-memory-mb", fmt.Sprint(memoryMB))
+}
@@ -0,0 +1,205 @@
+package arrival
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"auction-simulator/internal/config"
+	"auction-simulator/pkg/utils"
+)
+
+// Process generates the gaps between consecutive auction arrivals
+type Process interface {
+	// Next returns the time until the next arrival, or false when the
+	// process has no more arrivals
+	Next() (time.Duration, bool)
+}
+
+// Poisson arrivals have exponentially distributed gaps at a mean rate
+type Poisson struct {
+	Rate float64 // arrivals per second
+	rng  *utils.RNG
+}
+
+// Next implements Process
+func (p *Poisson) Next() (time.Duration, bool) {
+	return seconds(p.rng.ExpFloat64() / p.Rate), true
+}
+
+// Constant arrivals are evenly spaced
+type Constant struct {
+	Rate float64 // arrivals per second
+}
+
+// Next implements Process
+func (c *Constant) Next() (time.Duration, bool) {
+	return seconds(1 / c.Rate), true
+}
+
+// Bursty is a two-state Markov-modulated Poisson process: arrivals come at
+// Rate between bursts and at BurstRate during them, and the time spent in
+// each state is exponentially distributed
+type Bursty struct {
+	Rate        float64
+	BurstRate   float64
+	BurstEvery  time.Duration // mean time between bursts
+	BurstLength time.Duration // mean burst duration
+
+	rng       *utils.RNG
+	bursting  bool
+	remaining float64 // seconds left in the current state
+}
+
+// Next implements Process
+func (b *Bursty) Next() (time.Duration, bool) {
+	gap := 0.0
+	for {
+		rate, mean := b.Rate, b.BurstEvery.Seconds()
+		if b.bursting {
+			rate, mean = b.BurstRate, b.BurstLength.Seconds()
+		}
+		if b.remaining <= 0 {
+			b.remaining = b.rng.ExpFloat64() * mean
+		}
+
+		// Exponential gaps are memoryless, so a gap that overruns the state
+		// can be redrawn at the next state's rate from the switch point
+		next := math.Inf(1)
+		if rate > 0 {
+			next = b.rng.ExpFloat64() / rate
+		}
+		if next <= b.remaining {
+			b.remaining -= next
+			return seconds(gap + next), true
+		}
+		gap += b.remaining
+		b.remaining = 0
+		b.bursting = !b.bursting
+	}
+}
+
+// Trace replays recorded arrival times, sped up by a factor
+type Trace struct {
+	gaps  []time.Duration
+	speed float64
+	pos   int
+}
+
+// Next implements Process
+func (t *Trace) Next() (time.Duration, bool) {
+	if t.pos >= len(t.gaps) {
+		return 0, false
+	}
+	gap := t.gaps[t.pos]
+	t.pos++
+	return scaled(gap, t.speed), true
+}
+
+// LoadTrace reads arrival times, one per line, as seconds from the start of
+// the trace or RFC 3339 timestamps. Only the first comma-separated field is
+// read, so CSV exports work; blank lines, '#' comments and a non-numeric
+// header are skipped.
+func LoadTrace(path string) (*Trace, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open arrival trace: %w", err)
+	}
+	defer file.Close()
+
+	var offsets []float64
+	var origin time.Time
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		field, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ",")
+		field = strings.TrimSpace(field)
+		if field == "" || strings.HasPrefix(field, "#") {
+			continue
+		}
+
+		if v, err := strconv.ParseFloat(field, 64); err == nil {
+			offsets = append(offsets, v)
+			continue
+		}
+		ts, err := time.Parse(time.RFC3339Nano, field)
+		if err != nil {
+			if line == 1 {
+				continue // header
+			}
+			return nil, fmt.Errorf("arrival trace line %d: cannot parse %q", line, field)
+		}
+		if origin.IsZero() {
+			origin = ts
+		}
+		offsets = append(offsets, ts.Sub(origin).Seconds())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read arrival trace: %w", err)
+	}
+	if len(offsets) == 0 {
+		return nil, fmt.Errorf("arrival trace %s has no arrivals", path)
+	}
+
+	sort.Float64s(offsets)
+	trace := &Trace{gaps: make([]time.Duration, len(offsets))}
+	prev := offsets[0]
+	for i, offset := range offsets {
+		trace.gaps[i] = seconds(offset - prev)
+		prev = offset
+	}
+	return trace, nil
+}
+
+// FromConfig builds the arrival process described by cfg, seeded for
+// reproducible arrival times
+func FromConfig(cfg config.ArrivalConfig, seed int64) (Process, error) {
+	rng := utils.NewRNG(utils.DeriveSeed(seed, "arrivals"))
+
+	switch cfg.Process {
+	case config.ArrivalPoisson:
+		if cfg.Rate <= 0 {
+			return nil, fmt.Errorf("poisson arrivals need a positive rate")
+		}
+		return &Poisson{Rate: cfg.Rate, rng: rng}, nil
+	case config.ArrivalConstant:
+		if cfg.Rate <= 0 {
+			return nil, fmt.Errorf("constant arrivals need a positive rate")
+		}
+		return &Constant{Rate: cfg.Rate}, nil
+	case config.ArrivalBursty:
+		if cfg.BurstRate <= 0 || cfg.BurstEvery <= 0 || cfg.BurstLength <= 0 {
+			return nil, fmt.Errorf("bursty arrivals need a burst rate, interval and length")
+		}
+		return &Bursty{
+			Rate:        cfg.Rate,
+			BurstRate:   cfg.BurstRate,
+			BurstEvery:  cfg.BurstEvery,
+			BurstLength: cfg.BurstLength,
+			rng:         rng,
+		}, nil
+	case config.ArrivalTrace:
+		if cfg.TracePath == "" {
+			return nil, fmt.Errorf("trace arrivals need a trace file")
+		}
+		trace, err := LoadTrace(cfg.TracePath)
+		if err != nil {
+			return nil, err
+		}
+		trace.speed = cfg.Speed
+		return trace, nil
+	case config.ArrivalReplay:
+		return nil, fmt.Errorf("replay arrivals carry their auctions; load them with LoadReplay")
+	default:
+		return nil, fmt.Errorf("unknown arrival process: %s", cfg.Process)
+	}
+}
+
+// seconds converts fractional seconds to a duration
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
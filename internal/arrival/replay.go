@@ -0,0 +1,262 @@
+package arrival
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"auction-simulator/internal/catalog"
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/types"
+)
+
+// Request is a recorded bid request, replayed as the auction it asked for
+type Request struct {
+	AuctionID  string
+	Offset     time.Duration // since the first request
+	Attributes []types.Attribute
+	Reserve    float64 // 0 uses the reserve policy
+}
+
+// record is a line of a bid request log: the fields of a types.BidRequest
+// the replay needs, attributes as values in schema order or by name, and
+// an optional reserve
+type record struct {
+	AuctionID  string          `json:"auction_id"`
+	Attributes json.RawMessage `json:"attributes"`
+	Timestamp  json.RawMessage `json:"timestamp"`
+	Reserve    float64         `json:"reserve"`
+}
+
+// Replay replays recorded bid requests with their recorded timing, sped up
+// by a factor
+type Replay struct {
+	requests []Request
+	speed    float64
+	pos      int
+}
+
+// Next implements Process
+func (r *Replay) Next() (time.Duration, bool) {
+	if r.pos >= len(r.requests) {
+		return 0, false
+	}
+	gap := r.requests[r.pos].Offset
+	if r.pos > 0 {
+		gap -= r.requests[r.pos-1].Offset
+	}
+	r.pos++
+	return scaled(gap, r.speed), true
+}
+
+// Request returns the i-th request in arrival order
+func (r *Replay) Request(i int) (Request, bool) {
+	if i < 0 || i >= len(r.requests) {
+		return Request{}, false
+	}
+	return r.requests[i], true
+}
+
+// Len returns the number of requests
+func (r *Replay) Len() int {
+	return len(r.requests)
+}
+
+// LoadReplay reads a log of bid requests, one JSON object per line, and
+// returns them in timestamp order with the attribute schema they follow.
+// Timestamps are RFC 3339 or seconds from the start of the log. Attributes
+// are either arrays of values in the order of the given schema, or of
+// unnamed attributes if there is none, or objects keyed by attribute name,
+// whose schema is inferred as for a catalog unless one is given.
+func LoadReplay(path string, given []config.AttributeSpec, speed float64) (*Replay, []config.AttributeSpec, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not open bid request log: %w", err)
+	}
+	defer file.Close()
+
+	var records []record
+	var offsets []float64
+	var origin time.Time
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		var rec record
+		if err := json.Unmarshal(text, &rec); err != nil {
+			return nil, nil, fmt.Errorf("bid request log line %d: %w", line, err)
+		}
+		if rec.AuctionID == "" {
+			return nil, nil, fmt.Errorf("bid request log line %d: no auction_id", line)
+		}
+		offset, err := parseTimestamp(rec.Timestamp, &origin)
+		if err != nil {
+			return nil, nil, fmt.Errorf("bid request log line %d: %w", line, err)
+		}
+		records = append(records, rec)
+		offsets = append(offsets, offset)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("could not read bid request log: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil, fmt.Errorf("bid request log %s has no requests", path)
+	}
+
+	attributes, schema, err := replayAttributes(records, given)
+	if err != nil {
+		return nil, nil, fmt.Errorf("bid request log %s: %w", path, err)
+	}
+
+	order := make([]int, len(records))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return offsets[order[a]] < offsets[order[b]] })
+
+	replay := &Replay{requests: make([]Request, len(records)), speed: speed}
+	seen := make(map[string]bool, len(records))
+	first := offsets[order[0]]
+	for i, idx := range order {
+		rec := records[idx]
+		if seen[rec.AuctionID] {
+			return nil, nil, fmt.Errorf("bid request log %s: auction %s is requested twice", path, rec.AuctionID)
+		}
+		seen[rec.AuctionID] = true
+		replay.requests[i] = Request{
+			AuctionID:  rec.AuctionID,
+			Offset:     seconds(offsets[idx] - first),
+			Attributes: attributes[idx],
+			Reserve:    rec.Reserve,
+		}
+	}
+	return replay, schema, nil
+}
+
+// parseTimestamp reads a timestamp as seconds since the first one in the
+// log, which sets origin
+func parseTimestamp(raw json.RawMessage, origin *time.Time) (float64, error) {
+	if len(raw) == 0 {
+		return 0, fmt.Errorf("no timestamp")
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err != nil {
+		v, err := strconv.ParseFloat(string(raw), 64)
+		if err != nil {
+			return 0, fmt.Errorf("cannot parse timestamp %s", raw)
+		}
+		return v, nil
+	}
+	ts, err := time.Parse(time.RFC3339Nano, text)
+	if err != nil {
+		return 0, fmt.Errorf("cannot parse timestamp %q", text)
+	}
+	if origin.IsZero() {
+		*origin = ts
+	}
+	return ts.Sub(*origin).Seconds(), nil
+}
+
+// replayAttributes converts the attributes of every record, which must all
+// be arrays or all be objects, and returns the schema they follow
+func replayAttributes(records []record, given []config.AttributeSpec) ([][]types.Attribute, []config.AttributeSpec, error) {
+	attributes := make([][]types.Attribute, len(records))
+	if isArray(records[0].Attributes) {
+		schema := given
+		for i, rec := range records {
+			var values []float64
+			if err := json.Unmarshal(rec.Attributes, &values); err != nil {
+				return nil, nil, fmt.Errorf("auction %s: attributes must all be arrays of numbers", rec.AuctionID)
+			}
+			if schema == nil {
+				schema = config.DefaultAttributeSchema(len(values))
+			}
+			if len(values) != len(schema) {
+				return nil, nil, fmt.Errorf("auction %s has %d attributes, want %d", rec.AuctionID, len(values), len(schema))
+			}
+			attributes[i] = make([]types.Attribute, len(values))
+			for j, value := range values {
+				attribute, err := positional(j, value, &schema[j])
+				if err != nil {
+					return nil, nil, fmt.Errorf("auction %s: %w", rec.AuctionID, err)
+				}
+				attributes[i][j] = attribute
+			}
+		}
+		return attributes, schema, nil
+	}
+
+	// Named attributes read as catalog items, so their schema is inferred
+	// the same way
+	items := make([]catalog.Item, len(records))
+	for i, rec := range records {
+		var named map[string]any
+		if err := json.Unmarshal(rec.Attributes, &named); err != nil || named == nil {
+			return nil, nil, fmt.Errorf("auction %s: attributes must all be objects", rec.AuctionID)
+		}
+		items[i] = catalog.Item{ID: rec.AuctionID, Attributes: make(map[string]string, len(named))}
+		for name, value := range named {
+			switch v := value.(type) {
+			case string:
+				items[i].Attributes[name] = v
+			case float64:
+				items[i].Attributes[name] = strconv.FormatFloat(v, 'f', -1, 64)
+			case bool:
+				items[i].Attributes[name] = strconv.FormatBool(v)
+			default:
+				return nil, nil, fmt.Errorf("auction %s: attribute %s must be a string, number or boolean", rec.AuctionID, name)
+			}
+		}
+	}
+	schema := catalog.Schema(items, given)
+	for i, item := range items {
+		converted, err := catalog.Attributes(item, schema)
+		if err != nil {
+			return nil, nil, err
+		}
+		attributes[i] = converted
+	}
+	return attributes, schema, nil
+}
+
+// positional makes attribute id of a value recorded in schema order
+func positional(id int, value float64, spec *config.AttributeSpec) (types.Attribute, error) {
+	attribute := types.Attribute{ID: id, Name: spec.Name, Value: value}
+	switch spec.Type {
+	case config.AttributeCategorical:
+		index := int(value)
+		if float64(index) != value || index < 0 || index >= len(spec.Categories) {
+			return attribute, fmt.Errorf("%g is not a category index of %s", value, spec.Name)
+		}
+		attribute.Label = spec.Categories[index]
+	case config.AttributeBoolean:
+		if value != 0 && value != 1 {
+			return attribute, fmt.Errorf("boolean %s must be 0 or 1, not %g", spec.Name, value)
+		}
+		attribute.Label = strconv.FormatBool(value == 1)
+	}
+	return attribute, nil
+}
+
+// isArray reports whether a JSON value is an array
+func isArray(raw json.RawMessage) bool {
+	raw = bytes.TrimSpace(raw)
+	return len(raw) > 0 && raw[0] == '['
+}
+
+// scaled divides a recorded gap by a speed-up; 0 keeps it as recorded
+func scaled(gap time.Duration, speed float64) time.Duration {
+	if speed <= 0 {
+		return gap
+	}
+	return time.Duration(float64(gap) / speed)
+}
@@ -0,0 +1,131 @@
+package arrival
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"auction-simulator/internal/config"
+)
+
+// writeLog writes a bid request log of the given lines
+func writeLog(t *testing.T, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "requests.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadReplayOrdersByTimestamp(t *testing.T) {
+	path := writeLog(t,
+		`{"auction_id": "c", "timestamp": "2026-01-01T00:00:03Z", "attributes": [3]}`,
+		`{"auction_id": "a", "timestamp": "2026-01-01T00:00:01Z", "attributes": [1], "reserve": 2.5}`,
+		``,
+		`{"auction_id": "b", "timestamp": "2026-01-01T00:00:01.5Z", "attributes": [2]}`,
+	)
+
+	replay, schema, err := LoadReplay(path, nil, 2)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(schema) != 1 || replay.Len() != 3 {
+		t.Fatalf("%d requests with %d attributes, want 3 with 1", replay.Len(), len(schema))
+	}
+
+	want := []struct {
+		id     string
+		offset time.Duration
+		value  float64
+	}{
+		{"a", 0, 1},
+		{"b", 500 * time.Millisecond, 2},
+		{"c", 2 * time.Second, 3},
+	}
+	for i, w := range want {
+		req, _ := replay.Request(i)
+		if req.AuctionID != w.id || req.Offset != w.offset || req.Attributes[0].Value != w.value {
+			t.Errorf("request %d is %s at %v with %v, want %s at %v with %g",
+				i, req.AuctionID, req.Offset, req.Attributes, w.id, w.offset, w.value)
+		}
+	}
+	if req, _ := replay.Request(0); req.Reserve != 2.5 {
+		t.Errorf("reserve %g, want 2.5", req.Reserve)
+	}
+
+	// Gaps between arrivals, halved by the speed-up
+	for i, gap := range []time.Duration{0, 250 * time.Millisecond, 750 * time.Millisecond} {
+		got, ok := replay.Next()
+		if !ok || got != gap {
+			t.Errorf("gap %d is %v (%v), want %v", i, got, ok, gap)
+		}
+	}
+	if _, ok := replay.Next(); ok {
+		t.Error("replay continued past its last request")
+	}
+}
+
+func TestLoadReplaySecondsAndNamedAttributes(t *testing.T) {
+	path := writeLog(t,
+		`{"auction_id": "late", "timestamp": 12.5, "attributes": {"color": "red", "size": 3}}`,
+		`{"auction_id": "early", "timestamp": 10, "attributes": {"color": "blue", "size": 1}}`,
+	)
+
+	replay, schema, err := LoadReplay(path, nil, 0)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(schema) != 2 {
+		t.Fatalf("inferred schema %+v, want color and size", schema)
+	}
+	first, _ := replay.Request(0)
+	second, _ := replay.Request(1)
+	if first.AuctionID != "early" || first.Offset != 0 || second.AuctionID != "late" || second.Offset != 2500*time.Millisecond {
+		t.Errorf("replayed %s at %v then %s at %v, want early at 0s then late at 2.5s",
+			first.AuctionID, first.Offset, second.AuctionID, second.Offset)
+	}
+}
+
+func TestLoadReplayRejectsBadLogs(t *testing.T) {
+	categorical := []config.AttributeSpec{{Name: "color", Type: config.AttributeCategorical, Categories: []string{"red", "blue"}}}
+
+	tests := []struct {
+		name   string
+		lines  []string
+		schema []config.AttributeSpec
+		want   string
+	}{
+		{"duplicate id", []string{
+			`{"auction_id": "a", "timestamp": 1, "attributes": [1]}`,
+			`{"auction_id": "a", "timestamp": 2, "attributes": [2]}`,
+		}, nil, "auction a is requested twice"},
+		{"malformed json", []string{
+			`{"auction_id": "a", "timestamp": 1, "attributes": [1]}`,
+			`{"auction_id": "b", "timestamp": 2,`,
+		}, nil, "line 2"},
+		{"no auction id", []string{`{"timestamp": 1, "attributes": [1]}`}, nil, "no auction_id"},
+		{"no timestamp", []string{`{"auction_id": "a", "attributes": [1]}`}, nil, "no timestamp"},
+		{"bad timestamp", []string{`{"auction_id": "a", "timestamp": "yesterday", "attributes": [1]}`}, nil, "cannot parse timestamp"},
+		{"attribute count", []string{
+			`{"auction_id": "a", "timestamp": 1, "attributes": [1, 2]}`,
+			`{"auction_id": "b", "timestamp": 2, "attributes": [1]}`,
+		}, nil, "auction b has 1 attributes, want 2"},
+		{"mixed attributes", []string{
+			`{"auction_id": "a", "timestamp": 1, "attributes": [1]}`,
+			`{"auction_id": "b", "timestamp": 2, "attributes": {"size": 1}}`,
+		}, nil, "must all be arrays"},
+		{"category out of range", []string{`{"auction_id": "a", "timestamp": 1, "attributes": [2]}`}, categorical, "not a category index"},
+		{"empty log", []string{``, ``}, nil, "has no requests"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := LoadReplay(writeLog(t, tt.lines...), tt.schema, 1)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -1,10 +1,14 @@
 package auction
 
 import (
+	"auction-simulator/internal/arrival"
+	"auction-simulator/internal/catalog"
 	"auction-simulator/internal/config"
 	"auction-simulator/internal/types"
+	"auction-simulator/pkg/utils"
 	"fmt"
 	"log"
+	"math"
 	"sync"
 	"time"
 )
@@ -17,6 +21,10 @@ type Manager struct {
 	metrics   *Metrics
 	startTime time.Time
 	endTime   time.Time
+	rng       *utils.RNG
+	catalog   *catalog.Catalog // nil for auctions of random items
+	replay    *arrival.Replay  // recorded requests the auctions are made from, or nil
+	logger    *log.Logger
 }
 
 // Metrics tracks auction performance
@@ -35,46 +43,130 @@ func NewManager(cfg *config.Config) *Manager {
 		auctions: make([]*Auction, 0, cfg.TotalAuctions),
 		results:  make(chan *types.AuctionResult, cfg.TotalAuctions), // Changed to types.AuctionResult
 		metrics:  &Metrics{},
+		rng:      utils.NewRNG(utils.DeriveSeed(cfg.Seed, "auctions")),
+		logger:   log.Default(),
 	}
 }
 
-// InitializeAuctions creates all auction instances
+// InitializeAuctions creates all auction instances up front. With a
+// catalog each takes a unit, so there are no more auctions than units, and
+// units that go unsold are not auctioned again; only streamed runs, which
+// create auctions as they arrive, put them up a second time.
 func (m *Manager) InitializeAuctions() error {
-	log.Printf("Initializing %d auctions...", m.config.TotalAuctions)
+	m.logger.Printf("Initializing %d auctions...", m.config.TotalAuctions)
 
 	for i := 0; i < m.config.TotalAuctions; i++ {
 		auction := m.createAuction(i)
+		if auction == nil {
+			m.logger.Printf("📦 All catalog units allocated after %d auctions; unsold units are not auctioned again", i)
+			break
+		}
 		m.auctions = append(m.auctions, auction)
 	}
 
-	log.Printf("✅ Successfully initialized %d auctions", len(m.auctions))
+	m.logger.Printf("✅ Successfully initialized %d auctions", len(m.auctions))
 	return nil
 }
 
-// createAuction generates a single auction with random attributes
+// SetLogger sends the manager's progress messages to l instead of the
+// standard logger
+func (m *Manager) SetLogger(l *log.Logger) {
+	m.logger = l
+}
+
+// SetCatalog auctions the items of c instead of random ones
+func (m *Manager) SetCatalog(c *catalog.Catalog) {
+	m.catalog = c
+}
+
+// SetReplay makes auctions from recorded bid requests instead of random
+// ones, the auction with index i from the i-th request
+func (m *Manager) SetReplay(r *arrival.Replay) {
+	m.replay = r
+}
+
+// createAuction generates a single auction with random attributes, of the
+// next catalog item, or from a recorded request. It returns nil while no
+// catalog item is available and once the requests run out.
 func (m *Manager) createAuction(id int) *Auction {
-	attributes := make([]types.Attribute, m.config.AttributesPerAuction) // Changed to types.Attribute
-	for j := 0; j < m.config.AttributesPerAuction; j++ {
-		attributes[j] = types.Attribute{ // Changed to types.Attribute
-			ID:    j,
-			Value: generateAttributeValue(),
+	var item catalog.Item
+	var attributes []types.Attribute
+	var fixedReserve float64 // set by the item or request, overriding the policy
+	auctionID := fmt.Sprintf("auction-%d", id+1)
+	if m.replay != nil {
+		request, ok := m.replay.Request(id)
+		if !ok {
+			return nil
 		}
+		auctionID, attributes, fixedReserve = request.AuctionID, request.Attributes, request.Reserve
+	} else if m.catalog != nil {
+		var ok bool
+		if item, attributes, ok = m.catalog.Take(m.rng); !ok {
+			return nil
+		}
+		fixedReserve = item.Reserve
+	} else {
+		schema := m.config.AttributeSchema()
+		attributes = make([]types.Attribute, len(schema)) // Changed to types.Attribute
+		for j := range schema {
+			attributes[j] = m.generateAttribute(j, &schema[j])
+		}
+	}
+
+	values := make([]float64, len(attributes))
+	for j, attr := range attributes {
+		values[j] = attr.Value
+	}
+	reserve := m.config.Reserve.For(auctionID, values)
+	if fixedReserve > 0 {
+		reserve = fixedReserve
 	}
 
 	return &Auction{
-		ID:         fmt.Sprintf("auction-%d", id+1),
+		ID:         auctionID,
+		ItemID:     item.ID,
 		Attributes: attributes,
 		Timeout:    m.config.AuctionTimeout,
+		Format:     m.config.Format,
+		Mechanism:  m.config.Mechanism,
+		TieBreak:   m.config.TieBreak,
+		Seed:       utils.DeriveSeed(m.config.Seed, "ties", auctionID),
+		Reserve:    reserve,
+		Duplicates: m.config.Validation.Duplicates,
 		Bids:       make([]types.Bid, 0), // Changed to types.Bid
 		IsComplete: false,
 	}
 }
 
+// NewAuction creates the auction with the given zero-based index without
+// registering it, for auctions that arrive while the simulation runs. It
+// returns nil while no catalog item is available.
+func (m *Manager) NewAuction(id int) *Auction {
+	return m.createAuction(id)
+}
+
+// Settle returns the item of an auction that did not sell to the catalog
+func (m *Manager) Settle(auct *Auction, sold bool) {
+	if m.catalog != nil && auct.ItemID != "" {
+		m.catalog.Settle(auct.ItemID, sold)
+	}
+}
+
+// SoldOut reports whether the catalog has been sold out
+func (m *Manager) SoldOut() bool {
+	return m.catalog != nil && m.catalog.SoldOut()
+}
+
 // GetAuctions returns all auctions (thread-safe)
 func (m *Manager) GetAuctions() []*Auction {
 	return m.auctions
 }
 
+// RNGPosition returns how far the auction generator has advanced
+func (m *Manager) RNGPosition() uint64 {
+	return m.rng.Position()
+}
+
 // GetMetrics returns current metrics
 func (m *Manager) GetMetrics() *Metrics {
 	return m.metrics
@@ -94,7 +186,61 @@ func (m *Manager) RecordResult(result *types.AuctionResult) {
 	m.metrics.totalBids += result.TotalBids
 }
 
-// generateAttributeValue creates random attribute values
-func generateAttributeValue() float64 {
-	return float64(int(time.Now().UnixNano())%10000) / 100.0
+// generateAttribute draws the value of attribute id from its spec
+func (m *Manager) generateAttribute(id int, spec *config.AttributeSpec) types.Attribute {
+	attribute := types.Attribute{ID: id, Name: spec.Name}
+	switch spec.Type {
+	case config.AttributeCategorical:
+		index := m.pickCategory(spec)
+		attribute.Value = float64(index)
+		attribute.Label = spec.Categories[index]
+	case config.AttributeBoolean:
+		probability := spec.Probability
+		if probability == 0 {
+			probability = 0.5
+		}
+		attribute.Label = "false"
+		if m.rng.RandomChance(probability) {
+			attribute.Value = 1
+			attribute.Label = "true"
+		}
+	default:
+		attribute.Value = m.generateAttributeValue(spec)
+	}
+	return attribute
+}
+
+// generateAttributeValue draws a numeric attribute value to the cent
+func (m *Manager) generateAttributeValue(spec *config.AttributeSpec) float64 {
+	var value float64
+	switch spec.Distribution {
+	case config.DistNormal:
+		value = spec.Mean + spec.StdDev*m.rng.NormFloat64()
+	case config.DistLogNormal:
+		value = math.Exp(spec.Mean + spec.StdDev*m.rng.NormFloat64())
+	default:
+		cents := max(1, int(math.Round((spec.Max-spec.Min)*100)))
+		return spec.Min + float64(m.rng.Intn(cents))/100.0
+	}
+	value = math.Max(spec.Min, math.Min(spec.Max, value))
+	return math.Round(value*100) / 100
+}
+
+// pickCategory draws the index of a category by the categories' weights
+func (m *Manager) pickCategory(spec *config.AttributeSpec) int {
+	if len(spec.Weights) == 0 {
+		return m.rng.Intn(len(spec.Categories))
+	}
+	total := 0.0
+	for _, w := range spec.Weights {
+		total += w
+	}
+	target := m.rng.Float64() * total
+	for i, w := range spec.Weights {
+		if target < w {
+			return i
+		}
+		target -= w
+	}
+	return len(spec.Weights) - 1
 }
@@ -0,0 +1,206 @@
+package auction
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"auction-simulator/internal/types"
+)
+
+// noticeLog tracks notices in flight and tallies them once delivered
+type noticeLog struct {
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	sent   map[string]int
+	failed int
+}
+
+// record tallies a notice by kind, or as failed
+func (n *noticeLog) record(kind string, err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if err != nil {
+		n.failed++
+		return
+	}
+	if n.sent == nil {
+		n.sent = make(map[string]int)
+	}
+	n.sent[kind]++
+}
+
+// wait waits for the notices in flight
+func (n *noticeLog) wait() {
+	n.wg.Wait()
+}
+
+// Notices returns the notices delivered so far by kind, and how many could
+// not be delivered
+func (o *Orchestrator) Notices() (map[string]int, int) {
+	o.notices.mu.Lock()
+	defer o.notices.mu.Unlock()
+
+	sent := make(map[string]int, len(o.notices.sent))
+	for kind, n := range o.notices.sent {
+		sent[kind] = n
+	}
+	return sent, o.notices.failed
+}
+
+// sendNotices tells every bidder that bid in an auction, including bidders
+// whose bids were rejected, how it ended: winners get a win and a billing
+// notice, the others a loss notice with the reason, and bidders told nothing
+// forget the auction. Simulated bidders learn from their notices, so they
+// are told before the auction's result is returned and learn in the order
+// auctions complete. Remote bidders are told in the background so slow
+// bidders do not hold up the auction. A remote bidder gets the notices of
+// one auction in order, win before billing, but notices of different
+// auctions can reach it in any order.
+func (o *Orchestrator) sendNotices(processor *Processor, result *types.AuctionResult) {
+	highest := make(map[string]float64)
+	for _, bid := range result.Bids {
+		highest[bid.BidderID] = math.Max(highest[bid.BidderID], bid.Amount)
+	}
+	rejections := make(map[string]types.RejectedBid)
+	for _, bid := range result.Rejected {
+		rejections[bid.BidderID] = bid
+	}
+
+	paid := make(map[string]float64)
+	if result.Winner != nil && result.Error == nil {
+		paid[result.Winner.BidderID] = result.ClearingPrice
+		if result.Tie != nil && len(result.Tie.Shares) > 0 {
+			paid = make(map[string]float64)
+			for _, share := range result.Tie.Shares {
+				paid[share.BidderID] = share.Price
+			}
+		}
+	}
+
+	now := time.Now()
+	for _, b := range o.bidders {
+		notice := &types.Notice{
+			AuctionID:     result.AuctionID,
+			BidderID:      b.id,
+			ClearingPrice: result.ClearingPrice,
+			Timestamp:     now,
+		}
+		amount, bid := highest[b.id]
+		rejection, rejected := rejections[b.id]
+		switch {
+		case bid:
+			notice.Bid = amount
+		case rejected:
+			notice.Bid = rejection.Amount
+			notice.Reason = rejection.Reason
+		default:
+			forget(b, result.AuctionID)
+			continue
+		}
+
+		notices := []*types.Notice{notice}
+		if price, won := paid[b.id]; won {
+			notice.Kind = types.NoticeWin
+			notice.Price = price
+			billing := *notice
+			billing.Kind = types.NoticeBilling
+			notices = append(notices, &billing)
+		} else {
+			notice.Kind = types.NoticeLoss
+			if bid {
+				notice.Reason = lossReason(processor, result, b.id, amount)
+			}
+		}
+
+		if b.simulator != nil {
+			o.deliverAll(b, notices)
+			continue
+		}
+		o.notices.wg.Add(1)
+		go func(b registeredBidder) {
+			defer o.notices.wg.Done()
+			o.deliverAll(b, notices)
+		}(b)
+	}
+}
+
+// deliverAll sends the notices of one auction to a bidder in order
+func (o *Orchestrator) deliverAll(b registeredBidder, notices []*types.Notice) {
+	ctx, cancel := context.WithTimeout(context.Background(), o.config.AuctionTimeout)
+	defer cancel()
+	for _, notice := range notices {
+		delivered, err := o.deliver(ctx, b, notice)
+		if delivered {
+			o.notices.record(notice.Kind, err)
+		}
+		if err != nil {
+			o.logger.Printf("⚠️ Could not send %s notice of %s to %s: %v", notice.Kind, notice.AuctionID, b.id, err)
+		}
+	}
+}
+
+// forget tells a bidder that keeps state per auction that it will hear
+// nothing about auctionID
+func forget(b registeredBidder, auctionID string) {
+	if forgetter, ok := unwrap(b.bidder).(types.Forgetter); ok {
+		forgetter.Forget(auctionID)
+	}
+}
+
+// lossReason explains why a valid bid of amount lost the auction
+func lossReason(processor *Processor, result *types.AuctionResult, bidderID string, amount float64) string {
+	switch {
+	case processor.excluded[bidderID]:
+		return types.LossBudget
+	case amount < result.ReservePrice:
+		return types.LossBelowReserve
+	case result.Winner == nil || result.Error != nil:
+		return types.LossNoSale
+	case result.Tie != nil && amount == result.Tie.Amount:
+		return types.LossTieBreak
+	default:
+		return types.LossHigherBid
+	}
+}
+
+// deliver sends a notice to a bidder if it listens for its kind, and
+// reports whether it does. A panic in the bidder is recovered like in
+// evaluate.
+func (o *Orchestrator) deliver(ctx context.Context, b registeredBidder, notice *types.Notice) (delivered bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			o.logger.Printf("⚠️ Recovered panic in bidder %s: %v", b.id, r)
+			delivered, err = true, &BidderPanic{BidderID: b.id, Value: r}
+		}
+	}()
+
+	bidder := unwrap(b.bidder)
+	switch notice.Kind {
+	case types.NoticeWin:
+		if listener, ok := bidder.(types.WinListener); ok {
+			return true, listener.NotifyWin(ctx, notice)
+		}
+	case types.NoticeLoss:
+		if listener, ok := bidder.(types.LossListener); ok {
+			return true, listener.NotifyLoss(ctx, notice)
+		}
+	case types.NoticeBilling:
+		if listener, ok := bidder.(types.BillingListener); ok {
+			return true, listener.NotifyBilling(ctx, notice)
+		}
+	}
+	return false, nil
+}
+
+// unwrap returns the bidder inside any wrappers, the one notices are for
+func unwrap(bidder types.Bidder) types.Bidder {
+	for {
+		wrapper, ok := bidder.(types.Wrapper)
+		if !ok {
+			return bidder
+		}
+		bidder = wrapper.Unwrap()
+	}
+}
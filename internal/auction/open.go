@@ -0,0 +1,259 @@
+package auction
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/types"
+)
+
+// closer is the context of an open auction. It is done at the auction's
+// deadline, which bids in the soft-close window push back, or as soon as
+// its parent is done.
+type closer struct {
+	context.Context // parent, for values
+
+	cfg        config.OpenConfig
+	done       chan struct{}
+	stopParent func() bool
+
+	mu         sync.Mutex
+	deadline   time.Time
+	timer      *time.Timer
+	err        error
+	extensions int
+}
+
+// newCloser creates the context of an open auction closing after timeout
+func newCloser(parent context.Context, timeout time.Duration, cfg config.OpenConfig) *closer {
+	c := &closer{
+		Context:  parent,
+		cfg:      cfg,
+		done:     make(chan struct{}),
+		deadline: time.Now().Add(timeout),
+	}
+	c.timer = time.AfterFunc(timeout, func() { c.close(context.DeadlineExceeded) })
+	c.stopParent = context.AfterFunc(parent, func() { c.close(parent.Err()) })
+	return c
+}
+
+// Deadline implements context.Context with the current deadline
+func (c *closer) Deadline() (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.deadline, true
+}
+
+// Done implements context.Context
+func (c *closer) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err implements context.Context
+func (c *closer) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// close ends the auction with err unless it has already ended
+func (c *closer) close(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	close(c.done)
+}
+
+// cancel releases the auction's timer once it is over
+func (c *closer) cancel() {
+	c.timer.Stop()
+	c.stopParent()
+	c.close(context.Canceled)
+}
+
+// extend pushes the deadline back if a bid received at received falls in
+// the soft-close window, and reports whether it did
+func (c *closer) extend(received time.Time) bool {
+	if !c.cfg.SoftClose() {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil || c.extensions >= c.cfg.MaxExtensions || c.deadline.Sub(received) > c.cfg.Window {
+		return false
+	}
+	if !c.timer.Stop() {
+		return false // already closing
+	}
+	c.deadline = c.deadline.Add(c.cfg.Extension)
+	c.timer.Reset(time.Until(c.deadline))
+	c.extensions++
+	return true
+}
+
+// Extensions returns how often the deadline was pushed back
+func (c *closer) Extensions() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.extensions
+}
+
+// standing is the high bid of an open auction
+type standing struct {
+	mu      sync.Mutex
+	amount  float64
+	leader  string
+	opening float64 // lowest acceptable first bid
+	step    float64 // least raise over the standing bid
+}
+
+// minBid returns the lowest bid that is currently acceptable
+func (s *standing) minBid() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.leader == "" {
+		return s.opening
+	}
+	return s.amount + s.step
+}
+
+// request builds the bid request for a bidder's round of an open auction
+func (s *standing) request(auct *Auction, attributes []float64, round int, deadline time.Time, interval time.Duration) *types.BidRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	request := &types.BidRequest{
+		AuctionID:  auct.ID,
+		Attributes: attributes,
+		Timeout:    deadline.Sub(now),
+		Timestamp:  now,
+		Round:      round,
+		MinBid:     s.opening,
+		Leader:     s.leader,
+		Deadline:   deadline,
+	}
+	if s.leader != "" {
+		request.MinBid = s.amount + s.step
+	}
+	if next := now.Add(interval); next.Before(deadline) {
+		request.NextRound = next
+	}
+	return request
+}
+
+// raise records an accepted bid as the standing high bid
+func (s *standing) raise(bid types.Bid) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.amount = bid.Amount
+	s.leader = bid.BidderID
+}
+
+// collectOpenBids runs an open auction until it closes. Every bidder is
+// asked in rounds, seeing the standing high bid, until it withdraws. It
+// returns the number of failed requests by fault kind and the rejected
+// bids.
+func (o *Orchestrator) collectOpenBids(closing *closer, processor *Processor, auct *Auction) (map[string]int, []types.RejectedBid) {
+	var wg sync.WaitGroup
+	responseCh := make(chan *response, len(o.bidders))
+
+	var faultsMu sync.Mutex
+	faults := make(map[string]int)
+	recordFault := func(kind string) {
+		faultsMu.Lock()
+		faults[kind]++
+		faultsMu.Unlock()
+	}
+
+	attributeValues := make([]float64, len(auct.Attributes))
+	for i, attr := range auct.Attributes {
+		attributeValues[i] = attr.Value
+	}
+
+	step := o.config.Validation.Increment
+	if step <= 0 {
+		step = 0.01
+	}
+	high := &standing{
+		opening: math.Max(step, math.Max(auct.Reserve, o.config.Validation.MinBid)),
+		step:    step,
+	}
+	interval := o.config.Open.RoundInterval
+
+	for _, b := range o.bidders {
+		wg.Add(1)
+
+		go func(b registeredBidder) {
+			defer wg.Done()
+
+			for round := 1; closing.Err() == nil; round++ {
+				permit, err := o.limiter.Acquire(closing, auct.ID)
+				if err != nil {
+					return
+				}
+
+				deadline, _ := closing.Deadline()
+				request := high.request(auct, attributeValues, round, deadline, interval)
+				bidResponse, err := o.evaluate(closing, b, request)
+				permit.Release(requestOutcome(closing, err, time.Since(request.Timestamp), request.Timeout))
+				if kind := faultKind(closing, err); kind != "" {
+					recordFault(kind)
+				}
+				if err == nil && bidResponse != nil {
+					if bidResponse.Withdrawn {
+						return
+					}
+					responseCh <- &response{BidResponse: bidResponse, requestedFrom: b.id, request: request, received: time.Now()}
+				}
+
+				select {
+				case <-closing.Done():
+				case <-time.After(time.Until(request.Timestamp.Add(interval))):
+				}
+			}
+		}(b)
+	}
+
+	go func() {
+		wg.Wait()
+		close(responseCh)
+	}()
+
+	var rejections []types.RejectedBid
+	for r := range responseCh {
+		deadline, _ := closing.Deadline()
+		reason := o.validator.Validate(r, r.request, deadline)
+		if reason == "" && r.Amount < high.minBid()-1e-9 {
+			reason = types.RejectOutbid
+		}
+		if reason != "" {
+			if malformed(reason) {
+				recordFault(types.FaultMalformed)
+			}
+			rejections = append(rejections, rejected(r, r.request, reason))
+			continue
+		}
+
+		bid := accepted(r, r.request)
+		processor.AddBid(bid)
+		high.raise(bid)
+		if closing.extend(r.received) {
+			deadline, _ := closing.Deadline()
+			o.logger.Printf("⏱️ Auction %s extended to %s by a late bid from %s",
+				auct.ID, deadline.Format("15:04:05.000"), bid.BidderID)
+		}
+	}
+
+	if len(faults) == 0 {
+		faults = nil
+	}
+	return faults, rejections
+}
@@ -3,12 +3,18 @@ package auction
 import (
 	"auction-simulator/internal/types"
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"sort"
 	"sync"
 	"time"
 
+	"auction-simulator/internal/arrival"
 	"auction-simulator/internal/bidder"
 	"auction-simulator/internal/config"
+	"auction-simulator/internal/fault"
+	"auction-simulator/internal/limiter"
 )
 
 // Orchestrator manages concurrent auction execution
@@ -16,22 +22,169 @@ type Orchestrator struct {
 	config         *config.Config
 	auctionManager *Manager
 	bidderManager  *bidder.Manager
-	semaphore      chan struct{}
+	limiter        *limiter.Limiter
+	bidders        []registeredBidder
+	simulators     []*bidder.Simulator
+	validator      *Validator
+	faults         *fault.Counts
+	notices        noticeLog
+	skip           map[string]bool
+	onResult       func(*types.AuctionResult)
+	stockOuts      int // stream arrivals that found no catalog item to sell
+	logger         *log.Logger
+}
+
+// registeredBidder is a bidder taking part in the run's auctions
+type registeredBidder struct {
+	id        string
+	bidder    types.Bidder
+	simulator *bidder.Simulator // nil for remote bidders
 }
 
 // NewOrchestrator creates a new auction orchestrator
-func NewOrchestrator(cfg *config.Config, auctionMgr *Manager, bidderMgr *bidder.Manager) *Orchestrator {
+func NewOrchestrator(cfg *config.Config, auctionMgr *Manager, bidderMgr *bidder.Manager) (*Orchestrator, error) {
+	lim, err := limiter.FromConfig(cfg.Limiter, cfg.ResourceLimits.MaxConcurrentBidders)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bidders may be wrapped in a fault injector for chaos testing
+	faults := fault.NewCounts()
+	simulators := bidderMgr.GetBidderSimulators()
+	bidders := make([]registeredBidder, len(simulators))
+	for i, b := range bidderMgr.GetBidders() {
+		bidders[i] = registeredBidder{id: b.ID, bidder: simulators[i], simulator: simulators[i]}
+		if cfg.Faults.Enabled() {
+			bidders[i].bidder = fault.Wrap(simulators[i], b.ID, cfg.Faults, cfg.Seed, faults)
+		}
+	}
+	for _, remote := range cfg.Remote {
+		for _, other := range bidders {
+			if other.id == remote.ID {
+				return nil, fmt.Errorf("remote bidder %s has the ID of another bidder", remote.ID)
+			}
+		}
+		var b types.Bidder = bidder.NewRemote(remote.ID, remote.Endpoint)
+		if cfg.Faults.Enabled() {
+			b = fault.Wrap(b, remote.ID, cfg.Faults, cfg.Seed, faults)
+		}
+		bidders = append(bidders, registeredBidder{id: remote.ID, bidder: b})
+	}
+
+	registered := make([]string, len(bidders))
+	for i, b := range bidders {
+		registered[i] = b.id
+	}
+
 	return &Orchestrator{
 		config:         cfg,
 		auctionManager: auctionMgr,
 		bidderManager:  bidderMgr,
-		semaphore:      make(chan struct{}, cfg.ResourceLimits.MaxConcurrentBidders),
+		limiter:        lim,
+		bidders:        bidders,
+		simulators:     simulators,
+		validator:      NewValidator(cfg.Validation, registered, bidderMgr.Ledger()),
+		faults:         faults,
+		logger:         log.Default(),
+	}, nil
+}
+
+// FaultsInjected returns the faults injected into bidders so far by kind
+func (o *Orchestrator) FaultsInjected() map[string]int {
+	return o.faults.Snapshot()
+}
+
+// LearnedShades returns the mean shade learning bidders have settled on so
+// far, by strategy
+func (o *Orchestrator) LearnedShades() map[string]float64 {
+	totals := make(map[string]float64)
+	counts := make(map[string]int)
+	for _, simulator := range o.simulators {
+		if strategy, shade, ok := simulator.Learned(); ok {
+			totals[strategy] += shade
+			counts[strategy]++
+		}
+	}
+	if len(totals) == 0 {
+		return nil
+	}
+	for strategy := range totals {
+		totals[strategy] /= float64(counts[strategy])
+	}
+	return totals
+}
+
+// Learners returns what each learning bidder has learned so far, by bidder
+// ID
+func (o *Orchestrator) Learners() map[string]bidder.LearnerState {
+	learners := make(map[string]bidder.LearnerState)
+	for _, b := range o.bidders {
+		if b.simulator == nil {
+			continue
+		}
+		if state, ok := b.simulator.LearnerState(); ok {
+			learners[b.id] = state
+		}
+	}
+	if len(learners) == 0 {
+		return nil
 	}
+	return learners
 }
 
-// RunAllAuctions executes all auctions concurrently
+// RestoreLearners lets learning bidders carry on from states saved by
+// Learners, for resumed runs
+func (o *Orchestrator) RestoreLearners(learners map[string]bidder.LearnerState) error {
+	for _, b := range o.bidders {
+		state, ok := learners[b.id]
+		if !ok || b.simulator == nil {
+			continue
+		}
+		if err := b.simulator.RestoreLearner(state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StockOuts returns how many stream arrivals found every catalog unit
+// already in auction
+func (o *Orchestrator) StockOuts() int {
+	return o.stockOuts
+}
+
+// Limiter returns the limiter shared by all auctions' bidder requests
+func (o *Orchestrator) Limiter() *limiter.Limiter {
+	return o.limiter
+}
+
+// Skip excludes auctions that already finished, in an earlier part of a
+// resumed run, from RunAllAuctions
+func (o *Orchestrator) Skip(auctionIDs map[string]bool) {
+	o.skip = auctionIDs
+}
+
+// SetLogger sends the orchestrator's progress messages to l instead of the
+// standard logger
+func (o *Orchestrator) SetLogger(l *log.Logger) {
+	o.logger = l
+}
+
+// OnResult registers fn to receive each auction result as soon as the
+// auction completes. fn may be called concurrently.
+func (o *Orchestrator) OnResult(fn func(*types.AuctionResult)) {
+	o.onResult = fn
+}
+
+// RunAllAuctions executes all auctions concurrently, except those passed to
+// Skip, and returns their results in auction order
 func (o *Orchestrator) RunAllAuctions(ctx context.Context) ([]*types.AuctionResult, error) {
-	auctions := o.auctionManager.GetAuctions()
+	var auctions []*Auction
+	for _, auct := range o.auctionManager.GetAuctions() {
+		if !o.skip[auct.ID] {
+			auctions = append(auctions, auct)
+		}
+	}
 	results := make([]*types.AuctionResult, len(auctions))
 
 	var wg sync.WaitGroup
@@ -42,7 +195,7 @@ func (o *Orchestrator) RunAllAuctions(ctx context.Context) ([]*types.AuctionResu
 	defer cancel()
 
 	startTime := time.Now()
-	log.Printf("🏁 Starting %d auctions concurrently", len(auctions))
+	o.logger.Printf("🏁 Starting %d auctions concurrently", len(auctions))
 
 	for i, auction := range auctions {
 		wg.Add(1)
@@ -50,6 +203,9 @@ func (o *Orchestrator) RunAllAuctions(ctx context.Context) ([]*types.AuctionResu
 		go func(idx int, auct *Auction) {
 			defer wg.Done()
 			result := o.runSingleAuction(batchCtx, auct, idx)
+			if o.onResult != nil {
+				o.onResult(result)
+			}
 
 			mu.Lock()
 			results[idx] = result
@@ -61,47 +217,252 @@ func (o *Orchestrator) RunAllAuctions(ctx context.Context) ([]*types.AuctionResu
 	}
 
 	wg.Wait()
-	log.Printf("✅ All auctions completed in %v", time.Since(startTime))
+	o.notices.wait()
+	o.logger.Printf("✅ All auctions completed in %v", time.Since(startTime))
 	return results, firstError
 }
 
+// RunStream starts auctions as the arrival process produces them until the
+// arrival window closes, then waits for the running auctions to finish. With
+// Arrival.MaxInFlight set, arrivals beyond the limit queue for a slot and the
+// wait is recorded as the auction's queueing delay.
+func (o *Orchestrator) RunStream(ctx context.Context, arrivals arrival.Process) ([]*types.AuctionResult, error) {
+	streamCfg := o.config.Arrival
+	if streamCfg.Duration <= 0 && !streamCfg.Recorded() {
+		return nil, fmt.Errorf("%s arrivals need a duration", streamCfg.Process)
+	}
+
+	var slots chan struct{}
+	if streamCfg.MaxInFlight > 0 {
+		slots = make(chan struct{}, streamCfg.MaxInFlight)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var results []*types.AuctionResult
+
+	start := time.Now()
+	deadline := start.Add(streamCfg.Duration)
+	next := start
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+
+	if streamCfg.Duration > 0 {
+		o.logger.Printf("🌊 Streaming %s arrivals for %v", streamCfg.Process, streamCfg.Duration)
+	} else {
+		o.logger.Printf("🌊 Streaming %s arrivals for the whole trace", streamCfg.Process)
+	}
+
+arrivalLoop:
+	for i := 0; ; i++ {
+		gap, ok := arrivals.Next()
+		if !ok {
+			break
+		}
+		next = next.Add(gap)
+		if streamCfg.Duration > 0 && !next.Before(deadline) {
+			break
+		}
+
+		timer.Reset(time.Until(next))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			break arrivalLoop
+		}
+
+		// Arrivals while every catalog unit is in auction find nothing to
+		// sell; the stream ends once all are sold
+		auct := o.auctionManager.NewAuction(i)
+		if auct == nil {
+			if o.auctionManager.SoldOut() {
+				o.logger.Printf("📦 Catalog sold out after %d arrivals", i)
+				break
+			}
+			o.stockOuts++
+			continue
+		}
+		arrived := time.Now()
+		wg.Add(1)
+
+		go func(idx int, auct *Auction) {
+			defer wg.Done()
+
+			var result *types.AuctionResult
+			if slots != nil {
+				select {
+				case slots <- struct{}{}:
+					defer func() { <-slots }()
+				case <-ctx.Done():
+					now := time.Now()
+					result = &types.AuctionResult{AuctionID: auct.ID, Aborted: true, StartTime: now, EndTime: now}
+				}
+			}
+			if result == nil {
+				result = o.runSingleAuction(ctx, auct, idx)
+			}
+			result.QueueDelay = result.StartTime.Sub(arrived)
+			if o.onResult != nil {
+				o.onResult(result)
+			}
+
+			mu.Lock()
+			results = append(results, result)
+			mu.Unlock()
+		}(i, auct)
+	}
+
+	wg.Wait()
+	o.notices.wait()
+
+	// Completion order depends on scheduling; report in arrival order
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].StartTime.Add(-results[i].QueueDelay).Before(results[j].StartTime.Add(-results[j].QueueDelay))
+	})
+
+	o.logger.Printf("✅ %d streamed auctions completed in %v", len(results), time.Since(start))
+	return results, nil
+}
+
 // runSingleAuction executes a single auction
 func (o *Orchestrator) runSingleAuction(ctx context.Context, auct *Auction, auctionIndex int) *types.AuctionResult {
 	processor := NewProcessor(auct)
 
-	auctionCtx, cancel := context.WithTimeout(ctx, auct.Timeout)
-	defer cancel()
-
-	log.Printf("🎯 Starting auction %s (timeout: %v)", auct.ID, auct.Timeout)
+	o.logger.Printf("🎯 Starting auction %s (timeout: %v)", auct.ID, auct.Timeout)
 
 	result := &types.AuctionResult{
-		AuctionID: auct.ID,
-		StartTime: time.Now(),
+		AuctionID:    auct.ID,
+		ItemID:       auct.ItemID,
+		Attributes:   auct.Attributes,
+		ReservePrice: auct.Reserve,
+		StartTime:    time.Now(),
 	}
 
-	// Collect bids
-	o.collectBids(auctionCtx, processor, auct)
-
-	// Determine winner
-	if len(auct.Bids) > 0 {
-		winner := processor.determineWinner()
+	// Collect bids; if the run is stopped the auction closes with the bids
+	// received so far. Open auctions may run past their timeout when a
+	// soft close extends them.
+	if auct.Format == config.FormatOpen {
+		closing := newCloser(ctx, auct.Timeout, o.config.Open)
+		result.Faults, result.Rejected = o.collectOpenBids(closing, processor, auct)
+		result.Extensions = closing.Extensions()
+		closing.cancel()
+	} else {
+		auctionCtx, cancel := context.WithTimeout(ctx, auct.Timeout)
+		result.Faults, result.Rejected = o.collectBids(auctionCtx, processor, auct)
+		cancel()
+	}
+	result.Aborted = ctx.Err() != nil
+
+	// Determine winner and charge the price to their budget; a winner that
+	// can no longer afford it drops out and the auction is decided again.
+	// An auction cut short by a stopped run is not decided, so nobody is
+	// charged or told.
+	if len(auct.Bids) > 0 && !result.Aborted {
+		winner, price, tie, err := o.settle(processor)
 		auct.Winner = winner
 		result.Winner = winner
+		result.ClearingPrice = price
+		result.Tie = tie
+		result.Error = err
 	}
 
+	result.Bids = auct.Bids
 	result.TotalBids = len(auct.Bids)
+	o.auctionManager.Settle(auct, result.Winner != nil && result.Error == nil)
+	if result.Aborted {
+		for _, b := range o.bidders {
+			forget(b, result.AuctionID)
+		}
+	} else {
+		o.sendNotices(processor, result)
+	}
 	result.EndTime = time.Now()
 	result.Duration = result.EndTime.Sub(result.StartTime)
 	auct.IsComplete = true
 
-	log.Printf("📊 Auction %s completed: %d bids", auct.ID, result.TotalBids)
+	o.logger.Printf("📊 Auction %s completed: %d bids", auct.ID, result.TotalBids)
 	return result
 }
 
-// collectBids collects bids from all bidders using simulators
-func (o *Orchestrator) collectBids(ctx context.Context, processor *Processor, auct *Auction) {
+// settle determines the winner and charges the clearing price to the
+// bidders' ledger. A split item is charged to every tied bidder in shares;
+// if any of them cannot pay, the others are refunded and the auction is
+// decided again without that bidder.
+func (o *Orchestrator) settle(processor *Processor) (*types.Bid, float64, *types.Tie, error) {
+	ledger := o.bidderManager.Ledger()
+	for {
+		winner, tied := processor.determineWinner()
+		if winner == nil {
+			return nil, 0, nil, nil
+		}
+
+		price := processor.clearingPrice(winner)
+		tie := processor.tie(tied, price)
+		shares := []types.Share{{BidderID: winner.BidderID, Fraction: 1, Price: price}}
+		if tie != nil && len(tie.Shares) > 0 {
+			shares = tie.Shares
+		}
+
+		unpaid, err := o.charge(ledger, shares)
+		if err != nil {
+			return winner, price, tie, err
+		}
+		if len(unpaid) == 0 {
+			return winner, price, tie, nil
+		}
+		for _, bidderID := range unpaid {
+			processor.exclude(bidderID)
+		}
+	}
+}
+
+// charge charges every share to the ledger. If some bidders cannot pay
+// their share, the others are refunded and the unpaid bidders returned.
+func (o *Orchestrator) charge(ledger bidder.Ledger, shares []types.Share) ([]string, error) {
+	var paid []types.Share
+	var unpaid []string
+	for _, share := range shares {
+		charged, err := ledger.Charge(share.BidderID, share.Price)
+		if err != nil {
+			o.refund(ledger, paid)
+			return nil, fmt.Errorf("could not charge %s: %w", share.BidderID, err)
+		}
+		if charged {
+			paid = append(paid, share)
+		} else {
+			unpaid = append(unpaid, share.BidderID)
+		}
+	}
+
+	if len(unpaid) > 0 {
+		o.refund(ledger, paid)
+	}
+	return unpaid, nil
+}
+
+// refund returns charged shares to the bidders' budgets
+func (o *Orchestrator) refund(ledger bidder.Ledger, shares []types.Share) {
+	for _, share := range shares {
+		if _, err := ledger.Charge(share.BidderID, -share.Price); err != nil {
+			o.logger.Printf("⚠️ Could not refund %s: %v", share.BidderID, err)
+		}
+	}
+}
+
+// collectBids collects bids from all bidders and validates them. It returns
+// the number of failed requests by fault kind and the rejected bids.
+func (o *Orchestrator) collectBids(ctx context.Context, processor *Processor, auct *Auction) (map[string]int, []types.RejectedBid) {
 	var wg sync.WaitGroup
-	bidCh := make(chan types.Bid, o.config.TotalBidders)
+	responseCh := make(chan *response, len(o.bidders))
+
+	var faultsMu sync.Mutex
+	faults := make(map[string]int)
+	recordFault := func(kind string) {
+		faultsMu.Lock()
+		faults[kind]++
+		faultsMu.Unlock()
+	}
 
 	attributeValues := make([]float64, len(auct.Attributes))
 	for i, attr := range auct.Attributes {
@@ -115,46 +476,116 @@ func (o *Orchestrator) collectBids(ctx context.Context, processor *Processor, au
 		Timestamp:  time.Now(),
 	}
 
-	// Get bidder simulators instead of direct bidders
-	simulators := o.bidderManager.GetBidderSimulators()
-
-	for _, simulator := range simulators {
+	for _, b := range o.bidders {
 		wg.Add(1)
 
-		go func(sim *bidder.Simulator) {
+		go func(b registeredBidder) {
 			defer wg.Done()
 
-			select {
-			case o.semaphore <- struct{}{}:
-				defer func() { <-o.semaphore }()
-			case <-ctx.Done():
+			// Slots are shared fairly between auctions
+			permit, err := o.limiter.Acquire(ctx, auct.ID)
+			if err != nil {
 				return
 			}
 
-			bidResponse, err := sim.EvaluateBid(ctx, bidRequest)
+			started := time.Now()
+			bidResponse, err := o.evaluate(ctx, b, bidRequest)
+			permit.Release(requestOutcome(ctx, err, time.Since(started), bidRequest.Timeout))
+			if kind := faultKind(ctx, err); kind != "" {
+				recordFault(kind)
+			}
 			if err != nil || bidResponse == nil {
 				return
 			}
 
-			bid := types.Bid{
-				BidderID:  bidResponse.BidderID,
-				Amount:    bidResponse.Amount,
-				Timestamp: bidResponse.Timestamp,
-			}
-
-			select {
-			case bidCh <- bid:
-			case <-ctx.Done():
-			}
-		}(simulator)
+			// Buffered for every bidder, so a late response is still kept
+			// and rejected
+			responseCh <- &response{BidResponse: bidResponse, requestedFrom: b.id, received: time.Now()}
+		}(b)
 	}
 
 	go func() {
 		wg.Wait()
-		close(bidCh)
+		close(responseCh)
 	}()
 
-	for bid := range bidCh {
-		processor.AddBid(bid)
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = bidRequest.Timestamp.Add(bidRequest.Timeout)
+	}
+
+	var rejections []types.RejectedBid
+	for r := range responseCh {
+		if reason := o.validator.Validate(r, bidRequest, deadline); reason != "" {
+			if malformed(reason) {
+				recordFault(types.FaultMalformed)
+			}
+			rejections = append(rejections, rejected(r, bidRequest, reason))
+			continue
+		}
+		rejections = append(rejections, processor.AddBid(accepted(r, bidRequest))...)
+	}
+
+	if len(faults) == 0 {
+		faults = nil
+	}
+	return faults, rejections
+}
+
+// BidderPanic is the error of a bid request whose bidder panicked
+type BidderPanic struct {
+	BidderID string
+	Value    any
+}
+
+// Error implements error
+func (p *BidderPanic) Error() string {
+	return fmt.Sprintf("bidder %s panicked: %v", p.BidderID, p.Value)
+}
+
+// evaluate requests a bid, turning a panic in the bidder into an error so
+// that one broken bidder cannot bring down the run
+func (o *Orchestrator) evaluate(ctx context.Context, b registeredBidder, request *types.BidRequest) (response *types.BidResponse, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			o.logger.Printf("⚠️ Recovered panic in bidder %s: %v", b.id, r)
+			response, err = nil, &BidderPanic{BidderID: b.id, Value: r}
+		}
+	}()
+	return b.bidder.EvaluateBid(ctx, request)
+}
+
+// faultKind classifies a failed bid request. Requests cut short because the
+// run was stopped are not the bidder's fault.
+func faultKind(ctx context.Context, err error) string {
+	var panicked *BidderPanic
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &panicked):
+		return types.FaultPanic
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return types.FaultTimeout
+	case ctx.Err() != nil:
+		return ""
+	default:
+		return types.FaultError
+	}
+}
+
+// requestOutcome classifies a bidder request for the concurrency limiter.
+// A bidder that used its whole timeout without answering is overloaded. A
+// request cut short because the auction closed while it was queued, or
+// cancelled from outside the auction, says nothing about load.
+func requestOutcome(ctx context.Context, err error, elapsed, timeout time.Duration) limiter.Outcome {
+	switch {
+	case err == nil:
+		return limiter.Success
+	case errors.Is(ctx.Err(), context.DeadlineExceeded) && elapsed >= timeout:
+		return limiter.Dropped
+	case ctx.Err() != nil:
+		return limiter.Ignored
+	default:
+		return limiter.Dropped
 	}
 }
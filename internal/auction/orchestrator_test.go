@@ -0,0 +1,228 @@
+package auction
+
+import (
+	"context"
+	"io"
+	"log"
+	"math"
+	"testing"
+	"time"
+
+	"auction-simulator/internal/bidder"
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/fault"
+	"auction-simulator/internal/types"
+)
+
+const (
+	testAuctions = 4
+	testBidders  = 6
+)
+
+// testConfig returns a small run in which every bidder bids in every
+// auction well within the timeout
+func testConfig(faults config.FaultConfig) *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.Seed = 5
+	cfg.TotalAuctions = testAuctions
+	cfg.TotalBidders = testBidders
+	cfg.AuctionTimeout = 300 * time.Millisecond
+	cfg.Bidders.MinBidChance, cfg.Bidders.MaxBidChance = 1, 1
+	cfg.Bidders.MinSpeedMS, cfg.Bidders.MaxSpeedMS = 1, 5
+	cfg.Faults = faults
+	return cfg
+}
+
+// runAuctions runs every auction of cfg and returns the results with the
+// orchestrator that ran them
+func runAuctions(t *testing.T, cfg *config.Config) ([]*types.AuctionResult, *Orchestrator) {
+	t.Helper()
+	auctions := NewManager(cfg)
+	auctions.SetLogger(log.New(io.Discard, "", 0))
+	if err := auctions.InitializeAuctions(); err != nil {
+		t.Fatalf("initialize auctions: %v", err)
+	}
+	bidders := bidder.NewManager(cfg)
+	bidders.SetLogger(log.New(io.Discard, "", 0))
+	if err := bidders.InitializeBidders(); err != nil {
+		t.Fatalf("initialize bidders: %v", err)
+	}
+	o, err := NewOrchestrator(cfg, auctions, bidders)
+	if err != nil {
+		t.Fatalf("new orchestrator: %v", err)
+	}
+	o.SetLogger(log.New(io.Discard, "", 0))
+
+	results, err := o.RunAllAuctions(context.Background())
+	if err != nil {
+		t.Fatalf("run auctions: %v", err)
+	}
+	return results, o
+}
+
+func TestFaultsExcludeEveryBid(t *testing.T) {
+	requests := testAuctions * testBidders
+	tests := []struct {
+		name     string
+		faults   config.FaultConfig
+		injected string // kind tallied by the injector
+		failed   string // kind recorded on the auction result
+	}{
+		{"latency", config.FaultConfig{LatencyRate: 1, LatencySpike: time.Second}, fault.Latency, types.FaultTimeout},
+		{"error", config.FaultConfig{ErrorRate: 1}, fault.Error, types.FaultError},
+		{"malformed", config.FaultConfig{MalformedRate: 1}, fault.Malformed, types.FaultMalformed},
+		{"panic", config.FaultConfig{PanicRate: 1}, fault.Panic, types.FaultPanic},
+		{"outage", config.FaultConfig{OutageEvery: time.Hour, OutageLength: time.Hour}, fault.Outage, types.FaultError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, o := runAuctions(t, testConfig(tt.faults))
+
+			injected := o.FaultsInjected()
+			if injected[tt.injected] != requests {
+				t.Errorf("injected %d %s faults, want %d", injected[tt.injected], tt.injected, requests)
+			}
+			for kind, n := range injected {
+				if kind != tt.injected && n > 0 {
+					t.Errorf("injected %d unexpected %s faults", n, kind)
+				}
+			}
+
+			for _, result := range results {
+				if result.Winner != nil {
+					t.Errorf("%s won by %s, want no winner", result.AuctionID, result.Winner.BidderID)
+				}
+				if len(result.Bids) != 0 {
+					t.Errorf("%s kept %d bids, want none", result.AuctionID, len(result.Bids))
+				}
+				if result.Faults[tt.failed] != testBidders {
+					t.Errorf("%s recorded %d %s faults, want %d", result.AuctionID, result.Faults[tt.failed], tt.failed, testBidders)
+				}
+			}
+			if tt.injected == fault.Malformed {
+				for _, result := range results {
+					if len(result.Rejected) != testBidders {
+						t.Errorf("%s rejected %d bids, want %d", result.AuctionID, len(result.Rejected), testBidders)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestFaultsLeaveOtherBidsUntouched(t *testing.T) {
+	baseline, _ := runAuctions(t, testConfig(config.FaultConfig{}))
+
+	tests := []struct {
+		name     string
+		faults   config.FaultConfig
+		injected string
+		dropped  bool // faulted bidders lose their bid
+	}{
+		{"latency within timeout", config.FaultConfig{LatencyRate: 0.5, LatencySpike: 20 * time.Millisecond}, fault.Latency, false},
+		{"error", config.FaultConfig{ErrorRate: 0.5}, fault.Error, true},
+		{"malformed", config.FaultConfig{MalformedRate: 0.5}, fault.Malformed, true},
+		{"panic", config.FaultConfig{PanicRate: 0.5}, fault.Panic, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, o := runAuctions(t, testConfig(tt.faults))
+			injected := o.FaultsInjected()[tt.injected]
+			if injected == 0 || injected == testAuctions*testBidders {
+				t.Fatalf("injected %d %s faults, want some but not all", injected, tt.injected)
+			}
+
+			bids := 0
+			for i, result := range results {
+				want := make(map[string]float64)
+				for _, bid := range baseline[i].Bids {
+					want[bid.BidderID] = bid.Amount
+				}
+
+				var highest *types.Bid
+				for j := range result.Bids {
+					bid := &result.Bids[j]
+					if amount, ok := want[bid.BidderID]; !ok || amount != bid.Amount {
+						t.Errorf("%s: bid %.2f of %s is not in the fault-free run", result.AuctionID, bid.Amount, bid.BidderID)
+					}
+					if highest == nil || bid.Amount > highest.Amount {
+						highest = bid
+					}
+				}
+				bids += len(result.Bids)
+
+				switch {
+				case highest == nil && result.Winner != nil:
+					t.Errorf("%s won by %s without bids", result.AuctionID, result.Winner.BidderID)
+				case highest != nil && (result.Winner == nil || result.Winner.Amount != highest.Amount):
+					t.Errorf("%s not won by its highest remaining bid %.2f", result.AuctionID, highest.Amount)
+				}
+			}
+
+			want := testAuctions * testBidders
+			if tt.dropped {
+				want -= injected
+			}
+			if bids != want {
+				t.Errorf("kept %d bids after %d %s faults, want %d", bids, injected, tt.injected, want)
+			}
+		})
+	}
+}
+
+func TestRemoteBidderIDMustBeUnique(t *testing.T) {
+	cfg := testConfig(config.FaultConfig{})
+	cfg.Remote = []config.RemoteBidder{{ID: "bidder-3", Endpoint: "http://127.0.0.1:1"}}
+
+	bidders := bidder.NewManager(cfg)
+	bidders.SetLogger(log.New(io.Discard, "", 0))
+	if err := bidders.InitializeBidders(); err != nil {
+		t.Fatalf("initialize bidders: %v", err)
+	}
+	if _, err := NewOrchestrator(cfg, NewManager(cfg), bidders); err == nil {
+		t.Error("remote bidder-3 was registered alongside simulated bidder-3")
+	}
+}
+
+func TestFaultsDoNotChangeNoticeCounts(t *testing.T) {
+	_, baseline := runAuctions(t, testConfig(config.FaultConfig{}))
+	// Every bidder is wrapped, but no spike is long enough to matter
+	_, wrapped := runAuctions(t, testConfig(config.FaultConfig{LatencyRate: 1, LatencySpike: time.Microsecond}))
+
+	want, wantFailed := baseline.Notices()
+	got, failed := wrapped.Notices()
+	if failed != wantFailed || len(got) != len(want) {
+		t.Fatalf("notices %v (%d failed) with faults, want %v (%d failed)", got, failed, want, wantFailed)
+	}
+	for kind, n := range want {
+		if got[kind] != n {
+			t.Errorf("%d %s notices with faults, want %d", got[kind], kind, n)
+		}
+	}
+}
+
+func TestSimulatedBiddersAreBilled(t *testing.T) {
+	results, o := runAuctions(t, testConfig(config.FaultConfig{}))
+
+	want := make(map[string]float64)
+	wins := 0
+	for _, result := range results {
+		if result.Winner != nil && result.Error == nil {
+			want[result.Winner.BidderID] += result.ClearingPrice
+			wins++
+		}
+	}
+	if wins == 0 {
+		t.Fatal("no auction was won")
+	}
+	if sent, _ := o.Notices(); sent[types.NoticeBilling] != wins {
+		t.Errorf("%d billing notices for %d wins", sent[types.NoticeBilling], wins)
+	}
+	for _, b := range o.bidders {
+		if got := b.simulator.Billed(); math.Abs(got-want[b.id]) > 1e-9 {
+			t.Errorf("%s billed %.2f, want %.2f", b.id, got, want[b.id])
+		}
+	}
+}
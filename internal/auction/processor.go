@@ -1,14 +1,21 @@
 package auction
 
 import (
+	"auction-simulator/internal/config"
 	"auction-simulator/internal/types"
+	"auction-simulator/pkg/utils"
 	"context"
+	"math"
+	"sort"
+	"strings"
 	"time"
 )
 
 // Processor handles individual auction execution
 type Processor struct {
-	auction *Auction
+	auction  *Auction
+	excluded map[string]bool // bidders whose budget cannot cover their bid
+	banned   map[string]bool // bidders whose bids are all rejected as duplicates
 }
 
 // NewProcessor creates a new auction processor
@@ -48,32 +55,193 @@ func (p *Processor) Run(ctx context.Context) *types.AuctionResult {
 		result.TotalBids = len(p.auction.Bids)
 
 		if len(p.auction.Bids) > 0 {
-			winner := p.determineWinner()
+			winner, tied := p.determineWinner()
 			p.auction.Winner = winner
 			result.Winner = winner
+			result.ClearingPrice = p.clearingPrice(winner)
+			result.Tie = p.tie(tied, result.ClearingPrice)
 		}
 	}
 
 	return result
 }
 
-// determineWinner selects the highest bidder
-func (p *Processor) determineWinner() *types.Bid {
-	if len(p.auction.Bids) == 0 {
+// determineWinner selects the highest bidder at or above the reserve price.
+// Ties are broken by the auction's policy; the tied bids are returned in
+// the order it ranked them, or nil if no other bid matched the winner's.
+func (p *Processor) determineWinner() (*types.Bid, []*types.Bid) {
+	var top []*types.Bid
+	for i := range p.auction.Bids {
+		bid := &p.auction.Bids[i]
+		if bid.Amount < p.auction.Reserve || p.excluded[bid.BidderID] {
+			continue
+		}
+		switch {
+		case len(top) == 0 || bid.Amount > top[0].Amount:
+			top = append(top[:0], bid)
+		case bid.Amount == top[0].Amount:
+			top = append(top, bid)
+		}
+	}
+
+	switch len(top) {
+	case 0:
+		return nil, nil
+	case 1:
+		return top[0], nil
+	}
+	p.rankTied(top)
+	return top[0], top
+}
+
+// rankTied orders bids that tied for the win by the auction's tie-break
+// policy, best first. Split allocations rank like earliest, and the first
+// bid stands for the shared win.
+func (p *Processor) rankTied(tied []*types.Bid) {
+	// Start from bidder ID order so that the ranking never depends on the
+	// order bids happened to arrive in
+	sort.Slice(tied, func(i, j int) bool {
+		return lowerBidderID(tied[i].BidderID, tied[j].BidderID)
+	})
+
+	switch p.auction.TieBreak {
+	case config.TieLowestID:
+	case config.TieRandom:
+		rng := utils.NewRNG(p.auction.Seed)
+		for i := len(tied) - 1; i > 0; i-- {
+			j := rng.Intn(i + 1)
+			tied[i], tied[j] = tied[j], tied[i]
+		}
+	default:
+		sort.SliceStable(tied, func(i, j int) bool {
+			return tied[i].Timestamp.Before(tied[j].Timestamp)
+		})
+	}
+}
+
+// lowerBidderID orders bidder IDs with their numeric suffixes compared as
+// numbers, so bidder-2 comes before bidder-10
+func lowerBidderID(a, b string) bool {
+	prefixA, numberA := splitNumber(a)
+	prefixB, numberB := splitNumber(b)
+	if prefixA != prefixB || numberA == "" || numberB == "" || numberA == numberB {
+		return a < b
+	}
+	if len(numberA) != len(numberB) {
+		return len(numberA) < len(numberB)
+	}
+	return numberA < numberB
+}
+
+// splitNumber splits an ID into its prefix and trailing digits, with
+// leading zeros of the digits dropped
+func splitNumber(id string) (string, string) {
+	i := len(id)
+	for i > 0 && id[i-1] >= '0' && id[i-1] <= '9' {
+		i--
+	}
+	number := strings.TrimLeft(id[i:], "0")
+	if number == "" && i < len(id) {
+		number = "0"
+	}
+	return id[:i], number
+}
+
+// tie records the tied bids of a win at price, or returns nil if there were
+// none. Under split allocation each tied bidder gets an equal share.
+func (p *Processor) tie(tied []*types.Bid, price float64) *types.Tie {
+	if len(tied) == 0 {
 		return nil
 	}
 
-	winner := &p.auction.Bids[0]
-	for i := 1; i < len(p.auction.Bids); i++ {
-		if p.auction.Bids[i].Amount > winner.Amount {
-			winner = &p.auction.Bids[i]
+	policy := p.auction.TieBreak
+	if policy == "" {
+		policy = config.TieEarliest
+	}
+	tie := &types.Tie{Policy: policy, Amount: tied[0].Amount}
+	for _, bid := range tied {
+		tie.Bidders = append(tie.Bidders, bid.BidderID)
+		if policy == config.TieSplit {
+			tie.Shares = append(tie.Shares, types.Share{
+				BidderID: bid.BidderID,
+				Fraction: 1 / float64(len(tied)),
+				Price:    price / float64(len(tied)),
+			})
+		}
+	}
+	return tie
+}
+
+// clearingPrice returns what the winner pays under the auction's mechanism
+func (p *Processor) clearingPrice(winner *types.Bid) float64 {
+	if winner == nil {
+		return 0
+	}
+	if p.auction.Mechanism != config.SecondPrice {
+		return winner.Amount
+	}
+
+	price := p.auction.Reserve
+	for i := range p.auction.Bids {
+		bid := &p.auction.Bids[i]
+		if bid.BidderID != winner.BidderID && !p.excluded[bid.BidderID] && bid.Amount > price {
+			price = bid.Amount
 		}
 	}
+	return math.Min(price, winner.Amount)
+}
 
-	return winner
+// exclude removes a bidder from winner and price determination
+func (p *Processor) exclude(bidderID string) {
+	if p.excluded == nil {
+		p.excluded = make(map[string]bool)
+	}
+	p.excluded[bidderID] = true
 }
 
-// AddBid safely adds a bid to the auction
-func (p *Processor) AddBid(bid types.Bid) {
-	p.auction.Bids = append(p.auction.Bids, bid)
+// AddBid adds a bid to the auction. If the bidder has bid before, the
+// auction's duplicate policy decides which bid stands; the bids it rejects
+// are returned. Open auctions keep every raise.
+func (p *Processor) AddBid(bid types.Bid) []types.RejectedBid {
+	if p.auction.Format == config.FormatOpen {
+		p.auction.Bids = append(p.auction.Bids, bid)
+		return nil
+	}
+	if p.banned[bid.BidderID] {
+		return []types.RejectedBid{rejectedDuplicate(bid, p.auction.ID)}
+	}
+
+	existing := -1
+	for i := range p.auction.Bids {
+		if p.auction.Bids[i].BidderID == bid.BidderID {
+			existing = i
+			break
+		}
+	}
+	if existing < 0 {
+		p.auction.Bids = append(p.auction.Bids, bid)
+		return nil
+	}
+
+	previous := p.auction.Bids[existing]
+	switch p.auction.Duplicates {
+	case config.DuplicateLast:
+		p.auction.Bids[existing] = bid
+		return []types.RejectedBid{rejectedDuplicate(previous, p.auction.ID)}
+	case config.DuplicateHighest:
+		if bid.Amount > previous.Amount {
+			p.auction.Bids[existing] = bid
+			return []types.RejectedBid{rejectedDuplicate(previous, p.auction.ID)}
+		}
+		return []types.RejectedBid{rejectedDuplicate(bid, p.auction.ID)}
+	case config.DuplicateReject:
+		p.auction.Bids = append(p.auction.Bids[:existing], p.auction.Bids[existing+1:]...)
+		if p.banned == nil {
+			p.banned = make(map[string]bool)
+		}
+		p.banned[bid.BidderID] = true
+		return []types.RejectedBid{rejectedDuplicate(previous, p.auction.ID), rejectedDuplicate(bid, p.auction.ID)}
+	default:
+		return []types.RejectedBid{rejectedDuplicate(bid, p.auction.ID)}
+	}
 }
@@ -0,0 +1,49 @@
+package auction
+
+import (
+	"testing"
+	"time"
+
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/types"
+)
+
+func TestLowestIDTieBreakComparesNumbers(t *testing.T) {
+	now := time.Now()
+	auct := &Auction{
+		ID:       "auction-1",
+		TieBreak: config.TieLowestID,
+		Bids: []types.Bid{
+			{BidderID: "bidder-10", Amount: 120, Timestamp: now},
+			{BidderID: "bidder-2", Amount: 120, Timestamp: now.Add(time.Millisecond)},
+			{BidderID: "bidder-1", Amount: 90, Timestamp: now},
+		},
+	}
+
+	winner, tied := NewProcessor(auct).determineWinner()
+	if winner == nil || winner.BidderID != "bidder-2" {
+		t.Fatalf("winner %v, want bidder-2", winner)
+	}
+	if len(tied) != 2 || tied[1].BidderID != "bidder-10" {
+		t.Errorf("tied bids ranked %v, want bidder-2 then bidder-10", tied)
+	}
+}
+
+func TestLowerBidderID(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"bidder-2", "bidder-10", true},
+		{"bidder-10", "bidder-2", false},
+		{"bidder-9", "bidder-9", false},
+		{"bidder-02", "bidder-3", true},
+		{"bidder-7", "remote-1", true},
+		{"dsp", "bidder-1", false},
+	}
+	for _, tt := range tests {
+		if got := lowerBidderID(tt.a, tt.b); got != tt.want {
+			t.Errorf("lowerBidderID(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -8,10 +8,17 @@ import (
 // Auction represents a single auction instance
 type Auction struct {
 	ID         string            `json:"id"`
+	ItemID     string            `json:"item_id,omitempty"` // catalog item auctioned, if any
 	Attributes []types.Attribute `json:"attributes"`
 	StartTime  time.Time         `json:"start_time"`
 	EndTime    time.Time         `json:"end_time"`
 	Timeout    time.Duration     `json:"timeout"`
+	Format     string            `json:"format"`
+	Mechanism  string            `json:"mechanism"`
+	TieBreak   string            `json:"tie_break"`
+	Seed       int64             `json:"seed"` // seeds random tie-breaks
+	Reserve    float64           `json:"reserve"`
+	Duplicates string            `json:"duplicates"` // policy for repeated bids from one bidder
 	Winner     *types.Bid        `json:"winner,omitempty"`
 	Bids       []types.Bid       `json:"bids"`
 	IsComplete bool              `json:"is_complete"`
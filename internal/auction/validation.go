@@ -0,0 +1,188 @@
+package auction
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"auction-simulator/internal/bidder"
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/types"
+)
+
+// Validator checks bid responses before they enter an auction
+type Validator struct {
+	rules      config.ValidationConfig
+	registered map[string]bool
+	ledger     bidder.Ledger
+}
+
+// response is a bid response as it was received
+type response struct {
+	*types.BidResponse
+	requestedFrom string            // the bidder the request was sent to
+	request       *types.BidRequest // the round answered, in open auctions
+	received      time.Time
+}
+
+// rule is one stage of the validation pipeline; it returns a rejection
+// reason, or "" to pass the bid on
+type rule func(v *Validator, r *response, request *types.BidRequest, deadline time.Time) string
+
+// pipeline lists the rules in the order they are checked. Cheap checks of
+// the response itself come first and the budget, which may be remote, last.
+var pipeline = []rule{
+	checkAuction,
+	checkAmount,
+	checkBidder,
+	checkDeadline,
+	checkBounds,
+	checkIncrement,
+	checkBudget,
+}
+
+// NewValidator creates a validator for bids from the registered bidders,
+// checking budgets against ledger
+func NewValidator(rules config.ValidationConfig, registered []string, ledger bidder.Ledger) *Validator {
+	v := &Validator{
+		rules:      rules,
+		registered: make(map[string]bool, len(registered)),
+		ledger:     ledger,
+	}
+	for _, id := range registered {
+		v.registered[id] = true
+	}
+	return v
+}
+
+// Validate returns why r is rejected for the auction of request, which
+// closes at deadline, or "" if it is a valid bid
+func (v *Validator) Validate(r *response, request *types.BidRequest, deadline time.Time) string {
+	for _, check := range pipeline {
+		if reason := check(v, r, request, deadline); reason != "" {
+			return reason
+		}
+	}
+	return ""
+}
+
+// checkAuction rejects bids for another auction, e.g. from a bidder that
+// reuses request objects
+func checkAuction(v *Validator, r *response, request *types.BidRequest, deadline time.Time) string {
+	if r.AuctionID != request.AuctionID {
+		return types.RejectAuctionMismatch
+	}
+	return ""
+}
+
+// checkAmount rejects amounts that are not positive and finite
+func checkAmount(v *Validator, r *response, request *types.BidRequest, deadline time.Time) string {
+	if !(r.Amount > 0) || math.IsInf(r.Amount, 0) {
+		return types.RejectInvalidAmount
+	}
+	return ""
+}
+
+// checkBidder rejects bids in the name of an unknown bidder or of a
+// registered bidder other than the one asked
+func checkBidder(v *Validator, r *response, request *types.BidRequest, deadline time.Time) string {
+	switch {
+	case !v.registered[r.BidderID]:
+		return types.RejectUnregistered
+	case r.BidderID != r.requestedFrom:
+		return types.RejectBidderMismatch
+	}
+	return ""
+}
+
+// checkDeadline rejects bids made or received after the auction closed
+func checkDeadline(v *Validator, r *response, request *types.BidRequest, deadline time.Time) string {
+	if r.Timestamp.After(deadline) || r.received.After(deadline) {
+		return types.RejectLate
+	}
+	return ""
+}
+
+// checkBounds rejects amounts outside the configured minimum and maximum
+func checkBounds(v *Validator, r *response, request *types.BidRequest, deadline time.Time) string {
+	switch {
+	case r.Amount < v.rules.MinBid:
+		return types.RejectBelowMin
+	case v.rules.MaxBid > 0 && r.Amount > v.rules.MaxBid:
+		return types.RejectAboveMax
+	}
+	return ""
+}
+
+// checkIncrement rejects amounts that are not whole multiples of the
+// increment, allowing for floating-point error
+func checkIncrement(v *Validator, r *response, request *types.BidRequest, deadline time.Time) string {
+	if v.rules.Increment <= 0 {
+		return ""
+	}
+	steps := r.Amount / v.rules.Increment
+	if math.Abs(steps-math.Round(steps)) > 1e-6 {
+		return types.RejectOffIncrement
+	}
+	return ""
+}
+
+// checkBudget rejects amounts the bidder has not got left to spend
+func checkBudget(v *Validator, r *response, request *types.BidRequest, deadline time.Time) string {
+	remaining, err := v.ledger.Remaining(r.BidderID)
+	if err == nil && r.Amount > remaining {
+		return types.RejectOverBudget
+	}
+	return ""
+}
+
+// malformed reports whether a rejection means the response was unusable,
+// as opposed to a well-formed bid that broke the auction's rules
+func malformed(reason string) bool {
+	switch reason {
+	case types.RejectAuctionMismatch, types.RejectInvalidAmount, types.RejectUnregistered, types.RejectBidderMismatch:
+		return true
+	}
+	return false
+}
+
+// accepted converts a valid response to a bid
+func accepted(r *response, request *types.BidRequest) types.Bid {
+	return types.Bid{
+		BidderID:  r.BidderID,
+		Amount:    r.Amount,
+		Timestamp: r.Timestamp,
+		Latency:   r.Timestamp.Sub(request.Timestamp),
+	}
+}
+
+// rejected records a response that failed validation
+func rejected(r *response, request *types.BidRequest, reason string) types.RejectedBid {
+	bid := types.RejectedBid{
+		BidderID:  r.BidderID,
+		AuctionID: r.AuctionID,
+		Amount:    r.Amount,
+		Timestamp: r.Timestamp,
+		Latency:   r.Timestamp.Sub(request.Timestamp),
+		Reason:    reason,
+	}
+	// JSON has no NaN or infinities
+	if math.IsNaN(r.Amount) || math.IsInf(r.Amount, 0) {
+		bid.Amount = 0
+		bid.RawAmount = strconv.FormatFloat(r.Amount, 'g', -1, 64)
+	}
+	return bid
+}
+
+// rejectedDuplicate records an accepted bid that lost out to the duplicate
+// policy
+func rejectedDuplicate(bid types.Bid, auctionID string) types.RejectedBid {
+	return types.RejectedBid{
+		BidderID:  bid.BidderID,
+		AuctionID: auctionID,
+		Amount:    bid.Amount,
+		Timestamp: bid.Timestamp,
+		Latency:   bid.Latency,
+		Reason:    types.RejectDuplicate,
+	}
+}
@@ -0,0 +1,171 @@
+package bidder
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/types"
+	"auction-simulator/pkg/utils"
+)
+
+// coverShade is the share of its value a cartel member standing aside bids
+const coverShade = 0.6
+
+// shillTargets is how far up the range of base bids shills bid, close
+// enough to the top to raise prices. Shills are further held below the top
+// honest bid, see honestTop.
+var shillTargets = map[string]float64{
+	config.StrategyAggressive: 0.85,
+	config.StrategyCautious:   0.7,
+}
+
+// shillRange is the spread of shill bids, narrower than honest bidders'
+const shillRange = 4.0
+
+// shillWinShare is the share of auctions in which the top honest bid may
+// fall below what shills bid; shillDraws auctions are drawn to estimate it
+const (
+	shillWinShare = 0.05
+	shillDraws    = 1000
+)
+
+// membership is a simulated bidder's place in a collusion group
+type membership struct {
+	group *types.CollusionGroup
+	seed  int64 // shared by the members, so they agree who wins each auction
+}
+
+// designated returns the member the group lets win an auction: a rotation
+// cartel draws one per auction, a suppression ring always backs its leader
+func (m *membership) designated(auctionID string) string {
+	if m.group.Kind == config.GroupRotation {
+		rng := utils.NewRNG(utils.DeriveSeed(m.seed, auctionID))
+		return m.group.Members[rng.Intn(len(m.group.Members))]
+	}
+	return m.group.Members[0]
+}
+
+// shill reports whether the group is a shill group
+func (m *membership) shill() bool {
+	return m.group.Kind == config.GroupShill
+}
+
+// includes reports whether a bidder belongs to the group
+func (m *membership) includes(bidderID string) bool {
+	return slices.Contains(m.group.Members, bidderID)
+}
+
+// adjust applies the group's agreement to the amount a member would bid on
+// its own, and reports whether it bids at all. Shills bid as they are,
+// since their values are already the prices they push towards.
+func (m *membership) adjust(bidderID, auctionID string, amount float64) (float64, bool) {
+	if m.group.Kind == config.GroupShill || m.designated(auctionID) == bidderID {
+		return amount, true
+	}
+	if m.group.Strategy == config.StrategyAbstain {
+		return 0, false
+	}
+	return amount * coverShade, true
+}
+
+// groupID names the i-th configured group
+func groupID(i int, group config.GroupConfig) string {
+	return fmt.Sprintf("%s-%d", group.Kind, i+1)
+}
+
+// assignGroups draws the members of the configured groups from bidders
+// that do not yet belong to one. Members drop any behavior or learning
+// strategy so that they act only as their group agreed.
+func (m *Manager) assignGroups() error {
+	rng := utils.NewRNG(utils.DeriveSeed(m.config.Seed, "groups"))
+	free := rng.Perm(len(m.bidders))
+
+	m.groups = nil
+	var shills []*Bidder
+	for i, cfg := range m.config.Bidders.Groups {
+		if cfg.Size < 1 || cfg.Size > len(free) {
+			return fmt.Errorf("group %s needs %d bidders, %d left", groupID(i, cfg), cfg.Size, len(free))
+		}
+
+		group := &types.CollusionGroup{ID: groupID(i, cfg), Kind: cfg.Kind, Strategy: cfg.Strategy}
+		for _, idx := range free[:cfg.Size] {
+			b := m.bidders[idx]
+			b.Group = group.ID
+			b.Behavior = ""
+			b.Strategy = ""
+			if cfg.Kind == config.GroupShill {
+				bids := m.config.Bidders
+				b.BidChance = 1
+				b.BaseBid = bids.MinBaseBid + (bids.MaxBaseBid-bids.MinBaseBid)*shillTargets[cfg.Strategy]
+				b.BidRange = shillRange
+				shills = append(shills, b)
+			}
+			group.Members = append(group.Members, b.ID)
+		}
+		free = free[cfg.Size:]
+		m.groups = append(m.groups, group)
+	}
+
+	// Shills push prices up without winning, so their highest bids stay
+	// below the top honest bid of nearly every auction
+	if len(shills) > 0 {
+		top := m.honestTop()
+		for _, b := range shills {
+			b.BaseBid = math.Max(1, math.Min(b.BaseBid, top-shillRange/2))
+		}
+	}
+	return nil
+}
+
+// honestTop returns the amount the highest bid of bidders outside any group
+// exceeds in all but shillWinShare of auctions, estimated by drawing
+// auctions from its own stream
+func (m *Manager) honestTop() float64 {
+	rng := utils.NewRNG(utils.DeriveSeed(m.config.Seed, "shills"))
+	tops := make([]float64, shillDraws)
+	for i := range tops {
+		for _, b := range m.bidders {
+			if b.Group != "" || rng.Float64() > b.BidChance {
+				continue
+			}
+			tops[i] = math.Max(tops[i], b.BaseBid+(rng.Float64()-0.5)*b.BidRange)
+		}
+	}
+	slices.Sort(tops)
+	return tops[int(shillWinShare*shillDraws)]
+}
+
+// restoreGroups rebuilds the groups of restored bidders
+func (m *Manager) restoreGroups() {
+	m.groups = nil
+	for i, cfg := range m.config.Bidders.Groups {
+		group := &types.CollusionGroup{ID: groupID(i, cfg), Kind: cfg.Kind, Strategy: cfg.Strategy}
+		for _, b := range m.bidders {
+			if b.Group == group.ID {
+				group.Members = append(group.Members, b.ID)
+			}
+		}
+		m.groups = append(m.groups, group)
+	}
+}
+
+// Groups returns the collusion groups and their members
+func (m *Manager) Groups() []types.CollusionGroup {
+	groups := make([]types.CollusionGroup, len(m.groups))
+	for i, group := range m.groups {
+		groups[i] = *group
+	}
+	return groups
+}
+
+// membership returns the group membership of a bidder, or nil
+func (m *Manager) membership(b *Bidder) *membership {
+	for _, group := range m.groups {
+		if group.ID == b.Group {
+			return &membership{group: group, seed: utils.DeriveSeed(m.config.Seed, "group", group.ID)}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package bidder
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/types"
+	"auction-simulator/pkg/utils"
+)
+
+// ShadeLevels are the fractions of its value a learning bidder may bid
+var ShadeLevels = []float64{0.5, 0.6, 0.7, 0.8, 0.9, 1.0}
+
+// learner treats the shade levels as the arms of a bandit. Each auction
+// pulls one arm, and the outcome pays the bidder's surplus as a share of
+// its value, so rewards lie between 0 and 1.
+type learner struct {
+	mu       sync.Mutex
+	strategy string
+	epsilon  float64
+	pulls    []int
+	rewards  []float64       // total reward per arm
+	pending  map[string]pull // auctions bid in, awaiting their outcome
+}
+
+// LearnerState is what a learning bidder has learned so far, so a resumed
+// run can carry on from it
+type LearnerState struct {
+	Pulls   []int     `json:"pulls"`   // auctions bid in per shade level
+	Rewards []float64 `json:"rewards"` // total reward per shade level
+}
+
+// pull is the arm chosen for an auction and the value the bid was based on
+type pull struct {
+	arm   int
+	value float64
+}
+
+// newLearner creates a learner using strategy, one of the config.Learn
+// constants
+func newLearner(strategy string, epsilon float64) *learner {
+	return &learner{
+		strategy: strategy,
+		epsilon:  epsilon,
+		pulls:    make([]int, len(ShadeLevels)),
+		rewards:  make([]float64, len(ShadeLevels)),
+		pending:  make(map[string]pull),
+	}
+}
+
+// shade returns the arm to pull in an auction. Once the bidder has bid in
+// an auction the arm is kept, so later rounds of an open auction use it.
+func (l *learner) shade(auctionID string, rng *utils.RNG) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if p, ok := l.pending[auctionID]; ok {
+		return p.arm
+	}
+	return l.choose(rng)
+}
+
+// bid records that the bidder bid in an auction with arm, so the outcome is
+// credited to it. Only the first bid of an auction counts.
+func (l *learner) bid(auctionID string, arm int, value float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.pending[auctionID]; !ok {
+		l.pending[auctionID] = pull{arm: arm, value: value}
+	}
+}
+
+// forget drops an auction whose outcome will never be told, like one whose
+// bid was lost or arrived too late
+func (l *learner) forget(auctionID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.pending, auctionID)
+}
+
+// state returns a copy of what the learner has learned
+func (l *learner) state() LearnerState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return LearnerState{Pulls: slices.Clone(l.pulls), Rewards: slices.Clone(l.rewards)}
+}
+
+// restore replaces what the learner has learned with a saved state
+func (l *learner) restore(state LearnerState) error {
+	if len(state.Pulls) != len(ShadeLevels) || len(state.Rewards) != len(ShadeLevels) {
+		return fmt.Errorf("learner state has %d arms, want %d", len(state.Pulls), len(ShadeLevels))
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pulls = slices.Clone(state.Pulls)
+	l.rewards = slices.Clone(state.Rewards)
+	return nil
+}
+
+// choose picks an arm by the learner's strategy
+func (l *learner) choose(rng *utils.RNG) int {
+	switch l.strategy {
+	case config.LearnUCB:
+		return l.upperConfidence()
+	case config.LearnThompson:
+		return l.sample(rng)
+	default:
+		if rng.RandomChance(l.epsilon) {
+			return rng.Intn(len(ShadeLevels))
+		}
+		return l.best()
+	}
+}
+
+// best returns the arm with the highest mean reward; arms never pulled
+// count as perfect so each is tried at least once
+func (l *learner) best() int {
+	best, bestMean := 0, -1.0
+	for arm := range ShadeLevels {
+		mean := 1.0
+		if l.pulls[arm] > 0 {
+			mean = l.rewards[arm] / float64(l.pulls[arm])
+		}
+		if mean > bestMean {
+			best, bestMean = arm, mean
+		}
+	}
+	return best
+}
+
+// upperConfidence returns the arm with the highest UCB1 index
+func (l *learner) upperConfidence() int {
+	total := 0
+	for arm := range ShadeLevels {
+		if l.pulls[arm] == 0 {
+			return arm
+		}
+		total += l.pulls[arm]
+	}
+
+	best, bestIndex := 0, math.Inf(-1)
+	for arm := range ShadeLevels {
+		n := float64(l.pulls[arm])
+		index := l.rewards[arm]/n + math.Sqrt(2*math.Log(float64(total))/n)
+		if index > bestIndex {
+			best, bestIndex = arm, index
+		}
+	}
+	return best
+}
+
+// sample returns the arm with the highest draw from its Beta posterior,
+// counting fractional rewards as partial successes
+func (l *learner) sample(rng *utils.RNG) int {
+	best, bestDraw := 0, -1.0
+	for arm := range ShadeLevels {
+		successes := l.rewards[arm]
+		failures := float64(l.pulls[arm]) - successes
+		if draw := betaSample(rng, 1+successes, 1+failures); draw > bestDraw {
+			best, bestDraw = arm, draw
+		}
+	}
+	return best
+}
+
+// learn credits the outcome of an auction, a win or loss notice, to the arm
+// pulled for it
+func (l *learner) learn(notice *types.Notice) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	p, ok := l.pending[notice.AuctionID]
+	if !ok {
+		return
+	}
+	delete(l.pending, notice.AuctionID)
+
+	reward := 0.0
+	if notice.Kind == types.NoticeWin && p.value > 0 {
+		reward = math.Max(0, math.Min(1, (p.value-notice.Price)/p.value))
+	}
+	l.pulls[p.arm]++
+	l.rewards[p.arm] += reward
+}
+
+// preferred returns the shade level with the highest mean reward so far, or
+// NaN if the learner has never been rewarded
+func (l *learner) preferred() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	best, bestMean := -1, 0.0
+	for arm := range ShadeLevels {
+		if l.pulls[arm] == 0 {
+			continue
+		}
+		if mean := l.rewards[arm] / float64(l.pulls[arm]); mean > bestMean {
+			best, bestMean = arm, mean
+		}
+	}
+	if best < 0 {
+		return math.NaN()
+	}
+	return ShadeLevels[best]
+}
+
+// betaSample draws from a Beta(a, b) distribution
+func betaSample(rng *utils.RNG, a, b float64) float64 {
+	x := gammaSample(rng, a)
+	y := gammaSample(rng, b)
+	return x / (x + y)
+}
+
+// gammaSample draws from a Gamma(shape, 1) distribution with the method of
+// Marsaglia and Tsang; shape must be at least 1
+func gammaSample(rng *utils.RNG, shape float64) float64 {
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := rng.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := rng.Float64()
+		if math.Log(u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package bidder
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/types"
+)
+
+func TestLearnerWaitsOnlyForAuctionsBidIn(t *testing.T) {
+	b := &Bidder{ID: "bidder-1", BidChance: 0.5, BaseBid: 100, BidRange: 40, Strategy: config.LearnEpsilonGreedy}
+	s := NewSimulator(b, 3, NewBudgetLedger(1e6))
+
+	bids := 0
+	for i := 1; i <= 40; i++ {
+		request := &types.BidRequest{AuctionID: fmt.Sprintf("auction-%d", i), Timeout: time.Second}
+		response, err := s.EvaluateBid(context.Background(), request)
+		if err != nil {
+			t.Fatalf("%s: %v", request.AuctionID, err)
+		}
+		if response != nil {
+			bids++
+		}
+	}
+	if bids == 0 || bids == 40 {
+		t.Fatalf("bid in %d of 40 auctions, want some but not all", bids)
+	}
+	if len(s.learner.pending) != bids {
+		t.Errorf("learner waits on %d auctions after bidding in %d", len(s.learner.pending), bids)
+	}
+
+	// Outcomes are credited once; auctions without one are forgotten
+	for i := 1; i <= 40; i++ {
+		auctionID := fmt.Sprintf("auction-%d", i)
+		if i%2 == 0 {
+			s.Forget(auctionID)
+			continue
+		}
+		s.NotifyLoss(context.Background(), &types.Notice{AuctionID: auctionID, Kind: types.NoticeLoss})
+		s.NotifyLoss(context.Background(), &types.Notice{AuctionID: auctionID, Kind: types.NoticeLoss})
+	}
+	if len(s.learner.pending) != 0 {
+		t.Errorf("learner still waits on %d auctions", len(s.learner.pending))
+	}
+
+	state, ok := s.LearnerState()
+	if !ok {
+		t.Fatal("learning bidder has no state")
+	}
+	pulls := 0
+	for _, n := range state.Pulls {
+		pulls += n
+	}
+	if pulls == 0 || pulls >= bids {
+		t.Errorf("credited %d pulls for %d bids of which about half had an outcome", pulls, bids)
+	}
+
+	restored := NewSimulator(b, 3, NewBudgetLedger(1e6))
+	if err := restored.RestoreLearner(state); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if got, _ := restored.LearnerState(); fmt.Sprint(got) != fmt.Sprint(state) {
+		t.Errorf("restored state %v, want %v", got, state)
+	}
+}
@@ -0,0 +1,79 @@
+package bidder
+
+import (
+	"math"
+	"sync"
+)
+
+// Ledger tracks what bidders spend against their budgets. One ledger is
+// shared by every auction of a run, including auctions run by other workers
+// of a distributed run, so a budget is never spent twice.
+type Ledger interface {
+	// Remaining returns what a bidder has left to spend
+	Remaining(bidderID string) (float64, error)
+	// Charge deducts amount from a bidder's budget, or reports false and
+	// deducts nothing if the budget does not cover it. A negative amount
+	// refunds an earlier charge.
+	Charge(bidderID string, amount float64) (bool, error)
+}
+
+// BudgetLedger is an in-process Ledger giving every bidder the same budget
+type BudgetLedger struct {
+	mu     sync.Mutex
+	budget float64 // per bidder; 0 is unlimited
+	spent  map[string]float64
+}
+
+// NewBudgetLedger creates a ledger with budget per bidder; 0 is unlimited
+func NewBudgetLedger(budget float64) *BudgetLedger {
+	return &BudgetLedger{
+		budget: budget,
+		spent:  make(map[string]float64),
+	}
+}
+
+// Remaining implements Ledger
+func (l *BudgetLedger) Remaining(bidderID string) (float64, error) {
+	if l.budget <= 0 {
+		return math.Inf(1), nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return math.Max(0, l.budget-l.spent[bidderID]), nil
+}
+
+// Charge implements Ledger
+func (l *BudgetLedger) Charge(bidderID string, amount float64) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.budget > 0 && l.spent[bidderID]+amount > l.budget+1e-9 {
+		return false, nil
+	}
+	l.spent[bidderID] += amount
+	return true, nil
+}
+
+// Spent returns a copy of what each bidder has spent
+func (l *BudgetLedger) Spent() map[string]float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	spent := make(map[string]float64, len(l.spent))
+	for id, amount := range l.spent {
+		spent[id] = amount
+	}
+	return spent
+}
+
+// Restore sets what each bidder has spent, for resumed runs
+func (l *BudgetLedger) Restore(spent map[string]float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.spent = make(map[string]float64, len(spent))
+	for id, amount := range spent {
+		l.spent[id] = amount
+	}
+}
@@ -2,16 +2,23 @@ package bidder
 
 import (
 	"auction-simulator/internal/config"
+	"auction-simulator/internal/population"
+	"auction-simulator/internal/types"
 	"auction-simulator/pkg/utils"
 	"fmt"
 	"log"
-	"math/rand"
+	"math"
 )
 
 // Manager handles all bidders
 type Manager struct {
 	config  *config.Config
 	bidders []*Bidder
+	rng     *utils.RNG
+	ledger  Ledger
+	groups  []*types.CollusionGroup
+	sampler *population.Sampler // nil draws parameters from the uniform ranges
+	logger  *log.Logger
 }
 
 // NewManager creates a new bidder manager
@@ -19,36 +26,132 @@ func NewManager(cfg *config.Config) *Manager {
 	return &Manager{
 		config:  cfg,
 		bidders: make([]*Bidder, 0, cfg.TotalBidders),
+		rng:     utils.NewRNG(utils.DeriveSeed(cfg.Seed, "bidders")),
+		ledger:  NewBudgetLedger(cfg.Bidders.Budget),
+		logger:  log.Default(),
 	}
 }
 
 // InitializeBidders creates all bidder instances
 func (m *Manager) InitializeBidders() error {
-	log.Printf("Initializing %d bidders...", m.config.TotalBidders)
-	bidderConfig := DefaultBidderConfig()
+	m.logger.Printf("Initializing %d bidders...", m.config.TotalBidders)
+	bidderConfig := &m.config.Bidders
+	if bidderConfig.Population != nil {
+		sampler, err := population.NewSampler(bidderConfig.Population)
+		if err != nil {
+			return fmt.Errorf("invalid bidder population: %w", err)
+		}
+		m.sampler = sampler
+	}
 
 	for i := 0; i < m.config.TotalBidders; i++ {
 		bidder := m.createBidder(i, bidderConfig)
+		m.drawFromPopulation(i, bidder)
 		m.bidders = append(m.bidders, bidder)
 	}
+	if err := m.assignGroups(); err != nil {
+		return err
+	}
 
-	log.Printf("✅ Successfully initialized %d bidders", len(m.bidders))
+	m.logger.Printf("✅ Successfully initialized %d bidders", len(m.bidders))
 	return nil
 }
 
 // createBidder generates a single bidder
-func (m *Manager) createBidder(id int, config *BidderConfig) *Bidder {
+func (m *Manager) createBidder(id int, config *config.BidderConfig) *Bidder {
 	return &Bidder{
 		ID:         fmt.Sprintf("bidder-%d", id+1),
 		Name:       fmt.Sprintf("Bidder %d", id+1),
-		BidChance:  utils.RandomFloat(config.MinBidChance, config.MaxBidChance),
-		BaseBid:    utils.RandomFloat(config.MinBaseBid, config.MaxBaseBid),
-		BidRange:   utils.RandomFloat(5.0, 20.0),
-		SpeedMS:    utils.RandomInt(config.MinSpeedMS, config.MaxSpeedMS),
+		BidChance:  m.rng.RandomFloat(config.MinBidChance, config.MaxBidChance),
+		BaseBid:    m.rng.RandomFloat(config.MinBaseBid, config.MaxBaseBid),
+		BidRange:   m.rng.RandomFloat(5.0, 20.0),
+		SpeedMS:    m.rng.RandomInt(config.MinSpeedMS, config.MaxSpeedMS),
 		Attributes: m.generatePreferredAttributes(),
+		Behavior:   m.behavior(id, config),
+		Strategy:   m.strategy(id, config),
 	}
 }
 
+// drawFromPopulation replaces the parameters the population has
+// distributions for, from a stream of its own like behavior, so that the
+// others keep the values of their uniform ranges
+func (m *Manager) drawFromPopulation(id int, b *Bidder) {
+	if m.sampler == nil {
+		return
+	}
+	rng := utils.NewRNG(utils.DeriveSeed(m.config.Seed, "population", fmt.Sprint(id)))
+	values := m.sampler.Draw(rng)
+	if v, ok := values[config.ParamBidChance]; ok {
+		b.BidChance = math.Max(0, math.Min(1, v))
+	}
+	if v, ok := values[config.ParamBaseBid]; ok {
+		b.BaseBid = math.Max(0, v)
+	}
+	if v, ok := values[config.ParamBidRange]; ok {
+		b.BidRange = math.Max(0, v)
+	}
+	if v, ok := values[config.ParamSpeed]; ok {
+		b.SpeedMS = int(math.Round(math.Max(0, v)))
+	}
+}
+
+// behavior picks a bidder's behavior from a stream of its own, so that
+// enabling behaviors leaves the other bidder properties unchanged
+func (m *Manager) behavior(id int, config *config.BidderConfig) string {
+	if config.SniperShare <= 0 {
+		return ""
+	}
+	rng := utils.NewRNG(utils.DeriveSeed(m.config.Seed, "behavior", fmt.Sprint(id)))
+	if rng.RandomChance(config.SniperShare) {
+		return BehaviorSniper
+	}
+	return ""
+}
+
+// strategy picks whether a bidder learns to shade its bids, from a stream
+// of its own like behavior
+func (m *Manager) strategy(id int, config *config.BidderConfig) string {
+	if config.LearnerShare <= 0 {
+		return ""
+	}
+	rng := utils.NewRNG(utils.DeriveSeed(m.config.Seed, "strategy", fmt.Sprint(id)))
+	if rng.RandomChance(config.LearnerShare) {
+		return config.Learning
+	}
+	return ""
+}
+
+// Restore replaces the bidders with previously saved ones and moves the
+// generator to where it was when they were saved, for resumed runs
+func (m *Manager) Restore(bidders []*Bidder, rngPosition uint64) {
+	m.bidders = bidders
+	m.rng.Seek(rngPosition)
+	m.restoreGroups()
+	m.logger.Printf("✅ Restored %d bidders", len(m.bidders))
+}
+
+// RNGPosition returns how far the bidder generator has advanced
+func (m *Manager) RNGPosition() uint64 {
+	return m.rng.Position()
+}
+
+// Ledger returns the ledger bidders' spending is charged to
+func (m *Manager) Ledger() Ledger {
+	return m.ledger
+}
+
+// SetLedger charges spending to ledger instead of the manager's own, so
+// bidders can share budgets with other processes
+func (m *Manager) SetLedger(ledger Ledger) {
+	m.ledger = ledger
+}
+
+// SetLogger sends the manager's progress messages to l instead of the
+// standard logger
+func (m *Manager) SetLogger(l *log.Logger) {
+	m.logger = l
+}
+
 // GetBidders returns all bidders
 func (m *Manager) GetBidders() []*Bidder {
 	return m.bidders
@@ -69,17 +172,23 @@ func (m *Manager) GetBidderSimulators() []*Simulator {
 	bidders := m.GetBidders()
 	simulators := make([]*Simulator, len(bidders))
 	for i, bidder := range bidders {
-		simulators[i] = NewSimulator(bidder)
+		simulators[i] = NewSimulator(bidder, utils.DeriveSeed(m.config.Seed, bidder.ID), m.ledger)
+		simulators[i].group = m.membership(bidder)
 	}
 	return simulators
 }
 
-// generatePreferredAttributes creates random attribute preferences
+// generatePreferredAttributes creates random preferences among the IDs of
+// the attributes in the schema
 func (m *Manager) generatePreferredAttributes() []int {
-	numPreferences := rand.Intn(6) + 3 // 3-8 preferred attributes
+	attributes := len(m.config.AttributeSchema())
+	numPreferences := m.rng.Intn(6) + 3 // 3-8 preferred attributes
+	if attributes == 0 {
+		return nil
+	}
 	preferences := make([]int, numPreferences)
 	for i := 0; i < numPreferences; i++ {
-		preferences[i] = rand.Intn(20) // 0-19 attribute IDs
+		preferences[i] = m.rng.Intn(attributes)
 	}
 	return preferences
 }
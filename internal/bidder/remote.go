@@ -0,0 +1,168 @@
+package bidder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"auction-simulator/internal/types"
+)
+
+// lossCodes maps loss reasons to the loss reason codes of OpenRTB, sent in
+// the ${AUCTION_LOSS} macro
+var lossCodes = map[string]int{
+	types.LossHigherBid:         102,
+	types.LossTieBreak:          102,
+	types.LossBelowReserve:      100,
+	types.LossBudget:            1,
+	types.LossNoSale:            1,
+	types.RejectLate:            2,
+	types.RejectBelowMin:        100,
+	types.RejectAuctionMismatch: 5,
+	types.RejectInvalidAmount:   9,
+}
+
+// Remote is a bidder served over HTTP. Bid requests are POSTed to its
+// endpoint as JSON and it answers with a types.BidResponse, or with 204 No
+// Content for no bid. The nurl, lurl and burl of its response are fetched
+// for the win, loss and billing notices of that auction.
+type Remote struct {
+	id       string
+	endpoint string
+	client   *http.Client
+
+	mu   sync.Mutex
+	urls map[string]*types.BidResponse // responses with notice URLs, by auction
+}
+
+// NewRemote creates the adapter for the bidder id served at endpoint
+func NewRemote(id, endpoint string) *Remote {
+	return &Remote{
+		id:       id,
+		endpoint: endpoint,
+		client:   &http.Client{},
+		urls:     make(map[string]*types.BidResponse),
+	}
+}
+
+// EvaluateBid implements types.Bidder
+func (r *Remote) EvaluateBid(ctx context.Context, request *types.BidRequest) (*types.BidResponse, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+
+	httpResponse, err := r.client.Do(httpRequest)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", r.id, err)
+	}
+	defer httpResponse.Body.Close()
+
+	switch httpResponse.StatusCode {
+	case http.StatusNoContent:
+		return nil, nil
+	case http.StatusOK:
+	default:
+		return nil, fmt.Errorf("%s: bid request failed: %s", r.id, httpResponse.Status)
+	}
+
+	var response types.BidResponse
+	if err := json.NewDecoder(httpResponse.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("%s: could not decode bid response: %w", r.id, err)
+	}
+	if response.NURL != "" || response.LURL != "" || response.BURL != "" {
+		r.mu.Lock()
+		r.urls[request.AuctionID] = &response
+		r.mu.Unlock()
+	}
+	return &response, nil
+}
+
+// NotifyWin implements types.WinListener by fetching the nurl
+func (r *Remote) NotifyWin(ctx context.Context, notice *types.Notice) error {
+	return r.fire(ctx, notice, func(response *types.BidResponse) string { return response.NURL }, false)
+}
+
+// NotifyLoss implements types.LossListener by fetching the lurl
+func (r *Remote) NotifyLoss(ctx context.Context, notice *types.Notice) error {
+	return r.fire(ctx, notice, func(response *types.BidResponse) string { return response.LURL }, true)
+}
+
+// NotifyBilling implements types.BillingListener by fetching the burl
+func (r *Remote) NotifyBilling(ctx context.Context, notice *types.Notice) error {
+	return r.fire(ctx, notice, func(response *types.BidResponse) string { return response.BURL }, true)
+}
+
+// Forget implements types.Forgetter by dropping the notice URLs of an
+// auction the bidder will hear nothing more about
+func (r *Remote) Forget(auctionID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.urls, auctionID)
+}
+
+// fire fetches the notice URL chosen by pick from the bidder's response in
+// the notice's auction. last marks the final notice of the auction, after
+// which the response is forgotten.
+func (r *Remote) fire(ctx context.Context, notice *types.Notice, pick func(*types.BidResponse) string, last bool) error {
+	r.mu.Lock()
+	response, ok := r.urls[notice.AuctionID]
+	if ok && last {
+		delete(r.urls, notice.AuctionID)
+	}
+	r.mu.Unlock()
+	if !ok || pick(response) == "" {
+		return nil
+	}
+
+	target := expandMacros(pick(response), notice)
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	httpResponse, err := r.client.Do(httpRequest)
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+	io.Copy(io.Discard, httpResponse.Body)
+
+	if httpResponse.StatusCode >= 300 {
+		return fmt.Errorf("%s notice failed: %s", notice.Kind, httpResponse.Status)
+	}
+	return nil
+}
+
+// expandMacros fills in the OpenRTB substitution macros of a notice URL
+func expandMacros(template string, notice *types.Notice) string {
+	price := notice.Price
+	if notice.Kind == types.NoticeLoss {
+		price = notice.ClearingPrice
+	}
+	loss := 0
+	if notice.Kind == types.NoticeLoss {
+		loss = 3 // invalid bid response, for reasons without a code of their own
+		if code, ok := lossCodes[notice.Reason]; ok {
+			loss = code
+		}
+	}
+
+	return strings.NewReplacer(
+		"${AUCTION_ID}", url.QueryEscape(notice.AuctionID),
+		"${AUCTION_SEAT_ID}", url.QueryEscape(notice.BidderID),
+		"${AUCTION_PRICE}", strconv.FormatFloat(price, 'f', 2, 64),
+		"${AUCTION_LOSS}", strconv.Itoa(loss),
+	).Replace(template)
+}
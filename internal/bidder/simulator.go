@@ -2,50 +2,199 @@ package bidder
 
 import (
 	"auction-simulator/internal/types"
+	"auction-simulator/pkg/utils"
 	"context"
+	"fmt"
 	"math"
-	"math/rand"
+	"sync"
 	"time"
 )
 
+// explorationRate is how often epsilon-greedy learners try a random shade
+const explorationRate = 0.1
+
 // Simulator handles bidder behavior simulation
 type Simulator struct {
-	bidder *Bidder
+	bidder  *Bidder
+	seed    int64
+	ledger  Ledger
+	learner *learner    // nil unless the bidder has a learning strategy
+	group   *membership // nil unless the bidder colludes
+
+	mu     sync.Mutex
+	billed float64 // total of the billing notices received
 }
 
-// NewSimulator creates a new bidder simulator
-func NewSimulator(bidder *Bidder) *Simulator {
-	return &Simulator{
+// NewSimulator creates a new bidder simulator. Each auction draws from its
+// own stream derived from seed, so results do not depend on scheduling order.
+// Bids are capped at what the bidder has left to spend in ledger.
+func NewSimulator(bidder *Bidder, seed int64, ledger Ledger) *Simulator {
+	s := &Simulator{
 		bidder: bidder,
+		seed:   seed,
+		ledger: ledger,
+	}
+	if bidder.Strategy != "" {
+		s.learner = newLearner(bidder.Strategy, explorationRate)
+	}
+	return s
+}
+
+// NotifyWin implements types.WinListener; learning bidders learn from it
+func (s *Simulator) NotifyWin(ctx context.Context, notice *types.Notice) error {
+	if s.learner != nil {
+		s.learner.learn(notice)
 	}
+	return nil
 }
 
+// NotifyLoss implements types.LossListener like NotifyWin
+func (s *Simulator) NotifyLoss(ctx context.Context, notice *types.Notice) error {
+	if s.learner != nil {
+		s.learner.learn(notice)
+	}
+	return nil
+}
+
+// NotifyBilling implements types.BillingListener. The ledger was charged
+// when the auction settled, so the bidder only keeps a tally of its bills.
+func (s *Simulator) NotifyBilling(ctx context.Context, notice *types.Notice) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.billed += notice.Price
+	return nil
+}
+
+// Billed returns the total the bidder has been billed
+func (s *Simulator) Billed() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.billed
+}
+
+// Forget implements types.Forgetter; a learning bidder stops waiting for
+// the outcome of the auction
+func (s *Simulator) Forget(auctionID string) {
+	if s.learner != nil {
+		s.learner.forget(auctionID)
+	}
+}
+
+// LearnerState returns what a learning bidder has learned; ok is false for
+// other bidders
+func (s *Simulator) LearnerState() (state LearnerState, ok bool) {
+	if s.learner == nil {
+		return LearnerState{}, false
+	}
+	return s.learner.state(), true
+}
+
+// RestoreLearner carries on learning from a state saved by LearnerState
+func (s *Simulator) RestoreLearner(state LearnerState) error {
+	if s.learner == nil {
+		return fmt.Errorf("bidder %s does not learn", s.bidder.ID)
+	}
+	return s.learner.restore(state)
+}
+
+// Learned returns a learning bidder's strategy and the shade that has paid
+// best so far; ok is false for other bidders and learners without results
+func (s *Simulator) Learned() (strategy string, shade float64, ok bool) {
+	if s.learner == nil {
+		return "", 0, false
+	}
+	shade = s.learner.preferred()
+	return s.bidder.Strategy, shade, !math.IsNaN(shade)
+}
+
+// snipeMargin is how long before the deadline a sniper's bid should land
+const snipeMargin = 25 * time.Millisecond
+
 // EvaluateBid implements the types.Bidder interface
 func (s *Simulator) EvaluateBid(ctx context.Context, request *types.BidRequest) (*types.BidResponse, error) {
 	// Check if context is already cancelled
 	if ctx.Err() != nil {
 		return nil, ctx.Err()
 	}
+	speed := time.Duration(s.bidder.SpeedMS) * time.Millisecond
+
+	// Snipers pass every round of an open auction but the last one they can
+	// still answer in time, and then wait for the last moment
+	if request.Open() && s.bidder.Behavior == BehaviorSniper {
+		snipeAt := request.Deadline.Add(-snipeMargin - speed)
+		if !request.NextRound.IsZero() && request.NextRound.Before(snipeAt) {
+			return nil, nil
+		}
+		if err := wait(ctx, time.Until(snipeAt)); err != nil {
+			return nil, err
+		}
+	}
 
 	// Simulate response time with context awareness
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case <-time.After(time.Duration(s.bidder.SpeedMS) * time.Millisecond):
-		// Continue after delay
+	if err := wait(ctx, speed); err != nil {
+		return nil, err
 	}
 
+	rng := utils.NewRNG(utils.DeriveSeed(s.seed, request.AuctionID))
+
 	// Simple bid decision: use bid chance directly
-	if rand.Float64() > s.bidder.BidChance {
-		return nil, nil // No bid
+	if rng.Float64() > s.bidder.BidChance {
+		return s.withdraw(request), nil // No bid
 	}
 
 	// Calculate bid amount with some randomness
 	baseAmount := s.bidder.BaseBid
-	variation := (rand.Float64() - 0.5) * s.bidder.BidRange
+	variation := (rng.Float64() - 0.5) * s.bidder.BidRange
 	bidAmount := math.Max(1.0, baseAmount+variation)
 	bidAmount = math.Round(bidAmount*100) / 100 // Round to 2 decimal places
 
+	// Learning bidders bid a share of that value
+	value, arm := bidAmount, 0
+	if s.learner != nil {
+		arm = s.learner.shade(request.AuctionID, rng)
+		bidAmount = math.Max(1.0, math.Round(bidAmount*ShadeLevels[arm]*100)/100)
+	}
+
+	// Shills only push up a price another bidder is paying, and never in
+	// the last round, where nobody could raise them again
+	if s.group != nil && s.group.shill() && request.Open() &&
+		(request.Leader == "" || s.group.includes(request.Leader) || request.NextRound.IsZero()) {
+		return nil, nil
+	}
+
+	// Colluding bidders bid as their group agreed
+	if s.group != nil {
+		amount, ok := s.group.adjust(s.bidder.ID, request.AuctionID, bidAmount)
+		if !ok {
+			return s.withdraw(request), nil
+		}
+		bidAmount = math.Max(1.0, math.Round(amount*100)/100)
+	}
+
+	// A bidder cannot bid more than it has left to spend
+	remaining, err := s.ledger.Remaining(s.bidder.ID)
+	if err != nil {
+		return nil, err
+	}
+	if remaining < 1.0 {
+		return s.withdraw(request), nil // Budget exhausted
+	}
+	bidAmount = math.Min(bidAmount, math.Floor(remaining*100)/100)
+
+	// In an open auction the amount above is what the item is worth to the
+	// bidder: it raises the standing bid until the price passes that
+	if request.Open() {
+		if request.Leader == s.bidder.ID {
+			return nil, nil
+		}
+		if bidAmount < request.MinBid {
+			return s.withdraw(request), nil
+		}
+		if s.bidder.Behavior != BehaviorSniper {
+			bidAmount = math.Ceil(request.MinBid*100-1e-6) / 100
+		}
+	}
+
 	response := &types.BidResponse{
 		BidderID:  s.bidder.ID,
 		AuctionID: request.AuctionID,
@@ -53,5 +202,35 @@ func (s *Simulator) EvaluateBid(ctx context.Context, request *types.BidRequest)
 		Timestamp: time.Now(),
 	}
 
+	// Only an auction actually bid in teaches the learner anything
+	if s.learner != nil {
+		s.learner.bid(request.AuctionID, arm, value)
+	}
 	return response, nil
 }
+
+// withdraw leaves an open auction for good; sealed auctions just get no bid
+func (s *Simulator) withdraw(request *types.BidRequest) *types.BidResponse {
+	if !request.Open() {
+		return nil
+	}
+	return &types.BidResponse{
+		BidderID:  s.bidder.ID,
+		AuctionID: request.AuctionID,
+		Timestamp: time.Now(),
+		Withdrawn: true,
+	}
+}
+
+// wait sleeps for d unless ctx is done first
+func wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
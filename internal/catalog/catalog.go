@@ -0,0 +1,415 @@
+// Package catalog loads the items a simulation auctions and tracks how much
+// of each is left as the run sells them
+package catalog
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/types"
+	"auction-simulator/pkg/utils"
+)
+
+// Item is an entry of the catalog
+type Item struct {
+	ID         string            `json:"id"`
+	Attributes map[string]string `json:"attributes"`
+	Reserve    float64           `json:"reserve"`  // 0 uses the reserve policy
+	Quantity   int               `json:"quantity"` // units for sale; 0 is unlimited
+}
+
+// item is how a JSON catalog lists an item, with attribute values of any
+// JSON type
+type item struct {
+	ID         string         `json:"id"`
+	Attributes map[string]any `json:"attributes"`
+	Reserve    float64        `json:"reserve"`
+	Quantity   int            `json:"quantity"`
+}
+
+// Columns of a CSV catalog that are not attributes
+const (
+	columnID       = "id"
+	columnReserve  = "reserve"
+	columnQuantity = "quantity"
+)
+
+// Load reads a catalog from a CSV file with a header row, or a JSON array
+// of items, by the file's extension. CSV columns other than id, reserve and
+// quantity are attributes.
+func Load(path string) ([]Item, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open catalog: %w", err)
+	}
+	defer file.Close()
+
+	var items []Item
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		items, err = readCSV(file)
+	case ".json":
+		items, err = readJSON(file)
+	default:
+		return nil, fmt.Errorf("catalog %s: want a .csv or .json file", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read catalog %s: %w", path, err)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("catalog %s has no items", path)
+	}
+
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		switch {
+		case item.ID == "":
+			return nil, fmt.Errorf("catalog %s: item without an id", path)
+		case seen[item.ID]:
+			return nil, fmt.Errorf("catalog %s: item %s is listed twice", path, item.ID)
+		case item.Quantity < 0 || item.Reserve < 0:
+			return nil, fmt.Errorf("catalog %s: item %s has a negative quantity or reserve", path, item.ID)
+		}
+		seen[item.ID] = true
+	}
+	return items, nil
+}
+
+// readCSV reads items from CSV rows under a header
+func readCSV(r io.Reader) ([]Item, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	var items []Item
+	for line, record := range records[1:] {
+		item := Item{Attributes: make(map[string]string)}
+		for i, value := range record {
+			value = strings.TrimSpace(value)
+			switch column := strings.TrimSpace(header[i]); strings.ToLower(column) {
+			case columnID:
+				item.ID = value
+			case columnReserve:
+				if value != "" {
+					if item.Reserve, err = strconv.ParseFloat(value, 64); err != nil {
+						return nil, fmt.Errorf("line %d: invalid reserve %q", line+2, value)
+					}
+				}
+			case columnQuantity:
+				if value != "" {
+					if item.Quantity, err = strconv.Atoi(value); err != nil {
+						return nil, fmt.Errorf("line %d: invalid quantity %q", line+2, value)
+					}
+				}
+			default:
+				item.Attributes[column] = value
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// readJSON reads items from a JSON array
+func readJSON(r io.Reader) ([]Item, error) {
+	var raw []item
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, len(raw))
+	for i, entry := range raw {
+		items[i] = Item{ID: entry.ID, Reserve: entry.Reserve, Quantity: entry.Quantity,
+			Attributes: make(map[string]string, len(entry.Attributes))}
+		for name, value := range entry.Attributes {
+			switch v := value.(type) {
+			case string:
+				items[i].Attributes[name] = v
+			case float64:
+				items[i].Attributes[name] = strconv.FormatFloat(v, 'f', -1, 64)
+			case bool:
+				items[i].Attributes[name] = strconv.FormatBool(v)
+			default:
+				return nil, fmt.Errorf("item %s: attribute %s must be a string, number or boolean", entry.ID, name)
+			}
+		}
+	}
+	return items, nil
+}
+
+// Schema returns the attribute schema of a catalog. A given schema is kept
+// as it is; otherwise one is inferred from the items, in name order:
+// attributes whose values all parse as numbers are numeric, true and false
+// make a boolean, and anything else is categorical with its values in
+// order of appearance.
+func Schema(items []Item, given []config.AttributeSpec) []config.AttributeSpec {
+	if len(given) > 0 {
+		return given
+	}
+
+	values := make(map[string][]string)
+	for _, item := range items {
+		for name, value := range item.Attributes {
+			values[name] = append(values[name], value)
+		}
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	schema := make([]config.AttributeSpec, 0, len(names))
+	for _, name := range names {
+		schema = append(schema, inferSpec(name, values[name]))
+	}
+	return schema
+}
+
+// inferSpec infers an attribute's spec from the values items give it
+func inferSpec(name string, values []string) config.AttributeSpec {
+	numeric, boolean := true, true
+	low, high := 0.0, 0.0
+	var categories []string
+	seen := make(map[string]bool)
+	for i, value := range values {
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			if i == 0 || v < low {
+				low = v
+			}
+			if i == 0 || v > high {
+				high = v
+			}
+		} else {
+			numeric = false
+		}
+		if _, err := strconv.ParseBool(value); err != nil || value == "1" || value == "0" {
+			boolean = false
+		}
+		if !seen[value] {
+			seen[value] = true
+			categories = append(categories, value)
+		}
+	}
+
+	switch {
+	case numeric:
+		return config.AttributeSpec{Name: name, Type: config.AttributeNumeric, Min: low, Max: high}
+	case boolean:
+		return config.AttributeSpec{Name: name, Type: config.AttributeBoolean}
+	default:
+		return config.AttributeSpec{Name: name, Type: config.AttributeCategorical, Categories: categories}
+	}
+}
+
+// Attributes converts an item's attribute values to auction attributes in
+// schema order
+func Attributes(item Item, schema []config.AttributeSpec) ([]types.Attribute, error) {
+	attributes := make([]types.Attribute, len(schema))
+	for i, spec := range schema {
+		value, ok := item.Attributes[spec.Name]
+		if !ok {
+			return nil, fmt.Errorf("item %s has no attribute %s", item.ID, spec.Name)
+		}
+		attribute := types.Attribute{ID: i, Name: spec.Name}
+		switch spec.Type {
+		case config.AttributeCategorical:
+			index := slices.Index(spec.Categories, value)
+			if index < 0 {
+				return nil, fmt.Errorf("item %s: %q is not a category of %s", item.ID, value, spec.Name)
+			}
+			attribute.Value = float64(index)
+			attribute.Label = value
+		case config.AttributeBoolean:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("item %s: %s must be true or false, not %q", item.ID, spec.Name, value)
+			}
+			attribute.Label = strconv.FormatBool(b)
+			if b {
+				attribute.Value = 1
+			}
+		default:
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("item %s: %s must be a number, not %q", item.ID, spec.Name, value)
+			}
+			attribute.Value = v
+		}
+		attributes[i] = attribute
+	}
+	return attributes, nil
+}
+
+// Catalog hands out units of its items to auctions. A unit is taken when
+// an auction is created and put back if the auction does not sell it, so
+// unsold items come up again in auctions created later, as in streamed
+// runs. Batch runs create every auction before any settles.
+type Catalog struct {
+	mu         sync.Mutex
+	items      []Item
+	attributes [][]types.Attribute
+	remaining  []int // units left per item, not counting units in auction; -1 is unlimited
+	auctioning int   // units taken by auctions that have not settled
+	sampling   string
+	next       int // next item in sequential order
+	index      map[string]int
+}
+
+// New creates a catalog of items described by schema, sampled by sampling,
+// one of the config.Sample constants
+func New(items []Item, schema []config.AttributeSpec, sampling string) (*Catalog, error) {
+	c := &Catalog{
+		items:      items,
+		attributes: make([][]types.Attribute, len(items)),
+		remaining:  make([]int, len(items)),
+		sampling:   sampling,
+		index:      make(map[string]int, len(items)),
+	}
+	for i, item := range items {
+		attributes, err := Attributes(item, schema)
+		if err != nil {
+			return nil, err
+		}
+		c.attributes[i] = attributes
+		c.remaining[i] = item.Quantity
+		if item.Quantity == 0 {
+			c.remaining[i] = -1
+		}
+		c.index[item.ID] = i
+	}
+	return c, nil
+}
+
+// Open loads the catalog configured for a run
+func Open(cfg *config.Config) (*Catalog, error) {
+	items, err := Load(cfg.Catalog.Path)
+	if err != nil {
+		return nil, err
+	}
+	return New(items, Schema(items, cfg.Attributes), cfg.Catalog.Sampling)
+}
+
+// Take draws an item with units left and takes one unit of it, returning
+// the item and its attributes. ok is false once everything is sold.
+func (c *Catalog) Take(rng *utils.RNG) (item Item, attributes []types.Attribute, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := c.pick(rng)
+	if i < 0 {
+		return Item{}, nil, false
+	}
+	if c.remaining[i] > 0 {
+		c.remaining[i]--
+	}
+	c.auctioning++
+	return c.items[i], c.attributes[i], true
+}
+
+// pick returns the index of the item to auction next, or -1
+func (c *Catalog) pick(rng *utils.RNG) int {
+	switch c.sampling {
+	case config.SampleUniform:
+		var available []int
+		for i, left := range c.remaining {
+			if left != 0 {
+				available = append(available, i)
+			}
+		}
+		if len(available) == 0 {
+			return -1
+		}
+		return available[rng.Intn(len(available))]
+	case config.SampleStock:
+		// Weighted by units left; unlimited items weigh as much as the
+		// largest stock, or 1 if every item is unlimited
+		weights := make([]int, len(c.remaining))
+		largest, total := 1, 0
+		for _, left := range c.remaining {
+			largest = max(largest, left)
+		}
+		for i, left := range c.remaining {
+			weights[i] = left
+			if left < 0 {
+				weights[i] = largest
+			}
+			total += weights[i]
+		}
+		if total == 0 {
+			return -1
+		}
+		target := rng.Intn(total)
+		for i, w := range weights {
+			if target < w {
+				return i
+			}
+			target -= w
+		}
+		return -1
+	default:
+		for range c.items {
+			i := c.next
+			c.next = (c.next + 1) % len(c.items)
+			if c.remaining[i] != 0 {
+				return i
+			}
+		}
+		return -1
+	}
+}
+
+// Settle ends the auction of a unit of an item, putting the unit back if
+// the auction did not sell it
+func (c *Catalog) Settle(itemID string, sold bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i, ok := c.index[itemID]
+	if !ok {
+		return
+	}
+	c.auctioning--
+	if !sold && c.remaining[i] >= 0 {
+		c.remaining[i]++
+	}
+}
+
+// SoldOut reports whether every unit is sold, so none can come back from
+// auctions still running
+func (c *Catalog) SoldOut() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.auctioning > 0 {
+		return false
+	}
+	for _, left := range c.remaining {
+		if left != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Quantities returns the units of each item for sale, 0 for unlimited
+func (c *Catalog) Quantities() map[string]int {
+	quantities := make(map[string]int, len(c.items))
+	for _, item := range c.items {
+		quantities[item.ID] = item.Quantity
+	}
+	return quantities
+}
@@ -0,0 +1,208 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"auction-simulator/internal/bidder"
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/types"
+)
+
+// Version is the checkpoint format written by this build
+const Version = 1
+
+// DefaultPath is where runs are checkpointed unless told otherwise
+const DefaultPath = "output/checkpoint.json"
+
+// DefaultInterval is how often a running simulation is checkpointed
+const DefaultInterval = 30 * time.Second
+
+// Random streams whose positions are recorded
+const (
+	StreamAuctions = "auctions"
+	StreamBidders  = "bidders"
+)
+
+// Checkpoint is the saved state of a partly finished run. Bids are drawn
+// from streams derived per bidder and auction, so the auctions still to run
+// only depend on the config, the bidders and the generator positions, and
+// for learning bidders on the order in which auctions complete.
+type Checkpoint struct {
+	Version  int                            `json:"version"`
+	RunID    string                         `json:"run_id"`
+	SavedAt  time.Time                      `json:"saved_at"`
+	Elapsed  time.Duration                  `json:"elapsed"` // run time up to the save, across resumes
+	Config   *config.Config                 `json:"config"`
+	Bidders  []*bidder.Bidder               `json:"bidders"`
+	Spent    map[string]float64             `json:"spent,omitempty"`    // budget spent by each bidder
+	Learners map[string]bidder.LearnerState `json:"learners,omitempty"` // what each learning bidder has learned
+	RNG      map[string]uint64              `json:"rng"`                // values drawn from each stream
+	Results  []*types.AuctionResult         `json:"results"`            // completed auctions
+}
+
+// Completed returns the IDs of the auctions that finished before the save
+func (c *Checkpoint) Completed() map[string]bool {
+	completed := make(map[string]bool, len(c.Results))
+	for _, result := range c.Results {
+		completed[result.AuctionID] = true
+	}
+	return completed
+}
+
+// Save writes cp to path. The file is replaced atomically, so a crash while
+// saving leaves the previous checkpoint intact.
+func Save(path string, cp *Checkpoint) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("could not create checkpoint directory: %w", err)
+	}
+
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("could not encode checkpoint: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("could not write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("could not replace checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Load reads a checkpoint written by Save
+func Load(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read checkpoint: %w", err)
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("could not parse checkpoint %s: %w", path, err)
+	}
+	if cp.Version != Version {
+		return nil, fmt.Errorf("checkpoint %s has format version %d, this build reads %d", path, cp.Version, Version)
+	}
+	if cp.Config == nil {
+		return nil, fmt.Errorf("checkpoint %s has no run config", path)
+	}
+	return &cp, nil
+}
+
+// Writer collects auction results as they complete and saves the
+// checkpoint periodically
+type Writer struct {
+	path     string
+	interval time.Duration
+	snapshot func(*Checkpoint)
+	started  time.Time
+
+	mu    sync.Mutex
+	cp    *Checkpoint
+	dirty bool
+
+	stopChan chan struct{}
+	doneChan chan struct{}
+}
+
+// NewWriter creates a writer that extends cp and saves it to path every
+// interval. snapshot is called before each save to record the current
+// bidder state and generator positions.
+func NewWriter(path string, interval time.Duration, cp *Checkpoint, snapshot func(*Checkpoint)) *Writer {
+	return &Writer{
+		path:     path,
+		interval: interval,
+		snapshot: snapshot,
+		cp:       cp,
+		stopChan: make(chan struct{}),
+		doneChan: make(chan struct{}),
+	}
+}
+
+// Record adds a completed auction. Auctions that were cut short by an
+// interrupt are left out, so a resumed run runs them again in full.
+func (w *Writer) Record(result *types.AuctionResult) {
+	if result.Aborted {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.cp.Results = append(w.cp.Results, result)
+	w.dirty = true
+}
+
+// Start begins saving periodically. The first save happens right away, so
+// a run preempted before any auction completes can still be resumed.
+func (w *Writer) Start() {
+	w.started = time.Now()
+	if err := w.save(true); err != nil {
+		log.Printf("Warning: Could not save checkpoint: %v", err)
+	}
+	go w.loop()
+}
+
+// Stop ends the periodic saves and writes a final checkpoint. It saves
+// even if no auction completed since the last save, so the time the run
+// spent up to the interrupt counts toward the resumed run.
+func (w *Writer) Stop() error {
+	close(w.stopChan)
+	<-w.doneChan
+	return w.save(true)
+}
+
+// loop saves the checkpoint every interval while results are coming in
+func (w *Writer) loop() {
+	defer close(w.doneChan)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := w.save(false); err != nil {
+				log.Printf("Warning: Could not save checkpoint: %v", err)
+			}
+		case <-w.stopChan:
+			return
+		}
+	}
+}
+
+// save writes the checkpoint if anything changed since the last save, or
+// regardless when forced
+func (w *Writer) save(force bool) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.dirty && !force {
+		return nil
+	}
+
+	w.cp.Version = Version
+	w.cp.SavedAt = time.Now()
+	base := w.cp.Elapsed
+	w.cp.Elapsed += time.Since(w.started)
+	if w.snapshot != nil {
+		w.snapshot(w.cp)
+	}
+
+	err := Save(w.path, w.cp)
+	w.cp.Elapsed = base
+	if err != nil {
+		return err
+	}
+
+	w.dirty = false
+	log.Printf("💾 Checkpoint saved: %d auctions completed", len(w.cp.Results))
+	return nil
+}
@@ -0,0 +1,60 @@
+package checkpoint
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/types"
+)
+
+func TestSaveLoadKeepsResultErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	cp := &Checkpoint{
+		Version: Version,
+		Config:  config.DefaultConfig(),
+		Results: []*types.AuctionResult{
+			{AuctionID: "auction-1", Error: errors.New("could not charge bidder-3: ledger unreachable")},
+			{AuctionID: "auction-2", Winner: &types.Bid{BidderID: "bidder-1", Amount: 120}, ClearingPrice: 110},
+		},
+	}
+	if err := Save(path, cp); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(loaded.Results) != 2 {
+		t.Fatalf("loaded %d results, want 2", len(loaded.Results))
+	}
+	if got := loaded.Results[0].Error; got == nil || got.Error() != cp.Results[0].Error.Error() {
+		t.Errorf("error of auction-1 loaded as %v, want %v", got, cp.Results[0].Error)
+	}
+	if got := loaded.Results[1]; got.Error != nil || got.Winner == nil || got.ClearingPrice != 110 {
+		t.Errorf("auction-2 loaded as %+v", got)
+	}
+}
+
+func TestStopSavesElapsedWithoutNewResults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	cp := &Checkpoint{Config: config.DefaultConfig(), Elapsed: time.Second}
+
+	w := NewWriter(path, time.Hour, cp, nil)
+	w.Start()
+	time.Sleep(50 * time.Millisecond)
+	if err := w.Stop(); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if loaded.Elapsed < time.Second+50*time.Millisecond {
+		t.Errorf("elapsed %v, want the earlier 1s plus the 50ms since the start", loaded.Elapsed)
+	}
+}
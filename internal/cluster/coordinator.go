@@ -0,0 +1,268 @@
+package cluster
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/rpc"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"auction-simulator/internal/auction"
+	"auction-simulator/internal/bidder"
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/metrics"
+	"auction-simulator/internal/simulation"
+	"auction-simulator/internal/types"
+)
+
+// startTimeout bounds how long a worker process may take to come up
+const startTimeout = 10 * time.Second
+
+// Coordinator spreads simulations over worker processes on this machine
+type Coordinator struct {
+	workers   []*workerProcess
+	idle      chan *workerProcess
+	interrupt sync.Once
+}
+
+// workerProcess is a running worker and the connection to it
+type workerProcess struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	client *rpc.Client
+}
+
+// StartWorkers launches n worker processes by running name with args. Each
+// must serve a Worker and print ReadyPrefix and its address on the first
+// line of its output; everything else it prints goes to logOutput.
+func StartWorkers(n int, logOutput io.Writer, name string, args ...string) (*Coordinator, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("need at least one worker, got %d", n)
+	}
+
+	c := &Coordinator{idle: make(chan *workerProcess, n)}
+	for i := 0; i < n; i++ {
+		worker, err := startWorker(logOutput, name, args...)
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("could not start worker %d: %w", i+1, err)
+		}
+		c.workers = append(c.workers, worker)
+		c.idle <- worker
+	}
+
+	log.Printf("Started %d workers", n)
+	return c, nil
+}
+
+// startWorker launches one worker process and connects to it
+func startWorker(logOutput io.Writer, name string, args ...string) (*workerProcess, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stderr = logOutput
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	worker := &workerProcess{cmd: cmd, stdin: stdin}
+
+	addrCh := make(chan string, 1)
+	go func() {
+		reader := bufio.NewReader(stdout)
+		line, _ := reader.ReadString('\n')
+		addrCh <- strings.TrimSpace(strings.TrimPrefix(line, ReadyPrefix))
+		io.Copy(logOutput, reader)
+	}()
+
+	select {
+	case addr := <-addrCh:
+		client, err := rpc.Dial("tcp", addr)
+		if err != nil {
+			worker.stop()
+			return nil, fmt.Errorf("could not connect to worker at %q: %w", addr, err)
+		}
+		worker.client = client
+		return worker, nil
+	case <-time.After(startTimeout):
+		worker.stop()
+		return nil, fmt.Errorf("worker did not start within %v", startTimeout)
+	}
+}
+
+// Workers returns the number of worker processes
+func (c *Coordinator) Workers() int {
+	return len(c.workers)
+}
+
+// Run executes the simulation for cfg with its auctions split round-robin
+// between the workers, and merges their results into one report. Winners
+// are charged to a single budget ledger kept by the coordinator.
+//
+// Like simulation.Run, an interrupt returns the partial report, marked
+// incomplete, together with ctx.Err().
+func (c *Coordinator) Run(ctx context.Context, cfg *config.Config) (*metrics.RunReport, error) {
+	if cfg.Arrival.Streaming() {
+		return nil, fmt.Errorf("streaming runs cannot be distributed")
+	}
+
+	// Workers generate the same auctions from the seed; only IDs are sent
+	auctionManager := auction.NewManager(cfg)
+	if err := auctionManager.InitializeAuctions(); err != nil {
+		return nil, fmt.Errorf("failed to initialize auctions: %w", err)
+	}
+	auctions := auctionManager.GetAuctions()
+	partitions := make([][]string, len(c.workers))
+	for i, auct := range auctions {
+		partitions[i%len(c.workers)] = append(partitions[i%len(c.workers)], auct.ID)
+	}
+
+	var ledgerAddr string
+	if cfg.Bidders.Budget > 0 {
+		listener, err := ServeLedger(bidder.NewBudgetLedger(cfg.Bidders.Budget))
+		if err != nil {
+			return nil, err
+		}
+		defer listener.Close()
+		ledgerAddr = listener.Addr().String()
+	}
+
+	log.Printf("🏁 Distributing %d auctions over %d workers", len(auctions), len(c.workers))
+	replies := make([]*PartitionReply, len(c.workers))
+	calls := make([]*rpc.Call, len(c.workers))
+	for i, worker := range c.workers {
+		if len(partitions[i]) == 0 {
+			continue
+		}
+		replies[i] = &PartitionReply{}
+		args := &PartitionArgs{Config: cfg, AuctionIDs: partitions[i], LedgerAddr: ledgerAddr}
+		calls[i] = worker.client.Go("Worker.RunPartition", args, replies[i], nil)
+	}
+
+	var parts []*metrics.SimulationMetrics
+	byID := make(map[string]*types.AuctionResult, len(auctions))
+	for i, call := range calls {
+		if call == nil {
+			continue
+		}
+		if err := c.wait(ctx, call); err != nil {
+			return nil, fmt.Errorf("worker %d: %w", i+1, err)
+		}
+		parts = append(parts, replies[i].Metrics)
+		for _, result := range replies[i].Results {
+			byID[result.AuctionID] = result
+		}
+	}
+
+	results := make([]*types.AuctionResult, 0, len(auctions))
+	for _, auct := range auctions {
+		if result, ok := byID[auct.ID]; ok {
+			results = append(results, result)
+		}
+	}
+
+	merged := metrics.MergeWorkers(parts, results)
+	merged.RunID = metrics.NewRunID()
+	merged.Seed = cfg.Seed
+	merged.TotalAuctions = cfg.TotalAuctions
+	merged.TotalBidders = cfg.TotalBidders
+	report := &metrics.RunReport{Config: cfg, Metrics: merged, Results: results}
+
+	if err := ctx.Err(); err != nil {
+		merged.Incomplete = true
+		merged.Failure = fmt.Sprintf("interrupted: %v", context.Cause(ctx))
+		return report, err
+	}
+	if merged.Incomplete {
+		return report, errors.New(merged.Failure)
+	}
+
+	log.Printf("Run %s finished on %d workers in %v (seed %d)", merged.RunID, len(parts),
+		merged.TotalDuration.Round(time.Millisecond), cfg.Seed)
+	return report, nil
+}
+
+// RunBatch runs a batch on the next idle worker. It has the signature of
+// simulation.RunBatch so sweep points can be spread over the workers; the
+// returned batch only carries the summary.
+func (c *Coordinator) RunBatch(ctx context.Context, cfg *config.Config, opts simulation.BatchOptions) (*simulation.Batch, error) {
+	var worker *workerProcess
+	select {
+	case worker = <-c.idle:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { c.idle <- worker }()
+
+	// Workers log to their own output
+	opts.Log = nil
+	reply := &BatchReply{}
+	call := worker.client.Go("Worker.RunBatch", &BatchArgs{Config: cfg, Options: opts}, reply, nil)
+	if err := c.wait(ctx, call); err != nil {
+		return nil, err
+	}
+	return &simulation.Batch{Summary: reply.Summary}, ctx.Err()
+}
+
+// wait waits for call to finish. If ctx is cancelled first, the workers are
+// interrupted so that they return what they have so far.
+func (c *Coordinator) wait(ctx context.Context, call *rpc.Call) error {
+	select {
+	case <-call.Done:
+	case <-ctx.Done():
+		c.interruptWorkers()
+		<-call.Done
+	}
+	return call.Error
+}
+
+// interruptWorkers asks every worker to stop its runs
+func (c *Coordinator) interruptWorkers() {
+	c.interrupt.Do(func() {
+		log.Printf("Interrupting %d workers", len(c.workers))
+		for _, worker := range c.workers {
+			worker.cmd.Process.Signal(os.Interrupt)
+		}
+	})
+}
+
+// Close disconnects from the workers and waits for them to exit
+func (c *Coordinator) Close() error {
+	for _, worker := range c.workers {
+		worker.stop()
+	}
+	return nil
+}
+
+// stop disconnects from a worker and closes its input, which tells it to
+// exit, killing it if it does not
+func (w *workerProcess) stop() {
+	if w.client != nil {
+		w.client.Close()
+	}
+	w.stdin.Close()
+
+	done := make(chan struct{})
+	go func() {
+		w.cmd.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(startTimeout):
+		w.cmd.Process.Kill()
+		<-done
+	}
+}
@@ -0,0 +1,133 @@
+package cluster
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/rpc"
+	"testing"
+	"time"
+
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/metrics"
+	"auction-simulator/internal/simulation"
+)
+
+// startInProcess serves n workers in this process and connects a
+// coordinator to them, as StartWorkers does with worker processes
+func startInProcess(t *testing.T, n int) *Coordinator {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, n)
+
+	c := &Coordinator{idle: make(chan *workerProcess, n)}
+	for i := 0; i < n; i++ {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("listen: %v", err)
+		}
+		go func() { served <- Serve(ctx, listener) }()
+
+		client, err := rpc.Dial("tcp", listener.Addr().String())
+		if err != nil {
+			t.Fatalf("dial worker %d: %v", i+1, err)
+		}
+		worker := &workerProcess{client: client}
+		c.workers = append(c.workers, worker)
+		c.idle <- worker
+	}
+
+	t.Cleanup(func() {
+		for _, worker := range c.workers {
+			worker.client.Close()
+		}
+		cancel()
+		for i := 0; i < n; i++ {
+			if err := <-served; err != nil {
+				t.Errorf("worker: %v", err)
+			}
+		}
+	})
+	return c
+}
+
+func testConfig() *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.Seed = 11
+	cfg.TotalAuctions = 9
+	cfg.TotalBidders = 12
+	cfg.AuctionTimeout = 10 * time.Second
+	cfg.Bidders.MinSpeedMS, cfg.Bidders.MaxSpeedMS = 1, 5
+	return cfg
+}
+
+func TestDistributedRunMatchesSingleProcess(t *testing.T) {
+	cfg := testConfig()
+	single, err := simulation.Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("single-process run: %v", err)
+	}
+
+	for _, workers := range []int{2, 3} {
+		distributed, err := startInProcess(t, workers).Run(context.Background(), cfg)
+		if err != nil {
+			t.Fatalf("%d workers: %v", workers, err)
+		}
+		if len(distributed.Results) != len(single.Results) {
+			t.Fatalf("%d workers returned %d results, want %d", workers, len(distributed.Results), len(single.Results))
+		}
+		for i, want := range single.Results {
+			got := distributed.Results[i]
+			switch {
+			case got.AuctionID != want.AuctionID:
+				t.Errorf("%d workers: result %d is %s, want %s", workers, i, got.AuctionID, want.AuctionID)
+			case (got.Winner == nil) != (want.Winner == nil),
+				got.Winner != nil && got.Winner.BidderID != want.Winner.BidderID:
+				t.Errorf("%d workers: %s has a different winner", workers, want.AuctionID)
+			case math.Abs(got.ClearingPrice-want.ClearingPrice) > 1e-9:
+				t.Errorf("%d workers: %s cleared at %.2f, want %.2f", workers, want.AuctionID, got.ClearingPrice, want.ClearingPrice)
+			case got.TotalBids != want.TotalBids:
+				t.Errorf("%d workers: %s has %d bids, want %d", workers, want.AuctionID, got.TotalBids, want.TotalBids)
+			}
+		}
+
+		m, want := distributed.Metrics, single.Metrics
+		if m.SuccessfulAuctions != want.SuccessfulAuctions || m.TotalBidsReceived != want.TotalBidsReceived {
+			t.Errorf("%d workers: %d sold with %d bids, want %d with %d", workers,
+				m.SuccessfulAuctions, m.TotalBidsReceived, want.SuccessfulAuctions, want.TotalBidsReceived)
+		}
+		if math.Abs(m.TotalRevenue-want.TotalRevenue) > 1e-6 {
+			t.Errorf("%d workers: revenue $%.2f, want $%.2f", workers, m.TotalRevenue, want.TotalRevenue)
+		}
+	}
+}
+
+func TestDistributedRunSharesBudgets(t *testing.T) {
+	cfg := testConfig()
+	cfg.TotalAuctions = 24
+	cfg.TotalBidders = 4
+	cfg.Bidders.Budget = 250 // a few wins each
+
+	report, err := startInProcess(t, 3).Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("distributed run: %v", err)
+	}
+
+	spent := make(map[string]float64)
+	for _, result := range report.Results {
+		if result.Error != nil {
+			continue
+		}
+		for _, share := range metrics.WinShares(result) {
+			spent[share.BidderID] += share.Price
+		}
+	}
+	if len(spent) == 0 {
+		t.Fatal("no auction was won")
+	}
+	for bidderID, amount := range spent {
+		if amount > cfg.Bidders.Budget+1e-6 {
+			t.Errorf("%s spent $%.2f of a $%.2f budget", bidderID, amount, cfg.Bidders.Budget)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package cluster
+
+import (
+	"fmt"
+	"net"
+	"net/rpc"
+
+	"auction-simulator/internal/bidder"
+)
+
+// LedgerService exposes the coordinator's budget ledger to workers, so every
+// worker charges the same budgets
+type LedgerService struct {
+	ledger bidder.Ledger
+}
+
+// ChargeArgs asks the ledger to charge a bidder
+type ChargeArgs struct {
+	BidderID string
+	Amount   float64
+}
+
+// Remaining returns what a bidder has left to spend
+func (s *LedgerService) Remaining(bidderID string, reply *float64) error {
+	remaining, err := s.ledger.Remaining(bidderID)
+	*reply = remaining
+	return err
+}
+
+// Charge deducts an amount from a bidder's budget if it covers it
+func (s *LedgerService) Charge(args ChargeArgs, reply *bool) error {
+	charged, err := s.ledger.Charge(args.BidderID, args.Amount)
+	*reply = charged
+	return err
+}
+
+// ServeLedger serves ledger on a local port until the returned listener is
+// closed
+func ServeLedger(ledger bidder.Ledger) (net.Listener, error) {
+	server := rpc.NewServer()
+	if err := server.RegisterName("Ledger", &LedgerService{ledger: ledger}); err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("could not listen for ledger clients: %w", err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.ServeConn(conn)
+		}
+	}()
+	return listener, nil
+}
+
+// LedgerClient is a bidder.Ledger kept by the coordinator
+type LedgerClient struct {
+	client *rpc.Client
+}
+
+// DialLedger connects to a ledger served by ServeLedger
+func DialLedger(addr string) (*LedgerClient, error) {
+	client, err := rpc.Dial("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to ledger: %w", err)
+	}
+	return &LedgerClient{client: client}, nil
+}
+
+// Remaining implements bidder.Ledger
+func (c *LedgerClient) Remaining(bidderID string) (float64, error) {
+	var remaining float64
+	err := c.client.Call("Ledger.Remaining", bidderID, &remaining)
+	return remaining, err
+}
+
+// Charge implements bidder.Ledger
+func (c *LedgerClient) Charge(bidderID string, amount float64) (bool, error) {
+	var charged bool
+	err := c.client.Call("Ledger.Charge", ChargeArgs{BidderID: bidderID, Amount: amount}, &charged)
+	return charged, err
+}
+
+// Close disconnects from the ledger
+func (c *LedgerClient) Close() error {
+	return c.client.Close()
+}
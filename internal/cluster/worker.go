@@ -0,0 +1,135 @@
+package cluster
+
+import (
+	"context"
+	"encoding/gob"
+	"net"
+	"net/rpc"
+	"sync"
+
+	"auction-simulator/internal/bidder"
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/metrics"
+	"auction-simulator/internal/simulation"
+	"auction-simulator/internal/types"
+)
+
+// ReadyPrefix starts the line a worker prints once it accepts requests,
+// followed by its address
+const ReadyPrefix = "worker listening on "
+
+// RemoteError carries an auction error from a worker to the coordinator
+type RemoteError struct {
+	Message string
+}
+
+// Error implements error
+func (e RemoteError) Error() string {
+	return e.Message
+}
+
+func init() {
+	gob.Register(RemoteError{})
+}
+
+// PartitionArgs asks a worker to run some of the auctions of a run
+type PartitionArgs struct {
+	Config     *config.Config
+	AuctionIDs []string
+	LedgerAddr string // coordinator's budget ledger; empty when budgets are unlimited
+}
+
+// PartitionReply is a worker's share of a run
+type PartitionReply struct {
+	Metrics *metrics.SimulationMetrics
+	Results []*types.AuctionResult
+}
+
+// BatchArgs asks a worker to run a batch, e.g. one sweep point
+type BatchArgs struct {
+	Config  *config.Config
+	Options simulation.BatchOptions
+}
+
+// BatchReply is the summary of a worker's batch
+type BatchReply struct {
+	Summary *metrics.BatchSummary
+}
+
+// Worker is the RPC service a worker process offers its coordinator. When
+// ctx is cancelled, runs in progress stop and reply with partial results.
+type Worker struct {
+	ctx context.Context
+}
+
+// RunPartition runs the auctions in args.AuctionIDs
+func (w *Worker) RunPartition(args *PartitionArgs, reply *PartitionReply) error {
+	var ledger bidder.Ledger
+	if args.LedgerAddr != "" {
+		client, err := DialLedger(args.LedgerAddr)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+		ledger = client
+	}
+
+	report, err := simulation.RunPartition(w.ctx, args.Config, args.AuctionIDs, ledger)
+	if report == nil {
+		return err
+	}
+
+	// A partial report carries the reason it stopped in its metrics
+	reply.Metrics = report.Metrics
+	reply.Results = report.Results
+	for _, result := range reply.Results {
+		if result.Error != nil {
+			result.Error = RemoteError{Message: result.Error.Error()}
+		}
+	}
+	return nil
+}
+
+// RunBatch runs a batch of repeated simulations
+func (w *Worker) RunBatch(args *BatchArgs, reply *BatchReply) error {
+	batch, err := simulation.RunBatch(w.ctx, args.Config, args.Options)
+	if batch == nil {
+		return err
+	}
+	reply.Summary = batch.Summary
+	return nil
+}
+
+// Serve answers coordinator requests on listener until ctx is done and the
+// coordinator has disconnected, so replies to interrupted runs still reach it
+func Serve(ctx context.Context, listener net.Listener) error {
+	server := rpc.NewServer()
+	if err := server.RegisterName("Worker", &Worker{ctx: ctx}); err != nil {
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	var conns sync.WaitGroup
+	var acceptErr error
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() == nil {
+				acceptErr = err
+			}
+			break
+		}
+		conns.Add(1)
+		go func() {
+			defer conns.Done()
+			server.ServeConn(conn)
+		}()
+	}
+
+	conns.Wait()
+	return acceptErr
+}
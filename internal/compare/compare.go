@@ -0,0 +1,286 @@
+package compare
+
+import (
+	"math"
+	"math/rand"
+
+	"auction-simulator/internal/metrics"
+	"auction-simulator/internal/stats"
+	"auction-simulator/internal/types"
+)
+
+// Direction says which way a metric has to move to count as an improvement
+type Direction int
+
+const (
+	HigherIsBetter Direction = iota
+	LowerIsBetter
+	Neutral
+)
+
+// Options controls significance testing and regression thresholds
+type Options struct {
+	Threshold  float64 // relative change in the bad direction that counts as a regression
+	Confidence float64 // confidence level of bootstrap intervals
+	Resamples  int     // bootstrap resamples per metric
+	Seed       int64   // seed for the bootstrap RNG so comparisons are reproducible
+}
+
+// DefaultOptions returns a 5% regression threshold at 95% confidence
+func DefaultOptions() Options {
+	return Options{
+		Threshold:  0.05,
+		Confidence: 0.95,
+		Resamples:  2000,
+		Seed:       1,
+	}
+}
+
+// MetricDelta is the change of one metric between baseline and candidate
+type MetricDelta struct {
+	Name        string          `json:"name"`
+	Baseline    float64         `json:"baseline"`
+	Candidate   float64         `json:"candidate"`
+	Delta       float64         `json:"delta"`
+	RelDelta    float64         `json:"rel_delta"`
+	CI          *stats.Interval `json:"ci,omitempty"` // nil when only summary metrics are available
+	Significant bool            `json:"significant"`
+	Regressed   bool            `json:"regressed"`
+	Missing     bool            `json:"missing,omitempty"` // a run has no samples, so the metric is not judged
+}
+
+// WinShift describes how the distribution of winners moved between runs.
+// A shift has no better or worse direction, so it is flagged but never gates.
+type WinShift struct {
+	TotalVariation float64 `json:"total_variation"`
+	ChiSquare      float64 `json:"chi_square"`
+	DF             int     `json:"df"`
+	PValue         float64 `json:"p_value"`
+	Significant    bool    `json:"significant"`
+}
+
+// Comparison is the full comparison of a candidate run against a baseline
+type Comparison struct {
+	BaselineID  string        `json:"baseline_id"`
+	CandidateID string        `json:"candidate_id"`
+	Metrics     []MetricDelta `json:"metrics"`
+	WinShift    *WinShift     `json:"win_shift,omitempty"` // nil without full results
+}
+
+// Regressed reports whether any metric regressed beyond the threshold
+func (c *Comparison) Regressed() bool {
+	for _, m := range c.Metrics {
+		if m.Regressed {
+			return true
+		}
+	}
+	return false
+}
+
+// metricSpec describes how to extract one metric from a run
+type metricSpec struct {
+	name      string
+	direction Direction
+	summary   func(m *metrics.SimulationMetrics) float64
+	counted   func(m *metrics.SimulationMetrics) int // samples behind the summary
+	samples   func(results []*types.AuctionResult) []float64
+	stat      stats.Statistic
+}
+
+var metricSpecs = []metricSpec{
+	{
+		name:      "revenue_per_auction",
+		direction: HigherIsBetter,
+		summary: func(m *metrics.SimulationMetrics) float64 {
+			return ratio(m.TotalRevenue, float64(m.TotalAuctions))
+		},
+		counted: auctionCount,
+		samples: metrics.AuctionRevenues,
+		stat:    stats.Mean,
+	},
+	{
+		name:      "success_rate",
+		direction: HigherIsBetter,
+		summary: func(m *metrics.SimulationMetrics) float64 {
+			return ratio(float64(m.SuccessfulAuctions), float64(m.TotalAuctions))
+		},
+		counted: auctionCount,
+		samples: successIndicators,
+		stat:    stats.Mean,
+	},
+	{
+		name:      "bids_per_auction",
+		direction: Neutral,
+		summary: func(m *metrics.SimulationMetrics) float64 {
+			return m.AverageBidsPerAuction
+		},
+		counted: auctionCount,
+		samples: bidCounts,
+		stat:    stats.Mean,
+	},
+	{
+		name:      "latency_p50_ms",
+		direction: LowerIsBetter,
+		summary:   func(m *metrics.SimulationMetrics) float64 { return m.LatencyP50MS },
+		counted:   bidCount,
+		samples:   metrics.BidLatenciesMS,
+		stat:      stats.PercentileStat(50),
+	},
+	{
+		name:      "latency_p90_ms",
+		direction: LowerIsBetter,
+		summary:   func(m *metrics.SimulationMetrics) float64 { return m.LatencyP90MS },
+		counted:   bidCount,
+		samples:   metrics.BidLatenciesMS,
+		stat:      stats.PercentileStat(90),
+	},
+	{
+		name:      "latency_p99_ms",
+		direction: LowerIsBetter,
+		summary:   func(m *metrics.SimulationMetrics) float64 { return m.LatencyP99MS },
+		counted:   bidCount,
+		samples:   metrics.BidLatenciesMS,
+		stat:      stats.PercentileStat(99),
+	},
+}
+
+// Compare evaluates a candidate run against a baseline. Runs that carry full
+// results get bootstrap intervals; summary-only runs are judged on the
+// threshold alone.
+func Compare(baseline, candidate *metrics.RunReport, opts Options) *Comparison {
+	rng := rand.New(rand.NewSource(opts.Seed))
+	full := len(baseline.Results) > 0 && len(candidate.Results) > 0
+
+	comparison := &Comparison{
+		BaselineID:  baseline.Metrics.RunID,
+		CandidateID: candidate.Metrics.RunID,
+	}
+
+	for _, spec := range metricSpecs {
+		delta := MetricDelta{Name: spec.name}
+
+		var a, b []float64
+		if full {
+			a, b = spec.samples(baseline.Results), spec.samples(candidate.Results)
+			delta.Baseline, delta.Candidate = spec.stat(a), spec.stat(b)
+			delta.Missing = len(a) == 0 || len(b) == 0
+		} else {
+			delta.Baseline, delta.Candidate = spec.summary(baseline.Metrics), spec.summary(candidate.Metrics)
+			delta.Missing = spec.counted(baseline.Metrics) == 0 || spec.counted(candidate.Metrics) == 0
+		}
+
+		// A metric one run has no samples of cannot have changed or regressed
+		if delta.Missing {
+			comparison.Metrics = append(comparison.Metrics, delta)
+			continue
+		}
+
+		delta.Delta = delta.Candidate - delta.Baseline
+		delta.RelDelta = relativeChange(delta.Baseline, delta.Candidate)
+
+		if len(a) > 0 && len(b) > 0 {
+			ci := stats.BootstrapDiff(a, b, spec.stat, opts.Resamples, opts.Confidence, rng)
+			delta.CI = &ci
+			delta.Significant = !ci.Contains(0)
+		}
+
+		worse := (spec.direction == HigherIsBetter && delta.RelDelta < -opts.Threshold) ||
+			(spec.direction == LowerIsBetter && delta.RelDelta > opts.Threshold)
+		delta.Regressed = worse && (delta.CI == nil || delta.Significant)
+
+		comparison.Metrics = append(comparison.Metrics, delta)
+	}
+
+	if full {
+		comparison.WinShift = winShift(baseline.Results, candidate.Results, opts.Confidence)
+	}
+	return comparison
+}
+
+// winShift compares who won auctions in each run
+func winShift(baseline, candidate []*types.AuctionResult, confidence float64) *WinShift {
+	a, b := metrics.WinCounts(baseline), metrics.WinCounts(candidate)
+
+	shift := &WinShift{TotalVariation: totalVariation(a, b)}
+	shift.ChiSquare, shift.DF, shift.PValue = stats.ChiSquareHomogeneity(a, b)
+	shift.Significant = shift.PValue < 1-confidence
+	return shift
+}
+
+// totalVariation is half the L1 distance between two win-share distributions
+func totalVariation(a, b map[string]int) float64 {
+	totalA, totalB := 0, 0
+	for _, v := range a {
+		totalA += v
+	}
+	for _, v := range b {
+		totalB += v
+	}
+	if totalA == 0 || totalB == 0 {
+		return 0
+	}
+
+	keys := make(map[string]struct{})
+	for k := range a {
+		keys[k] = struct{}{}
+	}
+	for k := range b {
+		keys[k] = struct{}{}
+	}
+
+	sum := 0.0
+	for k := range keys {
+		sum += math.Abs(float64(a[k])/float64(totalA) - float64(b[k])/float64(totalB))
+	}
+	return sum / 2
+}
+
+// auctionCount returns the auctions a run's per-auction metrics cover
+func auctionCount(m *metrics.SimulationMetrics) int {
+	return m.TotalAuctions
+}
+
+// bidCount returns the bids a run's latency metrics cover
+func bidCount(m *metrics.SimulationMetrics) int {
+	return m.TotalBidsReceived
+}
+
+// successIndicators maps each auction to 1 on success and 0 on failure
+func successIndicators(results []*types.AuctionResult) []float64 {
+	indicators := make([]float64, len(results))
+	for i, result := range results {
+		if result.Error == nil {
+			indicators[i] = 1
+		}
+	}
+	return indicators
+}
+
+// bidCounts returns the number of bids received by each auction
+func bidCounts(results []*types.AuctionResult) []float64 {
+	counts := make([]float64, len(results))
+	for i, result := range results {
+		counts[i] = float64(result.TotalBids)
+	}
+	return counts
+}
+
+// relativeChange returns (candidate-baseline)/|baseline|, saturating at
+// +/-100% when the baseline is zero
+func relativeChange(baseline, candidate float64) float64 {
+	if baseline == 0 {
+		if candidate == 0 {
+			return 0
+		}
+		return math.Copysign(1, candidate)
+	}
+	return (candidate - baseline) / math.Abs(baseline)
+}
+
+// ratio divides safely, returning 0 for an empty denominator
+func ratio(num, den float64) float64 {
+	if den == 0 {
+		return 0
+	}
+	return num / den
+}
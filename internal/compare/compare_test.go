@@ -0,0 +1,130 @@
+package compare
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"auction-simulator/internal/metrics"
+	"auction-simulator/internal/types"
+)
+
+// report builds a run of n auctions, each won by one of winners at a
+// price around price and with bids answered in about latency
+func report(id string, n int, price float64, latency time.Duration, winners []string, seed int64) *metrics.RunReport {
+	rng := rand.New(rand.NewSource(seed))
+	results := make([]*types.AuctionResult, n)
+	for i := range results {
+		bid := types.Bid{
+			BidderID: winners[i%len(winners)],
+			Amount:   price + 5*rng.NormFloat64(),
+			Latency:  latency + time.Duration(rng.Intn(5))*time.Millisecond,
+		}
+		results[i] = &types.AuctionResult{
+			AuctionID:     fmt.Sprintf("auction-%d", i+1),
+			Winner:        &bid,
+			ClearingPrice: bid.Amount,
+			Bids:          []types.Bid{bid},
+			TotalBids:     1,
+		}
+	}
+	m := &metrics.SimulationMetrics{RunID: id}
+	m.ApplyResults(results)
+	return &metrics.RunReport{Metrics: m, Results: results}
+}
+
+// delta returns the comparison of one metric
+func delta(t *testing.T, c *Comparison, name string) MetricDelta {
+	t.Helper()
+	for _, d := range c.Metrics {
+		if d.Name == name {
+			return d
+		}
+	}
+	t.Fatalf("no %s in comparison", name)
+	return MetricDelta{}
+}
+
+func TestCompareFlagsOnlyRealRegressions(t *testing.T) {
+	bidders := []string{"bidder-1", "bidder-2", "bidder-3"}
+	baseline := report("base", 300, 100, 10*time.Millisecond, bidders, 1)
+
+	tests := []struct {
+		name      string
+		candidate *metrics.RunReport
+		metric    string
+		regressed bool
+	}{
+		{"same setup", report("same", 300, 100, 10*time.Millisecond, bidders, 2), "revenue_per_auction", false},
+		{"lower revenue", report("cheap", 300, 80, 10*time.Millisecond, bidders, 3), "revenue_per_auction", true},
+		{"higher revenue", report("rich", 300, 120, 10*time.Millisecond, bidders, 4), "revenue_per_auction", false},
+		{"slower bids", report("slow", 300, 100, 30*time.Millisecond, bidders, 5), "latency_p50_ms", true},
+		{"faster bids", report("fast", 300, 100, 5*time.Millisecond, bidders, 6), "latency_p50_ms", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Compare(baseline, tt.candidate, DefaultOptions())
+			d := delta(t, c, tt.metric)
+			if d.Regressed != tt.regressed {
+				t.Errorf("%s regressed = %v (delta %.3f, CI %+v), want %v", tt.metric, d.Regressed, d.RelDelta, d.CI, tt.regressed)
+			}
+			if tt.name == "same setup" && c.Regressed() {
+				t.Errorf("identical setups compared as a regression: %+v", c.Metrics)
+			}
+			if tt.regressed && (!d.Significant || d.CI == nil || d.CI.Contains(0)) {
+				t.Errorf("%s regressed without a significant interval %+v", tt.metric, d.CI)
+			}
+		})
+	}
+}
+
+func TestCompareWithoutSamples(t *testing.T) {
+	baseline := report("base", 50, 100, 10*time.Millisecond, []string{"bidder-1"}, 1)
+	empty := &metrics.RunReport{Metrics: &metrics.SimulationMetrics{RunID: "empty"}}
+
+	c := Compare(baseline, empty, DefaultOptions())
+	for _, d := range c.Metrics {
+		if !d.Missing || d.Regressed {
+			t.Errorf("%s against a run without auctions: missing %v regressed %v", d.Name, d.Missing, d.Regressed)
+		}
+	}
+	if c.Regressed() {
+		t.Error("a run without samples compared as a regression")
+	}
+}
+
+func TestWinShift(t *testing.T) {
+	a := report("a", 120, 100, 10*time.Millisecond, []string{"bidder-1", "bidder-2"}, 1)
+	b := report("b", 120, 100, 10*time.Millisecond, []string{"bidder-1", "bidder-1", "bidder-1", "bidder-2"}, 2)
+
+	shift := winShift(a.Results, b.Results, 0.95)
+	// Shares 1/2, 1/2 against 3/4, 1/4
+	if math.Abs(shift.TotalVariation-0.25) > 1e-9 {
+		t.Errorf("total variation %.3f, want 0.25", shift.TotalVariation)
+	}
+	if shift.DF != 1 || !shift.Significant {
+		t.Errorf("shift df %d p %.4f, want a significant shift on 1 df", shift.DF, shift.PValue)
+	}
+
+	if same := winShift(a.Results, a.Results, 0.95); same.TotalVariation != 0 || same.Significant {
+		t.Errorf("identical winners shift by %.3f, significant %v", same.TotalVariation, same.Significant)
+	}
+}
+
+func TestRelativeChange(t *testing.T) {
+	tests := []struct{ baseline, candidate, want float64 }{
+		{100, 110, 0.1},
+		{100, 90, -0.1},
+		{-50, -25, 0.5},
+		{0, 0, 0},
+		{0, 3, 1},
+		{0, -3, -1},
+	}
+	for _, tt := range tests {
+		if got := relativeChange(tt.baseline, tt.candidate); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("relativeChange(%g, %g) = %g, want %g", tt.baseline, tt.candidate, got, tt.want)
+		}
+	}
+}
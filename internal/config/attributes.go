@@ -0,0 +1,133 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Attribute types
+const (
+	AttributeNumeric     = "numeric"     // a value in [Min, Max), to the cent
+	AttributeCategorical = "categorical" // one of Categories; the value is its index
+	AttributeBoolean     = "boolean"     // 1 for true, 0 for false
+)
+
+// Distributions numeric attributes are drawn from
+const (
+	DistUniform   = "uniform"   // evenly over [Min, Max)
+	DistNormal    = "normal"    // Mean and StdDev, kept within [Min, Max]
+	DistLogNormal = "lognormal" // Mean and StdDev of the logarithm, kept within [Min, Max]
+)
+
+// AttributeSpec describes one attribute of the items auctioned, such as
+// geo, device or ad size
+type AttributeSpec struct {
+	Name         string    `json:"name"`
+	Type         string    `json:"type"`
+	Min          float64   `json:"min,omitempty"`
+	Max          float64   `json:"max,omitempty"`
+	Distribution string    `json:"distribution,omitempty"` // numeric only; empty is uniform
+	Mean         float64   `json:"mean,omitempty"`
+	StdDev       float64   `json:"stddev,omitempty"`
+	Categories   []string  `json:"categories,omitempty"`
+	Weights      []float64 `json:"weights,omitempty"`     // relative frequency of each category; empty draws evenly
+	Probability  float64   `json:"probability,omitempty"` // chance a boolean is true; 0 draws evenly
+}
+
+// DefaultAttributeSchema returns n unnamed numeric attributes uniform over
+// [0, 100), the attributes of auctions without a schema
+func DefaultAttributeSchema(n int) []AttributeSpec {
+	schema := make([]AttributeSpec, n)
+	for i := range schema {
+		schema[i] = AttributeSpec{Type: AttributeNumeric, Max: 100}
+	}
+	return schema
+}
+
+// AttributeSchema returns the attributes of the items auctioned: the
+// loaded schema, or AttributesPerAuction default attributes
+func (c *Config) AttributeSchema() []AttributeSpec {
+	if len(c.Attributes) > 0 {
+		return c.Attributes
+	}
+	return DefaultAttributeSchema(c.AttributesPerAuction)
+}
+
+// Validate checks that an attribute can be generated
+func (s *AttributeSpec) Validate() error {
+	switch s.Type {
+	case AttributeNumeric:
+		if s.Max <= s.Min {
+			return fmt.Errorf("attribute %s: max %g must be above min %g", s.Name, s.Max, s.Min)
+		}
+		switch s.Distribution {
+		case "", DistUniform:
+		case DistNormal, DistLogNormal:
+			if s.StdDev <= 0 {
+				return fmt.Errorf("attribute %s: %s distribution needs a positive stddev", s.Name, s.Distribution)
+			}
+		default:
+			return fmt.Errorf("attribute %s: unknown distribution %q", s.Name, s.Distribution)
+		}
+	case AttributeCategorical:
+		if len(s.Categories) == 0 {
+			return fmt.Errorf("attribute %s: no categories", s.Name)
+		}
+		if len(s.Weights) == 0 {
+			return nil
+		}
+		if len(s.Weights) != len(s.Categories) {
+			return fmt.Errorf("attribute %s: %d weights for %d categories", s.Name, len(s.Weights), len(s.Categories))
+		}
+		total := 0.0
+		for _, w := range s.Weights {
+			if w < 0 {
+				return fmt.Errorf("attribute %s: negative weight %g", s.Name, w)
+			}
+			total += w
+		}
+		if total <= 0 {
+			return fmt.Errorf("attribute %s: weights must not all be zero", s.Name)
+		}
+	case AttributeBoolean:
+		if s.Probability < 0 || s.Probability > 1 {
+			return fmt.Errorf("attribute %s: probability %g outside [0, 1]", s.Name, s.Probability)
+		}
+	default:
+		return fmt.Errorf("attribute %s: unknown type %q", s.Name, s.Type)
+	}
+	return nil
+}
+
+// LoadAttributeSchema reads a JSON array of attribute specs and checks that
+// every attribute has a distinct name and can be generated
+func LoadAttributeSchema(path string) ([]AttributeSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read attribute schema: %w", err)
+	}
+	var schema []AttributeSpec
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("could not parse attribute schema: %w", err)
+	}
+	if len(schema) == 0 {
+		return nil, fmt.Errorf("attribute schema %s has no attributes", path)
+	}
+
+	names := make(map[string]bool, len(schema))
+	for i := range schema {
+		spec := &schema[i]
+		if spec.Name == "" {
+			return nil, fmt.Errorf("attribute %d has no name", i)
+		}
+		if names[spec.Name] {
+			return nil, fmt.Errorf("attribute %s is defined twice", spec.Name)
+		}
+		names[spec.Name] = true
+		if err := spec.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return schema, nil
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// cgroupRoot is where the cgroup filesystem is mounted
+const cgroupRoot = "/sys/fs/cgroup"
+
+// unlimitedMemory is the threshold above which cgroup v1 memory limits mean
+// "no limit"; the kernel reports a page-aligned LONG_MAX
+const unlimitedMemory = 1 << 62
+
+// ContainerLimits are the CPU and memory limits imposed by the process's
+// cgroup. Zero values mean no limit was found.
+type ContainerLimits struct {
+	CgroupVersion int     // 1 or 2; 0 when no cgroup filesystem was readable
+	CPUQuota      float64 // CPUs worth of time per period, e.g. 1.5
+	MemoryBytes   int64
+}
+
+// MemoryMB returns the memory limit in megabytes
+func (c ContainerLimits) MemoryMB() int {
+	return int(c.MemoryBytes / 1024 / 1024)
+}
+
+// String describes the detected limits for logs
+func (c ContainerLimits) String() string {
+	if c.CgroupVersion == 0 {
+		return "no cgroup limits detected"
+	}
+	cpu, memory := "unlimited", "unlimited"
+	if c.CPUQuota > 0 {
+		cpu = strconv.FormatFloat(c.CPUQuota, 'f', 2, 64)
+	}
+	if c.MemoryBytes > 0 {
+		memory = fmt.Sprintf("%d MB", c.MemoryMB())
+	}
+	return fmt.Sprintf("cgroup v%d: cpu quota %s, memory %s", c.CgroupVersion, cpu, memory)
+}
+
+// DetectContainerLimits reads the CPU quota and memory limit of the current
+// process from cgroup v2, falling back to cgroup v1
+func DetectContainerLimits() ContainerLimits {
+	paths := selfCgroupPaths()
+
+	if _, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers")); err == nil {
+		return cgroupV2Limits(cgroupDirs(cgroupRoot, paths[""]))
+	}
+	if _, err := os.Stat(filepath.Join(cgroupRoot, "memory")); err == nil {
+		return cgroupV1Limits(paths)
+	}
+	return ContainerLimits{}
+}
+
+// cgroupV2Limits reads cpu.max and memory.max from the unified hierarchy,
+// keeping the tightest limit found on the way from the cgroup to the root
+func cgroupV2Limits(dirs []string) ContainerLimits {
+	limits := ContainerLimits{CgroupVersion: 2}
+	for _, dir := range dirs {
+		if fields := readFields(filepath.Join(dir, "cpu.max")); len(fields) == 2 && fields[0] != "max" {
+			quota, err1 := strconv.ParseFloat(fields[0], 64)
+			period, err2 := strconv.ParseFloat(fields[1], 64)
+			if err1 == nil && err2 == nil && period > 0 {
+				limits.CPUQuota = tighter(limits.CPUQuota, quota/period)
+			}
+		}
+		if fields := readFields(filepath.Join(dir, "memory.max")); len(fields) == 1 && fields[0] != "max" {
+			if bytes, err := strconv.ParseInt(fields[0], 10, 64); err == nil {
+				limits.MemoryBytes = int64(tighter(float64(limits.MemoryBytes), float64(bytes)))
+			}
+		}
+	}
+	return limits
+}
+
+// cgroupV1Limits reads the CFS quota and memory limit from the per-controller
+// hierarchies
+func cgroupV1Limits(paths map[string]string) ContainerLimits {
+	limits := ContainerLimits{CgroupVersion: 1}
+
+	for _, dir := range cgroupDirs(filepath.Join(cgroupRoot, "cpu"), paths["cpu"]) {
+		quota := readInt(filepath.Join(dir, "cpu.cfs_quota_us"))
+		period := readInt(filepath.Join(dir, "cpu.cfs_period_us"))
+		if quota > 0 && period > 0 {
+			limits.CPUQuota = tighter(limits.CPUQuota, float64(quota)/float64(period))
+		}
+	}
+	for _, dir := range cgroupDirs(filepath.Join(cgroupRoot, "memory"), paths["memory"]) {
+		if bytes := readInt(filepath.Join(dir, "memory.limit_in_bytes")); bytes > 0 && bytes < unlimitedMemory {
+			limits.MemoryBytes = int64(tighter(float64(limits.MemoryBytes), float64(bytes)))
+		}
+	}
+	return limits
+}
+
+// selfCgroupPaths parses /proc/self/cgroup into controller -> path. The
+// unified v2 hierarchy is stored under the empty controller name.
+func selfCgroupPaths() map[string]string {
+	paths := make(map[string]string)
+
+	file, err := os.Open("/proc/self/cgroup")
+	if err != nil {
+		return paths
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// Lines look like "hierarchy-ID:controller-list:path"
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[1] == "" {
+			paths[""] = parts[2]
+			continue
+		}
+		for _, controller := range strings.Split(parts[1], ",") {
+			paths[controller] = parts[2]
+		}
+	}
+	return paths
+}
+
+// cgroupDirs lists the directories from the process's cgroup up to the mount
+// root. Inside a container the cgroup namespace usually maps the process to
+// the root itself, and paths that do not exist under the mount are skipped.
+func cgroupDirs(mount, path string) []string {
+	var dirs []string
+	for p := filepath.Clean("/" + path); ; p = filepath.Dir(p) {
+		dir := filepath.Join(mount, p)
+		if _, err := os.Stat(dir); err == nil {
+			dirs = append(dirs, dir)
+		}
+		if p == "/" {
+			break
+		}
+	}
+	return dirs
+}
+
+// readFields returns the whitespace-separated fields of a small file
+func readFields(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(data))
+}
+
+// readInt reads a file holding a single integer, returning 0 on any error
+func readInt(path string) int64 {
+	fields := readFields(path)
+	if len(fields) != 1 {
+		return 0
+	}
+	n, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// tighter returns the smaller positive limit, treating 0 as unset
+func tighter(current, candidate float64) float64 {
+	if current <= 0 {
+		return candidate
+	}
+	return math.Min(current, candidate)
+}
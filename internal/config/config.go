@@ -1,6 +1,8 @@
 package config
 
 import (
+	"fmt"
+	"math"
 	"runtime"
 	"time"
 )
@@ -18,13 +20,137 @@ const (
 	DefaultMaxVCPUs            = 2
 	DefaultMaxMemoryMB         = 1024 // 1GB
 	MaxConcurrentBiddersPerCPU = 100
+	ContainerMemoryShare       = 0.9 // share of a container memory limit left to the simulation
 )
 
+// Pricing mechanisms that decide what the winner pays
+const (
+	FirstPrice  = "first-price"  // winner pays their own bid
+	SecondPrice = "second-price" // winner pays the runner-up bid or the reserve, whichever is higher
+)
+
+// Auction formats
+const (
+	FormatSealed = "sealed" // bidders are asked once and see no other bids
+	FormatOpen   = "open"   // bidders are asked in rounds and may raise the standing high bid
+)
+
+// OpenConfig controls open auctions. With a soft close, a bid that arrives
+// within Window of the deadline pushes it back by Extension, at most
+// MaxExtensions times, so other bidders can answer a last-moment bid.
+type OpenConfig struct {
+	RoundInterval time.Duration // time between the rounds a bidder is asked in
+	Window        time.Duration // 0 closes at the deadline regardless of bids
+	Extension     time.Duration
+	MaxExtensions int
+}
+
+// SoftClose reports whether late bids extend the deadline
+func (o OpenConfig) SoftClose() bool {
+	return o.Window > 0 && o.Extension > 0 && o.MaxExtensions > 0
+}
+
+// DefaultOpenConfig returns a hard close with soft-close defaults ready to
+// be switched on by setting Window
+func DefaultOpenConfig() OpenConfig {
+	return OpenConfig{
+		RoundInterval: 100 * time.Millisecond,
+		Extension:     250 * time.Millisecond,
+		MaxExtensions: 10,
+	}
+}
+
+// Tie-breaking policies for bids that tie for the win
+const (
+	TieEarliest = "earliest"  // the bid made first wins
+	TieRandom   = "random"    // a random tied bid wins, drawn from the run's seed
+	TieLowestID = "lowest-id" // the bid of the lowest bidder ID wins, numbering compared as numbers
+	TieSplit    = "split"     // the tied bidders share the item and its price equally
+)
+
+// Auction arrival processes for streaming mode
+const (
+	ArrivalPoisson  = "poisson"  // exponential gaps at Rate
+	ArrivalConstant = "constant" // evenly spaced at Rate
+	ArrivalBursty   = "bursty"   // Rate between bursts, BurstRate during them
+	ArrivalTrace    = "trace"    // replayed from TracePath
+	ArrivalReplay   = "replay"   // recorded bid requests replayed from TracePath
+)
+
+// ArrivalConfig switches the simulation from running TotalAuctions at once
+// to streaming mode, where auctions arrive over time for Duration
+type ArrivalConfig struct {
+	Process     string        // empty runs every auction at once
+	Rate        float64       // auctions per second
+	BurstRate   float64       // auctions per second during a burst
+	BurstEvery  time.Duration // mean time between bursts
+	BurstLength time.Duration // mean burst duration
+	TracePath   string        // arrival times, one per line, or a bid request log
+	Speed       float64       // speed-up of recorded arrivals; 0 keeps the recorded timing
+	Duration    time.Duration // arrival window; 0 replays a whole trace
+	MaxInFlight int           // auctions running at once, later arrivals queue; 0 is unlimited
+	WarmUp      float64       // leading fraction of Duration left out of steady-state metrics
+}
+
+// Streaming reports whether auctions arrive over time
+func (a *ArrivalConfig) Streaming() bool {
+	return a.Process != ""
+}
+
+// Recorded reports whether arrivals are replayed from TracePath
+func (a *ArrivalConfig) Recorded() bool {
+	return a.Process == ArrivalTrace || a.Process == ArrivalReplay
+}
+
+// DefaultArrivalConfig returns batch mode with streaming defaults ready to
+// be switched on by setting Process
+func DefaultArrivalConfig() ArrivalConfig {
+	return ArrivalConfig{
+		Rate:        20,
+		BurstRate:   100,
+		BurstEvery:  5 * time.Second,
+		BurstLength: time.Second,
+		Duration:    10 * time.Second,
+		WarmUp:      0.2,
+	}
+}
+
+// Concurrency limiter algorithms
+const (
+	LimiterFixed    = "fixed"    // constant limit of MaxConcurrentBidders
+	LimiterAIMD     = "aimd"     // additive increase, multiplicative decrease
+	LimiterGradient = "gradient" // shrink as latency inflates over its baseline
+)
+
+// LimiterConfig controls how many bidder requests run at once. Every
+// algorithm is capped at ResourceLimits.MaxConcurrentBidders.
+type LimiterConfig struct {
+	Algorithm        string
+	InitialLimit     int // 0 starts fixed limiters at the cap and adaptive ones at half of it
+	MinLimit         int
+	LatencyThreshold time.Duration // AIMD: slower responses count as overload; 0 disables
+	Backoff          float64       // AIMD: multiplicative decrease factor
+	Tolerance        float64       // gradient: allowed latency inflation
+	Smoothing        float64       // gradient: weight of each new limit estimate
+}
+
+// DefaultLimiterConfig returns a fixed limiter with sensible adaptive tuning
+func DefaultLimiterConfig() LimiterConfig {
+	return LimiterConfig{
+		Algorithm: LimiterFixed,
+		MinLimit:  1,
+		Backoff:   0.9,
+		Tolerance: 2,
+		Smoothing: 0.2,
+	}
+}
+
 // ResourceLimits holds the standardized resource constraints
 type ResourceLimits struct {
 	MaxVCPUs             int
 	MaxMemoryMB          int
 	MaxConcurrentBidders int
+	Container            ContainerLimits
 }
 
 // Config holds the complete simulation configuration
@@ -32,8 +158,224 @@ type Config struct {
 	TotalAuctions        int
 	TotalBidders         int
 	AttributesPerAuction int
+	Attributes           []AttributeSpec // schema of the items auctioned; empty uses AttributesPerAuction default attributes
+	Catalog              CatalogConfig
 	AuctionTimeout       time.Duration
 	ResourceLimits       ResourceLimits
+	Bidders              BidderConfig
+	Mechanism            string
+	TieBreak             string
+	Format               string
+	Open                 OpenConfig
+	Reserve              ReservePolicy
+	Limiter              LimiterConfig
+	Arrival              ArrivalConfig
+	Faults               FaultConfig
+	Validation           ValidationConfig
+	Remote               []RemoteBidder
+	Seed                 int64
+}
+
+// How auctions sample the items of a catalog
+const (
+	SampleSequential = "sequential" // each item in catalog order, then around again
+	SampleUniform    = "uniform"    // any item with units left, evenly
+	SampleStock      = "stock"      // items in proportion to their units left
+)
+
+// CatalogConfig auctions the items of a catalog file instead of items with
+// random attributes; an empty path disables it
+type CatalogConfig struct {
+	Path     string `json:"path"`
+	Sampling string `json:"sampling"` // one of the Sample constants; empty is sequential
+}
+
+// Policies for a bidder that bids more than once in an auction
+const (
+	DuplicateFirst   = "first"   // keep the first bid
+	DuplicateLast    = "last"    // keep the latest bid
+	DuplicateHighest = "highest" // keep the highest bid
+	DuplicateReject  = "reject"  // reject every bid of the bidder
+)
+
+// ValidationConfig sets the rules a bid must pass to take part in an auction
+type ValidationConfig struct {
+	MinBid     float64 `json:"min_bid"`    // 0 accepts any positive amount
+	MaxBid     float64 `json:"max_bid"`    // 0 is unbounded
+	Increment  float64 `json:"increment"`  // amounts must be whole multiples of it; 0 accepts any
+	Duplicates string  `json:"duplicates"` // duplicate bid policy
+}
+
+// DefaultValidationConfig accepts whole-cent bids and keeps a bidder's
+// first bid
+func DefaultValidationConfig() ValidationConfig {
+	return ValidationConfig{
+		Increment:  0.01,
+		Duplicates: DuplicateFirst,
+	}
+}
+
+// FaultConfig injects bidder faults for chaos testing. Rates are
+// probabilities per bid request; the zero value injects nothing.
+type FaultConfig struct {
+	LatencyRate   float64       `json:"latency_rate"`
+	LatencySpike  time.Duration `json:"latency_spike"` // delay added by a latency spike
+	ErrorRate     float64       `json:"error_rate"`
+	MalformedRate float64       `json:"malformed_rate"` // negative, non-finite or misaddressed bids
+	PanicRate     float64       `json:"panic_rate"`
+	OutageEvery   time.Duration `json:"outage_every"`  // each bidder goes down once per period; 0 disables outages
+	OutageLength  time.Duration `json:"outage_length"` // how long each outage lasts
+}
+
+// Enabled reports whether any fault is injected
+func (f *FaultConfig) Enabled() bool {
+	return f.LatencyRate > 0 || f.ErrorRate > 0 || f.MalformedRate > 0 || f.PanicRate > 0 ||
+		(f.OutageEvery > 0 && f.OutageLength > 0)
+}
+
+// ReservePolicy sets the minimum winning bid of each auction. The most
+// specific rule wins: a per-auction override, then the attribute segment,
+// then the flat price.
+type ReservePolicy struct {
+	Price            float64            `json:"price"`
+	ByAuction        map[string]float64 `json:"by_auction,omitempty"`
+	SegmentAttribute int                `json:"segment_attribute"`
+	SegmentBounds    []float64          `json:"segment_bounds,omitempty"` // ascending upper bounds of all but the last segment
+	SegmentPrices    []float64          `json:"segment_prices,omitempty"` // one reserve per segment, len(SegmentBounds)+1
+}
+
+// For returns the reserve price of an auction with the given attribute values
+func (p *ReservePolicy) For(auctionID string, attributes []float64) float64 {
+	if price, ok := p.ByAuction[auctionID]; ok {
+		return price
+	}
+	if len(p.SegmentPrices) > 0 && p.SegmentAttribute < len(attributes) {
+		return p.SegmentPrices[p.Segment(attributes[p.SegmentAttribute])]
+	}
+	return p.Price
+}
+
+// Segment returns the index of the segment containing an attribute value
+func (p *ReservePolicy) Segment(value float64) int {
+	for i, bound := range p.SegmentBounds {
+		if value < bound {
+			return i
+		}
+	}
+	return len(p.SegmentBounds)
+}
+
+// BidderConfig holds configuration for bidder behavior
+type BidderConfig struct {
+	MinBidChance float64       `json:"min_bid_chance"`
+	MaxBidChance float64       `json:"max_bid_chance"`
+	MinBaseBid   float64       `json:"min_base_bid"`
+	MaxBaseBid   float64       `json:"max_base_bid"`
+	MinSpeedMS   int           `json:"min_speed_ms"`
+	MaxSpeedMS   int           `json:"max_speed_ms"`
+	Budget       float64       `json:"budget"`        // what each bidder may spend over a run; 0 is unlimited
+	SniperShare  float64       `json:"sniper_share"`  // share of bidders that bid at the last moment of open auctions
+	LearnerShare float64       `json:"learner_share"` // share of bidders that learn how far to shade their bids
+	Learning     string        `json:"learning"`      // how learners choose a shade, one of the Learn constants
+	Groups       []GroupConfig `json:"groups,omitempty"`
+
+	// Population draws bidder parameters from distributions; nil keeps the
+	// uniform ranges above
+	Population *PopulationConfig `json:"population,omitempty"`
+}
+
+// Kinds of bidder groups that act together
+const (
+	GroupRotation    = "rotation"    // a cartel whose members take turns to win
+	GroupSuppression = "suppression" // a ring whose members stand aside for its leader
+	GroupShill       = "shill"       // seller-side bidders that push prices up without wanting to win
+)
+
+// Group strategies. Rotation cartels and suppression rings use cover or
+// abstain for members standing aside; shills are aggressive or cautious.
+const (
+	StrategyCover      = "cover"      // place a low bid that is meant to lose
+	StrategyAbstain    = "abstain"    // do not bid
+	StrategyAggressive = "aggressive" // bid close to the highest values in the population
+	StrategyCautious   = "cautious"   // bid lower and win less often
+)
+
+// GroupConfig adds a group of Size bidders acting together
+type GroupConfig struct {
+	Kind     string `json:"kind"`
+	Size     int    `json:"size"`
+	Strategy string `json:"strategy"`
+}
+
+// RemoteBidder is a bidder served over HTTP that takes part alongside the
+// simulated ones
+type RemoteBidder struct {
+	ID       string `json:"id"`
+	Endpoint string `json:"endpoint"` // receives bid requests as JSON POSTs
+}
+
+// Strategies learning bidders use to choose how far to shade their bids
+const (
+	LearnEpsilonGreedy = "epsilon-greedy" // the best shade so far, or a random one a tenth of the time
+	LearnUCB           = "ucb"            // the shade with the highest upper confidence bound
+	LearnThompson      = "thompson"       // Thompson sampling from each shade's posterior
+)
+
+// Validate checks that the bidder ranges are ordered and the shares are
+// fractions
+func (b *BidderConfig) Validate() error {
+	switch {
+	case b.MinBidChance < 0 || b.MaxBidChance > 1 || b.MaxBidChance < b.MinBidChance:
+		return fmt.Errorf("bid chance range [%g, %g] must be ordered within [0, 1]", b.MinBidChance, b.MaxBidChance)
+	case b.MinBaseBid < 0 || b.MaxBaseBid < b.MinBaseBid:
+		return fmt.Errorf("base bid range [%g, %g] must be ordered and non-negative", b.MinBaseBid, b.MaxBaseBid)
+	case b.MinSpeedMS < 0 || b.MaxSpeedMS < b.MinSpeedMS:
+		return fmt.Errorf("speed range [%d, %d] ms must be ordered and non-negative", b.MinSpeedMS, b.MaxSpeedMS)
+	case b.Budget < 0:
+		return fmt.Errorf("negative budget %g", b.Budget)
+	case b.SniperShare < 0 || b.SniperShare > 1:
+		return fmt.Errorf("sniper share %g outside [0, 1]", b.SniperShare)
+	case b.LearnerShare < 0 || b.LearnerShare > 1:
+		return fmt.Errorf("learner share %g outside [0, 1]", b.LearnerShare)
+	}
+	if b.Population != nil {
+		if err := b.Population.Validate(); err != nil {
+			return fmt.Errorf("bidder population: %w", err)
+		}
+	}
+	return nil
+}
+
+// Validate checks the fields a run cannot start without, such as a
+// parameter sweep may have set
+func (c *Config) Validate() error {
+	switch {
+	case c.TotalBidders < 1:
+		return fmt.Errorf("need at least one bidder, got %d", c.TotalBidders)
+	case c.TotalAuctions < 1 && !c.Arrival.Streaming():
+		return fmt.Errorf("need at least one auction, got %d", c.TotalAuctions)
+	case c.AuctionTimeout <= 0:
+		return fmt.Errorf("auction timeout %v must be positive", c.AuctionTimeout)
+	case c.ResourceLimits.MaxConcurrentBidders < 1:
+		return fmt.Errorf("need at least one concurrent bidder, got %d", c.ResourceLimits.MaxConcurrentBidders)
+	}
+	if err := c.Bidders.Validate(); err != nil {
+		return fmt.Errorf("bidders: %w", err)
+	}
+	return nil
+}
+
+// DefaultBidderConfig returns defaults for bidder behavior
+func DefaultBidderConfig() *BidderConfig {
+	return &BidderConfig{
+		MinBidChance: 0.6,
+		MaxBidChance: 0.8,
+		MinBaseBid:   50.0,
+		MaxBaseBid:   150.0,
+		MinSpeedMS:   5,
+		MaxSpeedMS:   250,
+		Learning:     LearnEpsilonGreedy,
+	}
 }
 
 // DefaultConfig returns the default configuration with resource standardization
@@ -46,24 +388,47 @@ func DefaultConfig() *Config {
 		AttributesPerAuction: AttributesPerAuction,
 		AuctionTimeout:       DefaultTimeout,
 		ResourceLimits:       limits,
+		Bidders:              *DefaultBidderConfig(),
+		Mechanism:            FirstPrice,
+		TieBreak:             TieEarliest,
+		Format:               FormatSealed,
+		Open:                 DefaultOpenConfig(),
+		Limiter:              DefaultLimiterConfig(),
+		Arrival:              DefaultArrivalConfig(),
+		Validation:           DefaultValidationConfig(),
+		Seed:                 time.Now().UnixNano(),
 	}
 }
 
-// CalculateResourceLimits determines optimal resource constraints
+// CalculateResourceLimits determines optimal resource constraints, honoring
+// the CPU quota and memory limit of the surrounding container
 func CalculateResourceLimits() ResourceLimits {
+	container := DetectContainerLimits()
+
 	availableCPUs := runtime.NumCPU()
+	if container.CPUQuota > 0 {
+		availableCPUs = min(availableCPUs, max(1, int(math.Ceil(container.CPUQuota))))
+	}
 	if availableCPUs > DefaultMaxVCPUs {
 		availableCPUs = DefaultMaxVCPUs
 	}
 
-	maxConcurrent := availableCPUs * MaxConcurrentBiddersPerCPU
-	if maxConcurrent > TotalBidders*TotalAuctions {
-		maxConcurrent = TotalBidders * TotalAuctions
+	memoryMB := DefaultMaxMemoryMB
+	if container.MemoryBytes > 0 {
+		memoryMB = min(memoryMB, int(float64(container.MemoryMB())*ContainerMemoryShare))
 	}
 
 	return ResourceLimits{
 		MaxVCPUs:             availableCPUs,
-		MaxMemoryMB:          DefaultMaxMemoryMB,
-		MaxConcurrentBidders: maxConcurrent,
+		MaxMemoryMB:          memoryMB,
+		MaxConcurrentBidders: ConcurrentBidderLimit(availableCPUs, TotalBidders, TotalAuctions),
+		Container:            container,
 	}
 }
+
+// ConcurrentBidderLimit is the number of bidders evaluated at once for a
+// run on vcpus CPUs: MaxConcurrentBiddersPerCPU each, but no more than
+// there are bidder evaluations in the run
+func ConcurrentBidderLimit(vcpus, bidders, auctions int) int {
+	return max(1, min(vcpus*MaxConcurrentBiddersPerCPU, bidders*auctions))
+}
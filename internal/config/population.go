@@ -0,0 +1,168 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"sort"
+)
+
+// Bidder parameters a population can draw from distributions
+const (
+	ParamBidChance = "bid_chance"
+	ParamBaseBid   = "base_bid"
+	ParamBidRange  = "bid_range"
+	ParamSpeed     = "speed_ms"
+)
+
+// PopulationParams lists the bidder parameters in the order they are drawn
+var PopulationParams = []string{ParamBidChance, ParamBaseBid, ParamBidRange, ParamSpeed}
+
+// Distributions of bidder parameters, in addition to those of attributes
+const (
+	DistBeta      = "beta"      // Alpha and Beta, stretched over [Min, Max]
+	DistEmpirical = "empirical" // a histogram of Counts between ascending Bins edges
+	DistMixture   = "mixture"   // one of Components, picked by Weights
+)
+
+// DistributionSpec describes the distribution of a bidder parameter. Min
+// and Max bound every kind; for uniform and beta they are the support, for
+// the others values outside are clamped, and Max <= Min leaves them
+// unbounded.
+type DistributionSpec struct {
+	Kind       string             `json:"kind"`
+	Min        float64            `json:"min,omitempty"`
+	Max        float64            `json:"max,omitempty"`
+	Mean       float64            `json:"mean,omitempty"`   // normal; of the logarithm for lognormal
+	StdDev     float64            `json:"stddev,omitempty"` // normal; of the logarithm for lognormal
+	Alpha      float64            `json:"alpha,omitempty"`
+	Beta       float64            `json:"beta,omitempty"`
+	Bins       []float64          `json:"bins,omitempty"`
+	Counts     []float64          `json:"counts,omitempty"` // one per bin, len(Bins)-1
+	Components []DistributionSpec `json:"components,omitempty"`
+	Weights    []float64          `json:"weights,omitempty"` // relative weight of each component
+}
+
+// Correlation ties two bidder parameters together, such as fast bidders
+// bidding higher: a negative Rho between speed_ms and base_bid. Rho is the
+// correlation of the parameters' normal scores, close to their rank
+// correlation.
+type Correlation struct {
+	A   string  `json:"a"`
+	B   string  `json:"b"`
+	Rho float64 `json:"rho"`
+}
+
+// PopulationConfig draws bidder parameters from distributions instead of
+// the uniform ranges of BidderConfig. Parameters without a distribution
+// keep their range.
+type PopulationConfig struct {
+	Params       map[string]DistributionSpec `json:"params"`
+	Correlations []Correlation               `json:"correlations,omitempty"`
+}
+
+// Validate checks that a distribution can be sampled
+func (d *DistributionSpec) Validate() error {
+	switch d.Kind {
+	case DistUniform, DistBeta:
+		if d.Max <= d.Min {
+			return fmt.Errorf("%s distribution: max %g must be above min %g", d.Kind, d.Max, d.Min)
+		}
+		if d.Kind == DistBeta && (d.Alpha <= 0 || d.Beta <= 0) {
+			return fmt.Errorf("beta distribution needs positive alpha and beta")
+		}
+	case DistNormal, DistLogNormal:
+		if d.StdDev <= 0 {
+			return fmt.Errorf("%s distribution needs a positive stddev", d.Kind)
+		}
+	case DistEmpirical:
+		if len(d.Bins) < 2 || len(d.Counts) != len(d.Bins)-1 {
+			return fmt.Errorf("empirical distribution needs bin edges and a count for each bin")
+		}
+		if !sort.Float64sAreSorted(d.Bins) || d.Bins[0] == d.Bins[len(d.Bins)-1] {
+			return fmt.Errorf("empirical distribution bins must ascend")
+		}
+		if err := checkWeights(d.Counts); err != nil {
+			return fmt.Errorf("empirical distribution counts: %w", err)
+		}
+	case DistMixture:
+		if len(d.Components) == 0 || len(d.Weights) != len(d.Components) {
+			return fmt.Errorf("mixture distribution needs components and a weight for each")
+		}
+		if err := checkWeights(d.Weights); err != nil {
+			return fmt.Errorf("mixture distribution weights: %w", err)
+		}
+		for i := range d.Components {
+			if err := d.Components[i].Validate(); err != nil {
+				return fmt.Errorf("mixture component %d: %w", i+1, err)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown distribution %q", d.Kind)
+	}
+	return nil
+}
+
+// checkWeights checks that weights are non-negative and not all zero
+func checkWeights(weights []float64) error {
+	total := 0.0
+	for _, w := range weights {
+		if w < 0 || math.IsNaN(w) {
+			return fmt.Errorf("negative weight %g", w)
+		}
+		total += w
+	}
+	if total <= 0 {
+		return fmt.Errorf("weights must not all be zero")
+	}
+	return nil
+}
+
+// Validate checks every distribution and that correlations are between
+// distinct parameters that have distributions
+func (p *PopulationConfig) Validate() error {
+	if len(p.Params) == 0 {
+		return fmt.Errorf("population has no parameter distributions")
+	}
+	for _, name := range slices.Sorted(maps.Keys(p.Params)) {
+		if !slices.Contains(PopulationParams, name) {
+			return fmt.Errorf("unknown bidder parameter %q", name)
+		}
+		spec := p.Params[name]
+		if err := spec.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	for _, c := range p.Correlations {
+		_, okA := p.Params[c.A]
+		_, okB := p.Params[c.B]
+		switch {
+		case !okA || !okB:
+			return fmt.Errorf("correlation %s/%s: both parameters need a distribution", c.A, c.B)
+		case c.A == c.B:
+			return fmt.Errorf("correlation of %s with itself", c.A)
+		case c.Rho <= -1 || c.Rho >= 1:
+			return fmt.Errorf("correlation %s/%s: rho %g outside (-1, 1)", c.A, c.B, c.Rho)
+		}
+	}
+	return nil
+}
+
+// LoadPopulation reads a population JSON file, as fit-population writes
+func LoadPopulation(path string) (*PopulationConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read bidder population: %w", err)
+	}
+	var population PopulationConfig
+	if err := json.Unmarshal(data, &population); err != nil {
+		return nil, fmt.Errorf("could not parse bidder population: %w", err)
+	}
+	if err := population.Validate(); err != nil {
+		return nil, fmt.Errorf("bidder population %s: %w", path, err)
+	}
+	return &population, nil
+}
@@ -0,0 +1,255 @@
+package fault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/types"
+	"auction-simulator/pkg/utils"
+)
+
+// Injected fault kinds
+const (
+	Latency   = "latency"
+	Error     = "error"
+	Malformed = "malformed"
+	Panic     = "panic"
+	Outage    = "outage"
+)
+
+// Kinds lists the injected fault kinds in report order
+var Kinds = []string{Latency, Error, Malformed, Panic, Outage}
+
+// ErrInjected is returned by a bidder with an injected error
+var ErrInjected = errors.New("injected bidder error")
+
+// ErrUnavailable is returned by a bidder during a scheduled outage
+var ErrUnavailable = errors.New("bidder unavailable")
+
+// Chaos is a preset that injects every kind of fault
+var Chaos = config.FaultConfig{
+	LatencyRate:   0.05,
+	LatencySpike:  time.Second,
+	ErrorRate:     0.03,
+	MalformedRate: 0.03,
+	PanicRate:     0.01,
+	OutageEvery:   4 * time.Second,
+	OutageLength:  500 * time.Millisecond,
+}
+
+// Counts tallies injected faults by kind; it is safe for concurrent use
+type Counts struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// NewCounts creates an empty tally
+func NewCounts() *Counts {
+	return &Counts{counts: make(map[string]int)}
+}
+
+// add records one injected fault
+func (c *Counts) add(kind string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[kind]++
+}
+
+// Snapshot returns a copy of the tally
+func (c *Counts) Snapshot() map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	snapshot := make(map[string]int, len(c.counts))
+	for kind, n := range c.counts {
+		snapshot[kind] = n
+	}
+	return snapshot
+}
+
+// Injector wraps a bidder and makes it misbehave as configured. Faults are
+// drawn from a stream per bidder and auction, so a seed reproduces them.
+type Injector struct {
+	bidder   types.Bidder
+	bidderID string
+	cfg      config.FaultConfig
+	seed     int64
+	counts   *Counts
+	start    time.Time
+	phase    time.Duration // offset of this bidder's outage schedule
+}
+
+// Wrap returns bidder with faults injected according to cfg, tallied in
+// counts. Outages follow a schedule that starts now, shifted per bidder so
+// they do not all go down at once.
+func Wrap(bidder types.Bidder, bidderID string, cfg config.FaultConfig, seed int64, counts *Counts) *Injector {
+	seed = utils.DeriveSeed(seed, "faults", bidderID)
+	injector := &Injector{
+		bidder:   bidder,
+		bidderID: bidderID,
+		cfg:      cfg,
+		seed:     seed,
+		counts:   counts,
+		start:    time.Now(),
+	}
+	if cfg.OutageEvery > 0 {
+		injector.phase = time.Duration(utils.NewRNG(seed).Float64() * float64(cfg.OutageEvery))
+	}
+	return injector
+}
+
+// EvaluateBid implements types.Bidder
+func (i *Injector) EvaluateBid(ctx context.Context, request *types.BidRequest) (*types.BidResponse, error) {
+	if i.down(time.Now()) {
+		i.counts.add(Outage)
+		return nil, fmt.Errorf("%s: %w", i.bidderID, ErrUnavailable)
+	}
+
+	rng := utils.NewRNG(utils.DeriveSeed(i.seed, request.AuctionID))
+	if rng.RandomChance(i.cfg.PanicRate) {
+		i.counts.add(Panic)
+		panic(fmt.Sprintf("injected panic in bidder %s", i.bidderID))
+	}
+	if rng.RandomChance(i.cfg.ErrorRate) {
+		i.counts.add(Error)
+		return nil, fmt.Errorf("%s: %w", i.bidderID, ErrInjected)
+	}
+	if rng.RandomChance(i.cfg.LatencyRate) {
+		i.counts.add(Latency)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(i.cfg.LatencySpike):
+		}
+	}
+	malformed := rng.RandomChance(i.cfg.MalformedRate)
+	corruption := rng.Intn(3)
+
+	response, err := i.bidder.EvaluateBid(ctx, request)
+	if err != nil || response == nil || response.Withdrawn || !malformed {
+		return response, err
+	}
+
+	i.counts.add(Malformed)
+	corrupted := *response
+	switch corruption {
+	case 0:
+		corrupted.Amount = -corrupted.Amount
+	case 1:
+		corrupted.Amount = math.NaN()
+	default:
+		corrupted.AuctionID = request.AuctionID + "-stale"
+	}
+	return &corrupted, nil
+}
+
+// Unwrap implements types.Wrapper. Notices are not subject to faults, so
+// they go straight to the wrapped bidder, and only if it listens for them.
+func (i *Injector) Unwrap() types.Bidder {
+	return i.bidder
+}
+
+// down reports whether the bidder is in a scheduled outage at now
+func (i *Injector) down(now time.Time) bool {
+	if i.cfg.OutageEvery <= 0 || i.cfg.OutageLength <= 0 {
+		return false
+	}
+	offset := (now.Sub(i.start) + i.phase) % i.cfg.OutageEvery
+	return offset < i.cfg.OutageLength
+}
+
+// Parse reads a fault spec: "chaos", or comma-separated kind=value pairs
+// such as "latency=0.05:1s,error=0.02,malformed=0.02,panic=0.01,outage=5s:500ms".
+// Latency takes a rate and spike duration, outage a period and length.
+func Parse(spec string) (config.FaultConfig, error) {
+	var cfg config.FaultConfig
+	if spec == "" {
+		return cfg, nil
+	}
+	if spec == "chaos" {
+		return Chaos, nil
+	}
+
+	for _, part := range strings.Split(spec, ",") {
+		kind, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return cfg, fmt.Errorf("fault %q: want kind=value", part)
+		}
+		first, second, _ := strings.Cut(value, ":")
+
+		var err error
+		switch kind {
+		case Latency:
+			cfg.LatencyRate, err = parseRate(first)
+			cfg.LatencySpike = time.Second
+			if err == nil && second != "" {
+				cfg.LatencySpike, err = time.ParseDuration(second)
+			}
+		case Error:
+			cfg.ErrorRate, err = parseRate(value)
+		case Malformed:
+			cfg.MalformedRate, err = parseRate(value)
+		case Panic:
+			cfg.PanicRate, err = parseRate(value)
+		case Outage:
+			cfg.OutageEvery, err = time.ParseDuration(first)
+			if err == nil {
+				cfg.OutageLength, err = time.ParseDuration(second)
+			}
+			if err == nil && cfg.OutageLength >= cfg.OutageEvery {
+				err = fmt.Errorf("outage length must be shorter than its period")
+			}
+		default:
+			return cfg, fmt.Errorf("unknown fault kind %q", kind)
+		}
+		if err != nil {
+			return cfg, fmt.Errorf("fault %s: %w", kind, err)
+		}
+	}
+	return cfg, nil
+}
+
+// parseRate reads a probability
+func parseRate(value string) (float64, error) {
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if rate < 0 || rate > 1 {
+		return 0, fmt.Errorf("rate %v is not between 0 and 1", rate)
+	}
+	return rate, nil
+}
+
+// Describe prints a fault config as the spec Parse reads
+func Describe(cfg config.FaultConfig) string {
+	var parts []string
+	if cfg.LatencyRate > 0 {
+		parts = append(parts, fmt.Sprintf("%s=%g:%v", Latency, cfg.LatencyRate, cfg.LatencySpike))
+	}
+	if cfg.ErrorRate > 0 {
+		parts = append(parts, fmt.Sprintf("%s=%g", Error, cfg.ErrorRate))
+	}
+	if cfg.MalformedRate > 0 {
+		parts = append(parts, fmt.Sprintf("%s=%g", Malformed, cfg.MalformedRate))
+	}
+	if cfg.PanicRate > 0 {
+		parts = append(parts, fmt.Sprintf("%s=%g", Panic, cfg.PanicRate))
+	}
+	if cfg.OutageEvery > 0 && cfg.OutageLength > 0 {
+		parts = append(parts, fmt.Sprintf("%s=%v:%v", Outage, cfg.OutageEvery, cfg.OutageLength))
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
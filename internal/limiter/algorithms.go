@@ -0,0 +1,101 @@
+package limiter
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"auction-simulator/internal/config"
+)
+
+// AIMD grows the limit by one per window of successful requests and cuts it
+// multiplicatively when a request is dropped or slower than LatencyThreshold
+type AIMD struct {
+	Backoff          float64       // factor applied on overload, e.g. 0.9
+	LatencyThreshold time.Duration // 0 only treats drops as overload
+
+	lastDecrease time.Time
+}
+
+// Update implements Algorithm
+func (a *AIMD) Update(limit float64, rtt time.Duration, inflight int, outcome Outcome) float64 {
+	overloaded := outcome == Dropped || (a.LatencyThreshold > 0 && rtt > a.LatencyThreshold)
+	if overloaded {
+		// A burst of timeouts reflects one overload event; back off at most
+		// once per round trip instead of once per request
+		now := time.Now()
+		if now.Sub(a.lastDecrease) < rtt {
+			return limit
+		}
+		a.lastDecrease = now
+		return limit * a.Backoff
+	}
+
+	// Only grow when the limit is actually being used
+	if float64(inflight)*2 >= limit {
+		return limit + 1/limit
+	}
+	return limit
+}
+
+// Gradient compares short-term latency with a long-term baseline and
+// shrinks the limit as latency inflates beyond Tolerance, leaving sqrt(limit)
+// of queueing headroom so the limit can grow while latency is flat
+type Gradient struct {
+	Tolerance float64 // allowed ratio of short to long latency, e.g. 1.5
+	Smoothing float64 // weight of each new estimate, e.g. 0.2
+
+	shortRTT float64 // seconds, fast-moving average
+	longRTT  float64 // seconds, slow-moving baseline
+}
+
+// Update implements Algorithm
+func (g *Gradient) Update(limit float64, rtt time.Duration, inflight int, outcome Outcome) float64 {
+	sample := rtt.Seconds()
+	if g.longRTT == 0 {
+		g.shortRTT, g.longRTT = sample, sample
+	}
+	g.shortRTT = 0.9*g.shortRTT + 0.1*sample
+	g.longRTT = 0.995*g.longRTT + 0.005*sample
+
+	// Let the baseline recover quickly once an overload has passed
+	if g.longRTT > 2*g.shortRTT {
+		g.longRTT *= 0.95
+	}
+
+	gradient := 0.5
+	if outcome != Dropped && g.shortRTT > 0 {
+		gradient = math.Max(0.5, math.Min(1, g.Tolerance*g.longRTT/g.shortRTT))
+	}
+
+	// Without demand the limit would drift up unboundedly
+	if float64(inflight)*2 < limit {
+		return limit
+	}
+
+	target := limit*gradient + math.Sqrt(limit)
+	return limit*(1-g.Smoothing) + target*g.Smoothing
+}
+
+// FromConfig builds the limiter described by cfg with hi as its ceiling
+func FromConfig(cfg config.LimiterConfig, hi int) (*Limiter, error) {
+	var algorithm Algorithm
+	initial := hi
+
+	switch cfg.Algorithm {
+	case config.LimiterFixed, "":
+	case config.LimiterAIMD:
+		algorithm = &AIMD{Backoff: cfg.Backoff, LatencyThreshold: cfg.LatencyThreshold}
+		initial = hi / 2
+	case config.LimiterGradient:
+		algorithm = &Gradient{Tolerance: cfg.Tolerance, Smoothing: cfg.Smoothing}
+		initial = hi / 2
+	default:
+		return nil, fmt.Errorf("unknown concurrency limiter: %s", cfg.Algorithm)
+	}
+
+	if cfg.InitialLimit > 0 {
+		initial = cfg.InitialLimit
+	}
+	return New(algorithm, initial, cfg.MinLimit, hi), nil
+}
@@ -0,0 +1,212 @@
+package limiter
+
+import (
+	"container/list"
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Outcome classifies a finished request for the limit algorithm
+type Outcome int
+
+const (
+	// Success means the request finished in time, with or without a bid
+	Success Outcome = iota
+	// Dropped means the request timed out or failed, a sign of overload
+	Dropped
+	// Ignored means the request was cancelled for reasons unrelated to load
+	Ignored
+)
+
+// Algorithm adjusts the concurrency limit from request samples
+type Algorithm interface {
+	// Update returns the new limit after a request that took rtt while
+	// inflight requests were running
+	Update(limit float64, rtt time.Duration, inflight int, outcome Outcome) float64
+}
+
+// Limiter bounds the number of concurrent requests and shares the slots
+// fairly between keys, so one busy key cannot starve the others. Waiting keys
+// are served round-robin and a key that already holds its fair share of the
+// limit yields to keys below theirs.
+type Limiter struct {
+	mu        sync.Mutex
+	algorithm Algorithm
+	limit     float64
+	min, max  float64
+	inflight  int
+	perKey    map[string]int
+	waiting   map[string]*list.List // key -> FIFO of waiter channels
+	order     *list.List            // round-robin order of keys with waiters
+	positions map[string]*list.Element
+	peak      float64
+	floor     float64
+}
+
+// New creates a limiter that starts at initial and stays within [lo, hi];
+// out-of-range bounds are clamped. A nil algorithm keeps the limit fixed.
+func New(algorithm Algorithm, initial, lo, hi int) *Limiter {
+	lo = max(1, lo)
+	hi = max(lo, hi)
+	initial = max(lo, min(initial, hi))
+
+	return &Limiter{
+		algorithm: algorithm,
+		limit:     float64(initial),
+		min:       float64(lo),
+		max:       float64(hi),
+		perKey:    make(map[string]int),
+		waiting:   make(map[string]*list.List),
+		order:     list.New(),
+		positions: make(map[string]*list.Element),
+		peak:      float64(initial),
+		floor:     float64(initial),
+	}
+}
+
+// Permit is a granted slot; Release must be called exactly once
+type Permit struct {
+	limiter *Limiter
+	key     string
+	start   time.Time
+}
+
+// Acquire waits for a slot for key or until ctx is done
+func (l *Limiter) Acquire(ctx context.Context, key string) (*Permit, error) {
+	l.mu.Lock()
+	if l.inflight < int(l.limit) && l.order.Len() == 0 {
+		l.grant(key)
+		l.mu.Unlock()
+		return &Permit{limiter: l, key: key, start: time.Now()}, nil
+	}
+
+	ready := make(chan struct{})
+	queue, ok := l.waiting[key]
+	if !ok {
+		queue = list.New()
+		l.waiting[key] = queue
+		l.positions[key] = l.order.PushBack(key)
+	}
+	elem := queue.PushBack(ready)
+	l.wake()
+	l.mu.Unlock()
+
+	select {
+	case <-ready:
+		return &Permit{limiter: l, key: key, start: time.Now()}, nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		select {
+		case <-ready:
+			// Granted while cancelling; hand the slot on
+			l.release(key)
+		default:
+			queue.Remove(elem)
+			l.dropKeyIfIdle(key)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// Release returns the slot and feeds the request outcome to the algorithm
+func (p *Permit) Release(outcome Outcome) {
+	rtt := time.Since(p.start)
+	l := p.limiter
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.algorithm != nil && outcome != Ignored {
+		limit := l.algorithm.Update(l.limit, rtt, l.inflight, outcome)
+		l.limit = math.Max(l.min, math.Min(l.max, limit))
+		l.peak = math.Max(l.peak, l.limit)
+		l.floor = math.Min(l.floor, l.limit)
+	}
+	l.release(p.key)
+}
+
+// Limit returns the current concurrency limit
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+// Range returns the lowest and highest limit reached so far
+func (l *Limiter) Range() (low, high int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.floor), int(l.peak)
+}
+
+// fairShare is the slot count each active key may hold while others wait
+func (l *Limiter) fairShare() int {
+	active := len(l.waiting)
+	for key := range l.perKey {
+		if _, ok := l.waiting[key]; !ok {
+			active++
+		}
+	}
+	if active == 0 {
+		return int(l.limit)
+	}
+	return max(1, int(math.Ceil(l.limit/float64(active))))
+}
+
+// grant records a slot taken by key
+func (l *Limiter) grant(key string) {
+	l.inflight++
+	l.perKey[key]++
+}
+
+// release frees a slot of key and wakes waiters in round-robin order
+func (l *Limiter) release(key string) {
+	l.inflight--
+	if l.perKey[key]--; l.perKey[key] == 0 {
+		delete(l.perKey, key)
+	}
+	l.wake()
+}
+
+// wake grants free slots to waiting keys. Keys under their fair share go
+// first; if none are, the slot goes to the next key in rotation so the
+// limit is never left unused.
+func (l *Limiter) wake() {
+	for l.inflight < int(l.limit) && l.order.Len() > 0 {
+		elem := l.nextWaiter()
+		key := elem.Value.(string)
+
+		queue := l.waiting[key]
+		ready := queue.Remove(queue.Front()).(chan struct{})
+		l.grant(key)
+		close(ready)
+
+		// Rotate the key to the back so the next slot goes elsewhere
+		l.order.MoveToBack(elem)
+		l.dropKeyIfIdle(key)
+	}
+}
+
+// nextWaiter returns the first key in rotation below its fair share, or the
+// front key when every waiting key is at or above it
+func (l *Limiter) nextWaiter() *list.Element {
+	share := l.fairShare()
+	for elem := l.order.Front(); elem != nil; elem = elem.Next() {
+		if l.perKey[elem.Value.(string)] < share {
+			return elem
+		}
+	}
+	return l.order.Front()
+}
+
+// dropKeyIfIdle forgets a key's queue once nothing waits on it
+func (l *Limiter) dropKeyIfIdle(key string) {
+	if queue, ok := l.waiting[key]; ok && queue.Len() == 0 {
+		delete(l.waiting, key)
+		l.order.Remove(l.positions[key])
+		delete(l.positions, key)
+	}
+}
@@ -0,0 +1,165 @@
+package metrics
+
+import (
+	"math"
+
+	"auction-simulator/internal/stats"
+	"auction-simulator/internal/types"
+)
+
+// HeadlineMetrics lists, in report order, the per-run metrics that batch
+// and sweep summaries aggregate
+var HeadlineMetrics = []string{
+	"total_revenue",
+	"revenue_per_auction",
+	"success_rate",
+	"bids_per_auction",
+	"latency_p50_ms",
+	"latency_p90_ms",
+	"latency_p99_ms",
+	"duration_s",
+	"memory_mb",
+	"max_goroutines",
+}
+
+// ProcessMetrics lists the headline metrics sampled from the whole process
+// rather than one run. They describe a run only if it had the process to
+// itself, so batches of parallel runs leave them out.
+var ProcessMetrics = []string{"memory_mb", "max_goroutines"}
+
+// MetricSummary aggregates one metric across several runs
+type MetricSummary struct {
+	Name   string         `json:"name"`
+	Mean   float64        `json:"mean"`
+	StdDev float64        `json:"std_dev"`
+	CI     stats.Interval `json:"ci"`
+	Min    float64        `json:"min"`
+	Max    float64        `json:"max"`
+}
+
+// BatchSummary is the aggregate of repeated runs of the same configuration
+type BatchSummary struct {
+	BatchID    string               `json:"batch_id"`
+	BaseSeed   int64                `json:"base_seed"`
+	Runs       int                  `json:"runs"`
+	Confidence float64              `json:"confidence"`
+	Metrics    []MetricSummary      `json:"metrics"`
+	RunMetrics []*SimulationMetrics `json:"run_metrics"`
+	Incomplete bool                 `json:"incomplete,omitempty"` // interrupted before every run finished
+}
+
+// Headline returns the values of HeadlineMetrics for a single run
+func (m *SimulationMetrics) Headline() map[string]float64 {
+	values := map[string]float64{
+		"total_revenue":    m.TotalRevenue,
+		"bids_per_auction": m.AverageBidsPerAuction,
+		"latency_p50_ms":   m.LatencyP50MS,
+		"latency_p90_ms":   m.LatencyP90MS,
+		"latency_p99_ms":   m.LatencyP99MS,
+		"duration_s":       m.TotalDuration.Seconds(),
+		"memory_mb":        m.MemoryUsageMB,
+		"max_goroutines":   float64(m.MaxGoroutines),
+	}
+	if m.TotalAuctions > 0 {
+		values["revenue_per_auction"] = m.TotalRevenue / float64(m.TotalAuctions)
+		values["success_rate"] = float64(m.SuccessfulAuctions) / float64(m.TotalAuctions)
+	}
+	return values
+}
+
+// Summarize computes mean, spread and a confidence interval of every
+// headline metric across runs
+func Summarize(runs []*SimulationMetrics, confidence float64) []MetricSummary {
+	samples := make(map[string][]float64, len(HeadlineMetrics))
+	for _, run := range runs {
+		for name, value := range run.Headline() {
+			samples[name] = append(samples[name], value)
+		}
+	}
+
+	summaries := make([]MetricSummary, 0, len(HeadlineMetrics))
+	for _, name := range HeadlineMetrics {
+		sample := samples[name]
+		summary := MetricSummary{
+			Name:   name,
+			Mean:   stats.Mean(sample),
+			StdDev: stats.StdDev(sample),
+			CI:     stats.MeanCI(sample, confidence),
+			Min:    math.Inf(1),
+			Max:    math.Inf(-1),
+		}
+		for _, v := range sample {
+			summary.Min = math.Min(summary.Min, v)
+			summary.Max = math.Max(summary.Max, v)
+		}
+		if len(sample) == 0 {
+			summary.Min, summary.Max = 0, 0
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+// MergeWorkers combines the metrics of workers that each ran part of one
+// run. Auction statistics are recomputed from the combined results; resource
+// usage and concurrency limits add up, since the workers run side by side.
+func MergeWorkers(parts []*SimulationMetrics, results []*types.AuctionResult) *SimulationMetrics {
+	merged := &SimulationMetrics{Workers: len(parts)}
+	shadeCounts := make(map[string]int)
+	for _, part := range parts {
+		if merged.StartTime.IsZero() || part.StartTime.Before(merged.StartTime) {
+			merged.StartTime = part.StartTime
+		}
+		if part.EndTime.After(merged.EndTime) {
+			merged.EndTime = part.EndTime
+		}
+		merged.TotalBidders = part.TotalBidders
+		merged.Groups = part.Groups // every worker draws the same groups
+		merged.MaxGoroutines += part.MaxGoroutines
+		merged.MemoryUsageMB += part.MemoryUsageMB
+		merged.ConcurrencyLimitMin += part.ConcurrencyLimitMin
+		merged.ConcurrencyLimitMax += part.ConcurrencyLimitMax
+		merged.ConcurrencyLimitFinal += part.ConcurrencyLimitFinal
+		for kind, n := range part.FaultsInjected {
+			if merged.FaultsInjected == nil {
+				merged.FaultsInjected = make(map[string]int)
+			}
+			merged.FaultsInjected[kind] += n
+		}
+		for kind, n := range part.Notices {
+			if merged.Notices == nil {
+				merged.Notices = make(map[string]int)
+			}
+			merged.Notices[kind] += n
+		}
+		merged.NoticeFailures += part.NoticeFailures
+		// Each worker's bidders learn from that worker's auctions only
+		for strategy, shade := range part.LearnedShades {
+			if merged.LearnedShades == nil {
+				merged.LearnedShades = make(map[string]float64)
+			}
+			merged.LearnedShades[strategy] += shade
+			shadeCounts[strategy]++
+		}
+		if part.Incomplete && !merged.Incomplete {
+			merged.Incomplete = true
+			merged.Failure = part.Failure
+		}
+	}
+
+	for strategy, n := range shadeCounts {
+		merged.LearnedShades[strategy] /= float64(n)
+	}
+
+	merged.TotalDuration = merged.EndTime.Sub(merged.StartTime)
+	merged.ApplyResults(results)
+	if len(parts) > 0 && parts[0].Inventory != nil {
+		merged.ApplyInventory(results, parts[0].Inventory.quantities())
+		for _, part := range parts {
+			if part.Inventory != nil {
+				merged.Inventory.StockOuts += part.Inventory.StockOuts
+			}
+		}
+	}
+	return merged
+}
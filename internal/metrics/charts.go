@@ -0,0 +1,226 @@
+package metrics
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"math"
+	"strings"
+)
+
+// Chart geometry shared by every inline SVG chart
+const (
+	chartWidth        = 760
+	chartHeight       = 300
+	chartMarginLeft   = 64
+	chartMarginRight  = 16
+	chartMarginTop    = 16
+	chartMarginBottom = 56
+	chartTicks        = 5
+	maxBarLabels      = 25
+)
+
+// chartPoint is a single x/y sample of a line chart
+type chartPoint struct {
+	X float64
+	Y float64
+}
+
+// chartSeries is a named line drawn on a line chart
+type chartSeries struct {
+	Name   string
+	Color  string
+	Points []chartPoint
+}
+
+// plotArea returns the inner drawing rectangle of a chart
+func plotArea() (x0, y0, width, height float64) {
+	x0 = chartMarginLeft
+	y0 = chartMarginTop
+	width = chartWidth - chartMarginLeft - chartMarginRight
+	height = chartHeight - chartMarginTop - chartMarginBottom
+	return x0, y0, width, height
+}
+
+// svgOpen starts an SVG document with the shared chart styling
+func svgOpen(sb *strings.Builder) {
+	fmt.Fprintf(sb, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" class="chart">`,
+		chartWidth, chartHeight)
+}
+
+// drawAxes renders both axes with evenly spaced y ticks and axis titles
+func drawAxes(sb *strings.Builder, yMax float64, xLabel, yLabel string) {
+	x0, y0, width, height := plotArea()
+
+	for i := 0; i <= chartTicks; i++ {
+		value := yMax * float64(i) / chartTicks
+		y := y0 + height - height*float64(i)/chartTicks
+		fmt.Fprintf(sb, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" class="grid"/>`,
+			x0, y, x0+width, y)
+		fmt.Fprintf(sb, `<text x="%.1f" y="%.1f" class="tick" text-anchor="end">%s</text>`,
+			x0-6, y+4, formatTick(value))
+	}
+
+	fmt.Fprintf(sb, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" class="axis"/>`,
+		x0, y0+height, x0+width, y0+height)
+	fmt.Fprintf(sb, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" class="axis"/>`,
+		x0, y0, x0, y0+height)
+	fmt.Fprintf(sb, `<text x="%.1f" y="%d" class="label" text-anchor="middle">%s</text>`,
+		x0+width/2, chartHeight-6, html.EscapeString(xLabel))
+	fmt.Fprintf(sb, `<text x="14" y="%.1f" class="label" text-anchor="middle" transform="rotate(-90 14 %.1f)">%s</text>`,
+		y0+height/2, y0+height/2, html.EscapeString(yLabel))
+}
+
+// barChart renders one bar per label; hovering a bar shows its exact value
+func barChart(labels []string, values []float64, xLabel, yLabel string) template.HTML {
+	var sb strings.Builder
+	svgOpen(&sb)
+
+	if len(values) == 0 {
+		return emptyChart(&sb)
+	}
+
+	yMax := niceMax(maxOf(values))
+	drawAxes(&sb, yMax, xLabel, yLabel)
+
+	x0, y0, width, height := plotArea()
+	slot := width / float64(len(values))
+	labelEvery := int(math.Ceil(float64(len(labels)) / maxBarLabels))
+
+	for i, value := range values {
+		barHeight := height * value / yMax
+		x := x0 + slot*float64(i) + slot*0.1
+		y := y0 + height - barHeight
+		fmt.Fprintf(&sb, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" class="bar"><title>%s: %s</title></rect>`,
+			x, y, slot*0.8, barHeight, html.EscapeString(labels[i]), formatTick(value))
+
+		if i%labelEvery == 0 {
+			lx := x + slot*0.4
+			ly := y0 + height + 12
+			fmt.Fprintf(&sb, `<text x="%.1f" y="%.1f" class="tick" text-anchor="end" transform="rotate(-40 %.1f %.1f)">%s</text>`,
+				lx, ly, lx, ly, html.EscapeString(labels[i]))
+		}
+	}
+
+	sb.WriteString(`</svg>`)
+	return template.HTML(sb.String())
+}
+
+// lineChart renders one or more series sharing the same axes
+func lineChart(series []chartSeries, xLabel, yLabel string) template.HTML {
+	var sb strings.Builder
+	svgOpen(&sb)
+
+	var xMax, yMax float64
+	points := 0
+	for _, s := range series {
+		for _, p := range s.Points {
+			xMax = math.Max(xMax, p.X)
+			yMax = math.Max(yMax, p.Y)
+			points++
+		}
+	}
+	if points == 0 {
+		return emptyChart(&sb)
+	}
+
+	yMax = niceMax(yMax)
+	if xMax == 0 {
+		xMax = 1
+	}
+	drawAxes(&sb, yMax, xLabel, yLabel)
+
+	x0, y0, width, height := plotArea()
+	for i := 0; i <= chartTicks; i++ {
+		value := xMax * float64(i) / chartTicks
+		x := x0 + width*float64(i)/chartTicks
+		fmt.Fprintf(&sb, `<text x="%.1f" y="%.1f" class="tick" text-anchor="middle">%s</text>`,
+			x, y0+height+16, formatTick(value))
+	}
+
+	for i, s := range series {
+		coords := make([]string, len(s.Points))
+		for j, p := range s.Points {
+			coords[j] = fmt.Sprintf("%.1f,%.1f", x0+width*p.X/xMax, y0+height-height*p.Y/yMax)
+		}
+		fmt.Fprintf(&sb, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2"/>`,
+			strings.Join(coords, " "), s.Color)
+		if s.Name != "" {
+			fmt.Fprintf(&sb, `<text x="%.1f" y="%.1f" class="legend" fill="%s" text-anchor="end">%s</text>`,
+				x0+width-4, y0+14+float64(i)*14, s.Color, html.EscapeString(s.Name))
+		}
+	}
+
+	sb.WriteString(`</svg>`)
+	return template.HTML(sb.String())
+}
+
+// histogram buckets values into a fixed number of equal-width bins
+func histogram(values []float64, bins int) ([]string, []float64) {
+	if len(values) == 0 || bins < 1 {
+		return nil, nil
+	}
+
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	binWidth := (hi - lo) / float64(bins)
+	if binWidth == 0 {
+		binWidth = 1
+	}
+
+	counts := make([]float64, bins)
+	for _, v := range values {
+		idx := int((v - lo) / binWidth)
+		if idx >= bins {
+			idx = bins - 1
+		}
+		counts[idx]++
+	}
+
+	labels := make([]string, bins)
+	for i := range labels {
+		labels[i] = fmt.Sprintf("%.0f-%.0f", lo+binWidth*float64(i), lo+binWidth*float64(i+1))
+	}
+	return labels, counts
+}
+
+// emptyChart closes an SVG that has no data to plot
+func emptyChart(sb *strings.Builder) template.HTML {
+	fmt.Fprintf(sb, `<text x="%d" y="%d" class="label" text-anchor="middle">No data</text></svg>`,
+		chartWidth/2, chartHeight/2)
+	return template.HTML(sb.String())
+}
+
+// niceMax rounds an axis maximum up to a readable value
+func niceMax(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, step := range []float64{1, 2, 2.5, 5, 10} {
+		if v <= step*magnitude {
+			return step * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+// maxOf returns the largest value in a slice
+func maxOf(values []float64) float64 {
+	m := math.Inf(-1)
+	for _, v := range values {
+		m = math.Max(m, v)
+	}
+	return m
+}
+
+// formatTick prints axis values without unnecessary decimals
+func formatTick(v float64) string {
+	if v == math.Trunc(v) {
+		return fmt.Sprintf("%.0f", v)
+	}
+	return fmt.Sprintf("%.2f", v)
+}
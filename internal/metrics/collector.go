@@ -13,12 +13,16 @@ type Collector struct {
 	startTime     time.Time
 	maxMemory     uint64
 	maxGoroutines int
+	stopChan      chan struct{}
+	doneChan      chan struct{}
 }
 
 // NewCollector creates a new metrics collector
 func NewCollector() *Collector {
 	return &Collector{
-		metrics: &SimulationMetrics{},
+		metrics:  &SimulationMetrics{},
+		stopChan: make(chan struct{}),
+		doneChan: make(chan struct{}),
 	}
 }
 
@@ -36,8 +40,12 @@ func (c *Collector) Start() {
 	go c.monitorResources()
 }
 
-// Stop ends metrics collection and finalizes results
+// Stop ends metrics collection, waits for the monitor to exit and
+// finalizes results
 func (c *Collector) Stop() *SimulationMetrics {
+	close(c.stopChan)
+	<-c.doneChan
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -72,11 +80,14 @@ func (c *Collector) RecordAuctionResult(successful bool, bidCount int) {
 func (c *Collector) monitorResources() {
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
+	defer close(c.doneChan)
 
 	for {
 		select {
 		case <-ticker.C:
 			c.updateResourceStats()
+		case <-c.stopChan:
+			return
 		}
 	}
 }
@@ -0,0 +1,233 @@
+package metrics
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"auction-simulator/internal/types"
+)
+
+// Exporter writes run output as flat tables in a specific file format
+type Exporter interface {
+	// Extension is the file extension used for every table, without a dot
+	Extension() string
+	WriteAuctions(w io.Writer, rows []AuctionRow) error
+	WriteBids(w io.Writer, rows []BidRow) error
+	WriteBidderStats(w io.Writer, rows []BidderStats) error
+}
+
+// AuctionRow is one auction outcome flattened for tabular export
+type AuctionRow struct {
+	AuctionID    string    `parquet:"auction_id"`
+	ItemID       string    `parquet:"item_id"`
+	WinnerID     string    `parquet:"winner_id"`
+	WinningBid   float64   `parquet:"winning_bid"`
+	ReservePrice float64   `parquet:"reserve_price"`
+	Revenue      float64   `parquet:"revenue"`
+	TotalBids    int       `parquet:"total_bids"`
+	DurationMS   float64   `parquet:"duration_ms"`
+	Success      bool      `parquet:"success"`
+	Aborted      bool      `parquet:"aborted"`
+	Extensions   int       `parquet:"extensions"`
+	Error        string    `parquet:"error"`
+	StartTime    time.Time `parquet:"start_time"`
+	EndTime      time.Time `parquet:"end_time"`
+}
+
+// BidRow is a single entry of an auction's bid book
+type BidRow struct {
+	AuctionID string    `parquet:"auction_id"`
+	BidderID  string    `parquet:"bidder_id"`
+	Amount    float64   `parquet:"amount"`
+	LatencyMS float64   `parquet:"latency_ms"`
+	Won       bool      `parquet:"won"`
+	Timestamp time.Time `parquet:"timestamp"`
+}
+
+// NewExporter returns the exporter registered for a format name
+func NewExporter(format string) (Exporter, error) {
+	switch format {
+	case "csv":
+		return &CSVExporter{}, nil
+	case "parquet":
+		return &ParquetExporter{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// AuctionRows flattens auction results into export rows
+func AuctionRows(results []*types.AuctionResult) []AuctionRow {
+	rows := make([]AuctionRow, len(results))
+	for i, result := range results {
+		row := AuctionRow{
+			AuctionID:    result.AuctionID,
+			ItemID:       result.ItemID,
+			ReservePrice: result.ReservePrice,
+			Revenue:      Revenue(result),
+			TotalBids:    result.TotalBids,
+			DurationMS:   float64(result.Duration) / float64(time.Millisecond),
+			Success:      result.Error == nil,
+			Aborted:      result.Aborted,
+			Extensions:   result.Extensions,
+			StartTime:    result.StartTime,
+			EndTime:      result.EndTime,
+		}
+		if result.Winner != nil {
+			row.WinnerID = result.Winner.BidderID
+			row.WinningBid = result.Winner.Amount
+		}
+		if result.Error != nil {
+			row.Error = result.Error.Error()
+		}
+		rows[i] = row
+	}
+	return rows
+}
+
+// BidRows flattens every auction's bid book into export rows
+func BidRows(results []*types.AuctionResult) []BidRow {
+	var rows []BidRow
+	for _, result := range results {
+		for _, bid := range result.Bids {
+			rows = append(rows, BidRow{
+				AuctionID: result.AuctionID,
+				BidderID:  bid.BidderID,
+				Amount:    bid.Amount,
+				LatencyMS: float64(bid.Latency) / float64(time.Millisecond),
+				Won:       result.Winner != nil && *result.Winner == bid,
+				Timestamp: bid.Timestamp,
+			})
+		}
+	}
+	return rows
+}
+
+// CSVExporter writes each table as a CSV file with a header row
+type CSVExporter struct{}
+
+// Extension implements Exporter
+func (e *CSVExporter) Extension() string { return "csv" }
+
+// WriteAuctions implements Exporter
+func (e *CSVExporter) WriteAuctions(w io.Writer, rows []AuctionRow) error {
+	header := []string{"auction_id", "item_id", "winner_id", "winning_bid", "reserve_price", "revenue", "total_bids",
+		"duration_ms", "success", "aborted", "extensions", "error", "start_time", "end_time"}
+
+	return writeCSV(w, header, len(rows), func(i int) []string {
+		row := rows[i]
+		return []string{
+			row.AuctionID,
+			row.ItemID,
+			row.WinnerID,
+			formatFloat(row.WinningBid),
+			formatFloat(row.ReservePrice),
+			formatFloat(row.Revenue),
+			strconv.Itoa(row.TotalBids),
+			formatFloat(row.DurationMS),
+			strconv.FormatBool(row.Success),
+			strconv.FormatBool(row.Aborted),
+			strconv.Itoa(row.Extensions),
+			row.Error,
+			row.StartTime.Format(time.RFC3339Nano),
+			row.EndTime.Format(time.RFC3339Nano),
+		}
+	})
+}
+
+// WriteBids implements Exporter
+func (e *CSVExporter) WriteBids(w io.Writer, rows []BidRow) error {
+	header := []string{"auction_id", "bidder_id", "amount", "latency_ms", "won", "timestamp"}
+
+	return writeCSV(w, header, len(rows), func(i int) []string {
+		row := rows[i]
+		return []string{
+			row.AuctionID,
+			row.BidderID,
+			formatFloat(row.Amount),
+			formatFloat(row.LatencyMS),
+			strconv.FormatBool(row.Won),
+			row.Timestamp.Format(time.RFC3339Nano),
+		}
+	})
+}
+
+// WriteBidderStats implements Exporter
+func (e *CSVExporter) WriteBidderStats(w io.Writer, rows []BidderStats) error {
+	header := []string{"bidder_id", "bids", "wins", "win_rate", "total_spend",
+		"average_bid", "max_bid", "average_latency_ms"}
+
+	return writeCSV(w, header, len(rows), func(i int) []string {
+		row := rows[i]
+		return []string{
+			row.BidderID,
+			strconv.Itoa(row.Bids),
+			strconv.Itoa(row.Wins),
+			formatFloat(row.WinRate),
+			formatFloat(row.TotalSpend),
+			formatFloat(row.AverageBid),
+			formatFloat(row.MaxBid),
+			formatFloat(row.AverageLatencyMS),
+		}
+	})
+}
+
+// ParquetExporter writes each table as a Parquet file
+type ParquetExporter struct{}
+
+// Extension implements Exporter
+func (e *ParquetExporter) Extension() string { return "parquet" }
+
+// WriteAuctions implements Exporter
+func (e *ParquetExporter) WriteAuctions(w io.Writer, rows []AuctionRow) error {
+	return writeParquet(w, rows)
+}
+
+// WriteBids implements Exporter
+func (e *ParquetExporter) WriteBids(w io.Writer, rows []BidRow) error {
+	return writeParquet(w, rows)
+}
+
+// WriteBidderStats implements Exporter
+func (e *ParquetExporter) WriteBidderStats(w io.Writer, rows []BidderStats) error {
+	return writeParquet(w, rows)
+}
+
+// writeCSV writes a header followed by one record per row
+func writeCSV(w io.Writer, header []string, count int, record func(i int) []string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("could not write CSV header: %w", err)
+	}
+
+	for i := 0; i < count; i++ {
+		if err := writer.Write(record(i)); err != nil {
+			return fmt.Errorf("could not write CSV record: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeParquet writes all rows as a single Parquet file
+func writeParquet[T any](w io.Writer, rows []T) error {
+	writer := parquet.NewGenericWriter[T](w)
+	if _, err := writer.Write(rows); err != nil {
+		return fmt.Errorf("could not write parquet rows: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("could not finalize parquet file: %w", err)
+	}
+	return nil
+}
+
+// formatFloat prints floats in the shortest form that round-trips
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
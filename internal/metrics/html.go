@@ -0,0 +1,241 @@
+package metrics
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// histogramBins is the number of buckets in the bid distribution chart
+const histogramBins = 20
+
+// reportField is a single name/value row in a report table
+type reportField struct {
+	Name  string
+	Value string
+}
+
+// htmlReportData is the view model rendered by reportTemplate
+type htmlReportData struct {
+	Generated    string
+	Incomplete   string // reason the run stopped early, empty when it completed
+	Summary      []reportField
+	Config       []reportField
+	BidHistogram template.HTML
+	Revenue      template.HTML
+	LatencyCDF   template.HTML
+	Winners      template.HTML
+	MemoryUsage  template.HTML
+	Goroutines   template.HTML
+}
+
+// SaveHTMLReport writes a single self-contained HTML report for a run
+func (r *Reporter) SaveHTMLReport(report *RunReport) error {
+	filename := fmt.Sprintf("%s/report_%s.html",
+		r.outputDir, time.Now().Format("20060102_150405"))
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("could not create HTML report: %w", err)
+	}
+	defer file.Close()
+
+	if err := reportTemplate.Execute(file, buildReportData(report)); err != nil {
+		return fmt.Errorf("could not render HTML report: %w", err)
+	}
+
+	log.Printf("HTML report saved to: %s", filename)
+	return nil
+}
+
+// buildReportData turns raw run output into chart markup
+func buildReportData(report *RunReport) *htmlReportData {
+	data := &htmlReportData{
+		Generated: time.Now().Format(time.RFC1123),
+	}
+
+	if report.Metrics != nil {
+		data.Summary = summaryFields(report.Metrics)
+		if report.Metrics.Incomplete {
+			data.Incomplete = report.Metrics.Failure
+		}
+	}
+	if report.Config != nil {
+		data.Config = flattenFields("", reflect.ValueOf(*report.Config))
+	}
+
+	bids := AllBids(report.Results)
+
+	amounts := make([]float64, len(bids))
+	for i, bid := range bids {
+		amounts[i] = bid.Amount
+	}
+	labels, counts := histogram(amounts, histogramBins)
+	data.BidHistogram = barChart(labels, counts, "Bid amount ($)", "Bids")
+
+	auctionLabels := make([]string, len(report.Results))
+	for i, result := range report.Results {
+		auctionLabels[i] = result.AuctionID
+	}
+	data.Revenue = barChart(auctionLabels, AuctionRevenues(report.Results), "Auction", "Revenue ($)")
+
+	data.LatencyCDF = lineChart([]chartSeries{{
+		Color:  "#2b6cb0",
+		Points: cdfPoints(BidLatenciesMS(report.Results)),
+	}}, "Latency (ms)", "Fraction of bids")
+
+	winnerLabels, wins := winnersByBidder(report)
+	data.Winners = barChart(winnerLabels, wins, "Bidder", "Auctions won")
+
+	memory := make([]chartPoint, len(report.Resources))
+	goroutines := make([]chartPoint, len(report.Resources))
+	for i, reading := range report.Resources {
+		elapsed := reading.Timestamp.Sub(report.Resources[0].Timestamp).Seconds()
+		memory[i] = chartPoint{X: elapsed, Y: reading.MemoryMB}
+		goroutines[i] = chartPoint{X: elapsed, Y: float64(reading.GoroutineCount)}
+	}
+	data.MemoryUsage = lineChart([]chartSeries{{Name: "Heap (MB)", Color: "#c05621", Points: memory}},
+		"Elapsed (s)", "Memory (MB)")
+	data.Goroutines = lineChart([]chartSeries{{Name: "Goroutines", Color: "#2f855a", Points: goroutines}},
+		"Elapsed (s)", "Goroutines")
+
+	return data
+}
+
+// summaryFields lists the headline metrics shown at the top of the report
+func summaryFields(m *SimulationMetrics) []reportField {
+	successRate := 0.0
+	if m.TotalAuctions > 0 {
+		successRate = float64(m.SuccessfulAuctions) / float64(m.TotalAuctions) * 100
+	}
+
+	return []reportField{
+		{"Total Duration", m.TotalDuration.Round(time.Millisecond).String()},
+		{"Auctions", fmt.Sprintf("%d", m.TotalAuctions)},
+		{"Bidders", fmt.Sprintf("%d", m.TotalBidders)},
+		{"Successful Auctions", fmt.Sprintf("%d (%.1f%%)", m.SuccessfulAuctions, successRate)},
+		{"Failed Auctions", fmt.Sprintf("%d", m.FailedAuctions)},
+		{"Total Bids", fmt.Sprintf("%d (avg %.1f per auction)", m.TotalBidsReceived, m.AverageBidsPerAuction)},
+		{"Total Revenue", fmt.Sprintf("$%.2f", m.TotalRevenue)},
+		{"Latency p50 / p90 / p99", fmt.Sprintf("%.1f / %.1f / %.1f ms", m.LatencyP50MS, m.LatencyP90MS, m.LatencyP99MS)},
+		{"Max Goroutines", fmt.Sprintf("%d", m.MaxGoroutines)},
+		{"Peak Memory", fmt.Sprintf("%.2f MB", m.MemoryUsageMB)},
+	}
+}
+
+// flattenFields lists every leaf field of a struct, prefixing nested names
+func flattenFields(prefix string, v reflect.Value) []reportField {
+	var fields []reportField
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		if !t.Field(i).IsExported() {
+			continue
+		}
+
+		name := t.Field(i).Name
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct && field.Type() != reflect.TypeOf(time.Time{}) {
+			fields = append(fields, flattenFields(name, field)...)
+			continue
+		}
+		fields = append(fields, reportField{Name: name, Value: fmt.Sprintf("%v", field.Interface())})
+	}
+	return fields
+}
+
+// cdfPoints returns the empirical cumulative distribution of values
+func cdfPoints(values []float64) []chartPoint {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	points := make([]chartPoint, len(sorted))
+	for i, v := range sorted {
+		points[i] = chartPoint{X: v, Y: float64(i+1) / float64(len(sorted))}
+	}
+	return points
+}
+
+// winnersByBidder counts auction wins per bidder, most wins first
+func winnersByBidder(report *RunReport) ([]string, []float64) {
+	wins := WinCounts(report.Results)
+
+	bidders := make([]string, 0, len(wins))
+	for id := range wins {
+		bidders = append(bidders, id)
+	}
+	sort.Slice(bidders, func(i, j int) bool {
+		if wins[bidders[i]] != wins[bidders[j]] {
+			return wins[bidders[i]] > wins[bidders[j]]
+		}
+		return bidders[i] < bidders[j]
+	})
+
+	counts := make([]float64, len(bidders))
+	for i, id := range bidders {
+		counts[i] = float64(wins[id])
+	}
+	return bidders, counts
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Auction Simulation Report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 820px; color: #1a202c; }
+h1 { margin-bottom: 0.2em; }
+h2 { margin-top: 1.8em; border-bottom: 1px solid #e2e8f0; padding-bottom: 0.2em; }
+.generated { color: #718096; }
+.incomplete { margin-top: 1em; padding: 8px 12px; background: #fff5f5; border: 1px solid #feb2b2; color: #c53030; }
+table { border-collapse: collapse; width: 100%; }
+td { padding: 4px 8px; border-bottom: 1px solid #edf2f7; font-size: 14px; }
+td:first-child { font-weight: 600; width: 40%; }
+.chart { width: 100%; height: auto; }
+.chart .grid { stroke: #edf2f7; }
+.chart .axis { stroke: #4a5568; }
+.chart .bar { fill: #4299e1; }
+.chart .bar:hover { fill: #2b6cb0; }
+.chart .tick { font-size: 10px; fill: #4a5568; }
+.chart .label { font-size: 12px; fill: #2d3748; }
+.chart .legend { font-size: 12px; }
+</style>
+</head>
+<body>
+<h1>Auction Simulation Report</h1>
+<div class="generated">Generated {{.Generated}}</div>
+{{if .Incomplete}}<div class="incomplete">Incomplete run: {{.Incomplete}}. Figures cover only the auctions processed before it stopped.</div>{{end}}
+
+<h2>Summary</h2>
+<table>{{range .Summary}}<tr><td>{{.Name}}</td><td>{{.Value}}</td></tr>{{end}}</table>
+
+<h2>Bid Distribution</h2>
+{{.BidHistogram}}
+
+<h2>Revenue per Auction</h2>
+{{.Revenue}}
+
+<h2>Bidder Latency CDF</h2>
+{{.LatencyCDF}}
+
+<h2>Winners by Bidder</h2>
+{{.Winners}}
+
+<h2>Resource Usage</h2>
+{{.MemoryUsage}}
+{{.Goroutines}}
+
+<h2>Configuration</h2>
+<table>{{range .Config}}<tr><td>{{.Name}}</td><td>{{.Value}}</td></tr>{{end}}</table>
+</body>
+</html>
+`))
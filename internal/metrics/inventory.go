@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"sort"
+
+	"auction-simulator/internal/types"
+)
+
+// InventoryMetrics describes how a run sold the items of its catalog
+type InventoryMetrics struct {
+	Items          int             `json:"items"`
+	Units          int             `json:"units"` // units for sale, not counting items of unlimited quantity
+	Sold           int             `json:"sold"`
+	SellThrough    float64         `json:"sell_through"` // share of units sold
+	SoldOut        int             `json:"sold_out"`     // items with no units left
+	UnsoldAuctions int             `json:"unsold_auctions"`
+	StockOuts      int             `json:"stock_outs"` // arrivals with every unit in auction
+	PerItem        []ItemInventory `json:"per_item"`
+}
+
+// ItemInventory is how one catalog item sold
+type ItemInventory struct {
+	ItemID    string  `json:"item_id"`
+	Quantity  int     `json:"quantity"` // 0 is unlimited
+	Auctions  int     `json:"auctions"`
+	Sold      int     `json:"sold"`
+	Remaining int     `json:"remaining"` // 0 for unlimited items
+	Revenue   float64 `json:"revenue"`
+}
+
+// ApplyInventory fills the inventory statistics of a run that auctioned
+// catalog items, given each item's quantity
+func (m *SimulationMetrics) ApplyInventory(results []*types.AuctionResult, quantities map[string]int) {
+	items := make(map[string]*ItemInventory, len(quantities))
+	for id, quantity := range quantities {
+		items[id] = &ItemInventory{ItemID: id, Quantity: quantity}
+	}
+
+	inventory := &InventoryMetrics{Items: len(quantities)}
+	for _, result := range results {
+		item, ok := items[result.ItemID]
+		if !ok {
+			continue
+		}
+		item.Auctions++
+		if result.Winner == nil || result.Error != nil {
+			inventory.UnsoldAuctions++
+			continue
+		}
+		item.Sold++
+		item.Revenue += Revenue(result)
+	}
+
+	for _, item := range items {
+		if item.Quantity > 0 {
+			item.Remaining = item.Quantity - item.Sold
+			inventory.Units += item.Quantity
+			inventory.Sold += item.Sold
+			if item.Remaining == 0 {
+				inventory.SoldOut++
+			}
+		}
+		inventory.PerItem = append(inventory.PerItem, *item)
+	}
+	if inventory.Units > 0 {
+		inventory.SellThrough = float64(inventory.Sold) / float64(inventory.Units)
+	}
+	sort.Slice(inventory.PerItem, func(i, j int) bool {
+		return inventory.PerItem[i].ItemID < inventory.PerItem[j].ItemID
+	})
+	m.Inventory = inventory
+}
+
+// quantities returns the quantity of each item of a run's inventory
+func (i *InventoryMetrics) quantities() map[string]int {
+	quantities := make(map[string]int, len(i.PerItem))
+	for _, item := range i.PerItem {
+		quantities[item.ItemID] = item.Quantity
+	}
+	return quantities
+}
@@ -3,17 +3,21 @@ package metrics
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
+	"auction-simulator/internal/fault"
 	"auction-simulator/internal/types"
 )
 
 // Reporter handles output of simulation results
 type Reporter struct {
 	outputDir string
+	exporter  Exporter
 }
 
 // NewReporter creates a new results reporter
@@ -32,6 +36,12 @@ func NewReporter(outputDir string) *Reporter {
 	}
 }
 
+// SetExporter switches result output from per-auction JSON files to
+// tabular files written by the given exporter
+func (r *Reporter) SetExporter(exporter Exporter) {
+	r.exporter = exporter
+}
+
 // ReportSummary prints a summary of simulation results
 func (r *Reporter) ReportSummary(metrics *SimulationMetrics) {
 	separator := strings.Repeat("=", 60)
@@ -45,12 +55,172 @@ func (r *Reporter) ReportSummary(metrics *SimulationMetrics) {
 		float64(metrics.SuccessfulAuctions)/float64(metrics.TotalAuctions)*100)
 	fmt.Printf("Total Bids Received: %d (avg: %.1f per auction)\n",
 		metrics.TotalBidsReceived, metrics.AverageBidsPerAuction)
+	fmt.Printf("Total Revenue: $%.2f\n", metrics.TotalRevenue)
+	fmt.Printf("Bid Latency p50/p90/p99: %.1f/%.1f/%.1f ms\n",
+		metrics.LatencyP50MS, metrics.LatencyP90MS, metrics.LatencyP99MS)
+	fmt.Printf("Concurrency Limit: %d (range %d-%d)\n",
+		metrics.ConcurrencyLimitFinal, metrics.ConcurrencyLimitMin, metrics.ConcurrencyLimitMax)
+	if metrics.Extensions > 0 {
+		fmt.Printf("Soft-Close Extensions: %d over %d auctions\n", metrics.Extensions, metrics.ExtendedAuctions)
+	}
+	if len(metrics.Notices) > 0 || metrics.NoticeFailures > 0 {
+		fmt.Printf("Notices Sent: %s (%d failed)\n", formatCounts(metrics.Notices, types.NoticeKinds), metrics.NoticeFailures)
+	}
+	if len(metrics.LearnedShades) > 0 {
+		fmt.Printf("Learned Shades: %s\n", formatShades(metrics.LearnedShades))
+	}
+	if len(metrics.Groups) > 0 {
+		fmt.Printf("Collusion Groups: %s\n", formatGroups(metrics.Groups))
+	}
+	if metrics.TiedAuctions > 0 {
+		fmt.Printf("Tied Auctions: %d\n", metrics.TiedAuctions)
+	}
+	if len(metrics.Rejections) > 0 {
+		fmt.Printf("Rejected Bids: %s\n", formatCounts(metrics.Rejections, types.RejectReasons))
+	}
+	if len(metrics.Faults) > 0 {
+		fmt.Printf("Bidder Faults: %s\n", formatCounts(metrics.Faults, types.FaultKinds))
+	}
+	if len(metrics.FaultsInjected) > 0 {
+		fmt.Printf("Injected Faults: %s\n", formatCounts(metrics.FaultsInjected, fault.Kinds))
+	}
 	fmt.Printf("Max Goroutines: %d\n", metrics.MaxGoroutines)
 	fmt.Printf("Peak Memory Usage: %.2f MB\n", metrics.MemoryUsageMB)
+	if s := metrics.Stream; s != nil {
+		fmt.Printf("Arrivals: %d %s over %v (offered %.1f/s)\n",
+			s.Arrivals, s.Process, s.Window.Round(time.Millisecond), s.OfferedRate)
+		fmt.Printf("Throughput: %.1f auctions/s, max %d in flight\n", s.Throughput, s.MaxInFlight)
+		fmt.Printf("Queueing Delay p50/p90/p99: %.1f/%.1f/%.1f ms (max %.1f)\n",
+			s.QueueDelayP50MS, s.QueueDelayP90MS, s.QueueDelayP99MS, s.QueueDelayMaxMS)
+		fmt.Printf("Steady State (after %v): %d auctions, %.1f/s, %.1f%% success, $%.2f/auction, %.1f bids/auction, queue p99 %.1f ms\n",
+			s.Steady.From.Round(time.Millisecond), s.Steady.Auctions, s.Steady.Throughput, s.Steady.SuccessRate*100,
+			s.Steady.RevenuePerAuction, s.Steady.BidsPerAuction, s.Steady.QueueDelayP99MS)
+	}
+	if inv := metrics.Inventory; inv != nil {
+		fmt.Printf("Inventory: %d of %d units sold (%.1f%%), %d of %d items sold out, %d auctions unsold\n",
+			inv.Sold, inv.Units, inv.SellThrough*100, inv.SoldOut, inv.Items, inv.UnsoldAuctions)
+		if inv.StockOuts > 0 {
+			fmt.Printf("Stock-outs: %d arrivals found every unit in auction\n", inv.StockOuts)
+		}
+	}
+	if metrics.Incomplete {
+		fmt.Printf("INCOMPLETE: %s\n", metrics.Failure)
+	}
 
 	fmt.Printf("%s\n", separator)
 }
 
+// formatCounts lists counts as "kind n", known kinds first in their order
+// and any others alphabetically
+func formatCounts(counts map[string]int, order []string) string {
+	var parts []string
+	seen := make(map[string]bool, len(order))
+	for _, kind := range order {
+		seen[kind] = true
+		if n := counts[kind]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", kind, n))
+		}
+	}
+
+	var rest []string
+	for kind := range counts {
+		if !seen[kind] {
+			rest = append(rest, kind)
+		}
+	}
+	sort.Strings(rest)
+	for _, kind := range rest {
+		parts = append(parts, fmt.Sprintf("%s %d", kind, counts[kind]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatShades lists shades by strategy in name order
+func formatShades(shades map[string]float64) string {
+	strategies := make([]string, 0, len(shades))
+	for strategy := range shades {
+		strategies = append(strategies, strategy)
+	}
+	sort.Strings(strategies)
+
+	parts := make([]string, len(strategies))
+	for i, strategy := range strategies {
+		parts[i] = fmt.Sprintf("%s %.2f", strategy, shades[strategy])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatGroups lists collusion groups as "id (size strategy)"
+func formatGroups(groups []types.CollusionGroup) string {
+	parts := make([]string, len(groups))
+	for i, group := range groups {
+		parts[i] = fmt.Sprintf("%s (%d %s)", group.ID, len(group.Members), group.Strategy)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// riskTop is how many of the riskiest bidders the summary lists
+const riskTop = 10
+
+// ReportRisk prints the riskiest bidders of a run and, when there were
+// collusion groups, how well the detectors found them
+func (r *Reporter) ReportRisk(risk *RiskReport) {
+	if len(risk.Bidders) == 0 {
+		return
+	}
+	fmt.Printf("\nCOLLUSION RISK (threshold %.2f): %d of %d bidders flagged\n",
+		risk.Threshold, risk.Flagged(), len(risk.Bidders))
+	lineSeparator := strings.Repeat("-", 80)
+	fmt.Printf("%s\n", lineSeparator)
+	fmt.Printf("%-12s %-7s %-7s %-9s %-7s %-15s %s\n",
+		"Bidder", "Score", "Corr", "Rotation", "Shill", "Group", "Partners")
+	fmt.Printf("%s\n", lineSeparator)
+
+	for i, b := range risk.Bidders {
+		if i == riskTop {
+			break
+		}
+		group := b.Group
+		if group == "" {
+			group = "-"
+		}
+		fmt.Printf("%-12s %-7.2f %-7.2f %-9.2f %-7.2f %-15s %s\n",
+			b.BidderID, b.Score, b.Correlation, b.Rotation, b.Shill, group, strings.Join(b.Partners, " "))
+	}
+
+	if e := risk.Evaluation; e != nil {
+		recall := make([]string, 0, len(e.RecallByKind))
+		for kind, v := range e.RecallByKind {
+			recall = append(recall, fmt.Sprintf("%s %.2f", kind, v))
+		}
+		sort.Strings(recall)
+		fmt.Printf("Detection: precision %.2f, recall %.2f, F1 %.2f, AUC %.2f (%d TP, %d FP, %d FN; recall %s)\n",
+			e.Precision, e.Recall, e.F1, e.AUC, e.TruePositives, e.FalsePositives, e.FalseNegatives, strings.Join(recall, ", "))
+	}
+}
+
+// SaveRiskReport writes the per-bidder risk report to a JSON file
+func (r *Reporter) SaveRiskReport(risk *RiskReport) error {
+	filename := fmt.Sprintf("%s/risk_report_%s.json",
+		r.outputDir, time.Now().Format("20060102_150405"))
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("could not create risk report file: %w", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(risk); err != nil {
+		return fmt.Errorf("could not encode risk report: %w", err)
+	}
+
+	log.Printf("Risk report saved to: %s", filename)
+	return nil
+}
+
 // SaveMetrics writes metrics to a JSON file
 func (r *Reporter) SaveMetrics(metrics *SimulationMetrics) error {
 	filename := fmt.Sprintf("%s/simulation_metrics_%s.json",
@@ -75,6 +245,10 @@ func (r *Reporter) SaveMetrics(metrics *SimulationMetrics) error {
 
 // SaveAuctionResults writes individual auction results to files
 func (r *Reporter) SaveAuctionResults(results []*types.AuctionResult) error {
+	if r.exporter != nil {
+		return r.exportTables(results)
+	}
+
 	for _, result := range results {
 		filename := fmt.Sprintf("%s/auction_%s.json", r.outputDir, result.AuctionID)
 
@@ -97,3 +271,61 @@ func (r *Reporter) SaveAuctionResults(results []*types.AuctionResult) error {
 	log.Printf("Auction results saved to: %s/", r.outputDir)
 	return nil
 }
+
+// exportTables writes auction results, bid books and bidder stats as tables
+func (r *Reporter) exportTables(results []*types.AuctionResult) error {
+	timestamp := time.Now().Format("20060102_150405")
+	ext := r.exporter.Extension()
+
+	tables := []struct {
+		name  string
+		write func(w io.Writer) error
+	}{
+		{"auctions", func(w io.Writer) error { return r.exporter.WriteAuctions(w, AuctionRows(results)) }},
+		{"bids", func(w io.Writer) error { return r.exporter.WriteBids(w, BidRows(results)) }},
+		{"bidder_stats", func(w io.Writer) error { return r.exporter.WriteBidderStats(w, ComputeBidderStats(results)) }},
+	}
+
+	for _, table := range tables {
+		filename := fmt.Sprintf("%s/%s_%s.%s", r.outputDir, table.name, timestamp, ext)
+
+		file, err := os.Create(filename)
+		if err != nil {
+			return fmt.Errorf("could not create %s file: %w", table.name, err)
+		}
+
+		if err := table.write(file); err != nil {
+			file.Close()
+			return fmt.Errorf("could not export %s: %w", table.name, err)
+		}
+
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("could not close %s file: %w", table.name, err)
+		}
+	}
+
+	log.Printf("Exported %s tables to: %s/", ext, r.outputDir)
+	return nil
+}
+
+// SaveBatchSummary writes the aggregate of a batch, including every run's
+// metrics for drill-down, to a JSON file
+func (r *Reporter) SaveBatchSummary(summary *BatchSummary) error {
+	filename := fmt.Sprintf("%s/%s.json", r.outputDir, summary.BatchID)
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("could not create batch summary file: %w", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(summary); err != nil {
+		return fmt.Errorf("could not encode batch summary: %w", err)
+	}
+
+	log.Printf("Batch summary saved to: %s", filename)
+	return nil
+}
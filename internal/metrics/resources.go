@@ -1,25 +1,59 @@
 package metrics
 
 import (
+	"fmt"
 	"runtime"
 	"time"
 )
 
 // ResourceMonitor provides detailed resource tracking
 type ResourceMonitor struct {
-	startTime time.Time
-	readings  []ResourceUsage
-	stopChan  chan struct{}
+	startTime     time.Time
+	readings      []ResourceUsage
+	stopChan      chan struct{}
+	doneChan      chan struct{}
+	memoryLimitMB int
+	breachChan    chan *MemoryBreach
+}
+
+// MemoryBreach describes the process exceeding its memory limit
+type MemoryBreach struct {
+	At         time.Time `json:"at"`
+	LimitMB    int       `json:"limit_mb"`
+	UsedMB     float64   `json:"used_mb"` // memory obtained from the OS and not yet returned
+	HeapMB     float64   `json:"heap_mb"`
+	StackMB    float64   `json:"stack_mb"`
+	Goroutines int       `json:"goroutines"`
+	NumGC      uint32    `json:"num_gc"`
+}
+
+// Error implements error so a breach can end a run
+func (b *MemoryBreach) Error() string {
+	return fmt.Sprintf("memory limit of %d MB exceeded: %.1f MB in use (heap %.1f MB, stacks %.1f MB, %d goroutines, %d GC cycles)",
+		b.LimitMB, b.UsedMB, b.HeapMB, b.StackMB, b.Goroutines, b.NumGC)
 }
 
 // NewResourceMonitor creates a new resource monitor
 func NewResourceMonitor() *ResourceMonitor {
 	return &ResourceMonitor{
-		readings: make([]ResourceUsage, 0),
-		stopChan: make(chan struct{}),
+		readings:   make([]ResourceUsage, 0),
+		stopChan:   make(chan struct{}),
+		doneChan:   make(chan struct{}),
+		breachChan: make(chan *MemoryBreach, 1),
 	}
 }
 
+// SetMemoryLimit makes the monitor report when the process uses more than
+// limitMB; call it before Start
+func (rm *ResourceMonitor) SetMemoryLimit(limitMB int) {
+	rm.memoryLimitMB = limitMB
+}
+
+// Breaches delivers the first memory limit breach, if any
+func (rm *ResourceMonitor) Breaches() <-chan *MemoryBreach {
+	return rm.breachChan
+}
+
 // Start begins resource monitoring
 func (rm *ResourceMonitor) Start() {
 	rm.startTime = time.Now()
@@ -30,6 +64,7 @@ func (rm *ResourceMonitor) Start() {
 // Stop ends resource monitoring and returns collected data
 func (rm *ResourceMonitor) Stop() []ResourceUsage {
 	close(rm.stopChan)
+	<-rm.doneChan
 	return rm.readings
 }
 
@@ -37,6 +72,7 @@ func (rm *ResourceMonitor) Stop() []ResourceUsage {
 func (rm *ResourceMonitor) monitorLoop() {
 	ticker := time.NewTicker(50 * time.Millisecond) // High frequency for accuracy
 	defer ticker.Stop()
+	defer close(rm.doneChan)
 
 	for {
 		select {
@@ -60,6 +96,23 @@ func (rm *ResourceMonitor) captureReading() {
 	}
 
 	rm.readings = append(rm.readings, reading)
+
+	usedMB := float64(m.Sys-m.HeapReleased) / 1024 / 1024
+	if rm.memoryLimitMB > 0 && usedMB > float64(rm.memoryLimitMB) {
+		breach := &MemoryBreach{
+			At:         reading.Timestamp,
+			LimitMB:    rm.memoryLimitMB,
+			UsedMB:     usedMB,
+			HeapMB:     float64(m.HeapInuse) / 1024 / 1024,
+			StackMB:    float64(m.StackInuse) / 1024 / 1024,
+			Goroutines: reading.GoroutineCount,
+			NumGC:      m.NumGC,
+		}
+		select {
+		case rm.breachChan <- breach:
+		default:
+		}
+	}
 }
 
 // GetPeakUsage returns the maximum resource usage observed
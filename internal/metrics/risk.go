@@ -0,0 +1,411 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/stats"
+	"auction-simulator/internal/types"
+)
+
+// Detector settings
+const (
+	RiskThreshold = 0.5 // score at which a bidder is flagged
+
+	minRiskBids   = 10   // bids a bidder needs before it is scored
+	minCommonBids = 20   // auctions two bidders need in common before their bids are compared
+	coverRatio    = 0.75 // bids below this share of a bidder's high bids count as cover bids
+)
+
+// BidderRisk is how suspicious one bidder's bidding looks. Each detector
+// scores from 0 to 1 and the risk score is the highest of them.
+type BidderRisk struct {
+	BidderID    string   `json:"bidder_id"`
+	Bids        int      `json:"bids"`
+	Wins        int      `json:"wins"`
+	Score       float64  `json:"score"`
+	Correlation float64  `json:"correlation"`        // bids or participation tied to another bidder's
+	Rotation    float64  `json:"rotation"`           // share of bids that look like cover bids
+	Shill       float64  `json:"shill"`              // bids always near the price and hardly ever wins
+	Partners    []string `json:"partners,omitempty"` // bidders whose bidding correlates with this one's
+	Flagged     bool     `json:"flagged"`
+	Group       string   `json:"group,omitempty"` // the collusion group it really belongs to, once evaluated
+}
+
+// RiskEvaluation scores the detectors against the simulated collusion
+// groups. Members of abstaining groups that never bid count as missed.
+type RiskEvaluation struct {
+	Colluders      int                `json:"colluders"`
+	TruePositives  int                `json:"true_positives"`
+	FalsePositives int                `json:"false_positives"`
+	FalseNegatives int                `json:"false_negatives"`
+	Precision      float64            `json:"precision"`
+	Recall         float64            `json:"recall"`
+	F1             float64            `json:"f1"`
+	AUC            float64            `json:"auc"`                      // chance a colluder outscores an honest bidder
+	RecallByKind   map[string]float64 `json:"recall_by_kind,omitempty"` // recall per group kind
+}
+
+// RiskReport is the per-bidder risk report of a run, riskiest first
+type RiskReport struct {
+	Threshold  float64         `json:"threshold"`
+	Bidders    []BidderRisk    `json:"bidders"`
+	Evaluation *RiskEvaluation `json:"evaluation,omitempty"`
+}
+
+// Flagged returns how many bidders scored at or above the threshold
+func (r *RiskReport) Flagged() int {
+	n := 0
+	for _, b := range r.Bidders {
+		if b.Flagged {
+			n++
+		}
+	}
+	return n
+}
+
+// bidBook is the highest bid of each bidder in each auction of a run
+type bidBook struct {
+	auctions []map[string]float64 // per auction, by bidder
+	winning  []float64            // winning amount per auction, 0 without a winner
+	bidders  map[string][]float64 // every bidder's bids in auction order
+	wins     map[string]int
+}
+
+// newBidBook collects the bid books of a run's auctions. A bidder that bid
+// several times in an auction, as in open auctions, counts with its highest
+// bid.
+func newBidBook(results []*types.AuctionResult) *bidBook {
+	book := &bidBook{
+		auctions: make([]map[string]float64, len(results)),
+		winning:  make([]float64, len(results)),
+		bidders:  make(map[string][]float64),
+		wins:     WinCounts(results),
+	}
+	for i, result := range results {
+		highest := make(map[string]float64)
+		for _, bid := range result.Bids {
+			highest[bid.BidderID] = math.Max(highest[bid.BidderID], bid.Amount)
+		}
+		book.auctions[i] = highest
+		for id, amount := range highest {
+			book.bidders[id] = append(book.bidders[id], amount)
+		}
+		if result.Winner != nil && result.Error == nil {
+			book.winning[i] = result.Winner.Amount
+		}
+	}
+	return book
+}
+
+// DetectCollusion scores every bidder in a run's bid books for signs of
+// collusion and shill bidding:
+//
+//   - correlation: pairs of bidders whose bids move against each other, or
+//     who bid together far less often than their activity predicts
+//   - rotation: bidders whose bids split into high bids and cover bids
+//     well below them, as cartel members taking turns place
+//   - shill: bidders who bid more often than nearly all others, close to
+//     the winning bid, and hardly ever win
+//
+// Rotation is not scored for open auctions of the given format: there a
+// bidder's highest bid is where it dropped out, which varies with the
+// competition as much as a cover bid does.
+func DetectCollusion(results []*types.AuctionResult, format string) *RiskReport {
+	book := newBidBook(results)
+	partners := correlatedPairs(book)
+
+	// The most active bidders other than shills bid in this many auctions
+	counts := make([]float64, 0, len(book.bidders))
+	for _, bids := range book.bidders {
+		counts = append(counts, float64(len(bids)))
+	}
+	usualBids := stats.Percentile(counts, 90)
+
+	report := &RiskReport{Threshold: RiskThreshold}
+	for id, bids := range book.bidders {
+		risk := BidderRisk{BidderID: id, Bids: len(bids), Wins: book.wins[id]}
+		for partner, score := range partners[id] {
+			risk.Correlation = math.Max(risk.Correlation, score)
+			if score >= RiskThreshold {
+				risk.Partners = append(risk.Partners, partner)
+			}
+		}
+		sort.Strings(risk.Partners)
+		if len(bids) >= minRiskBids {
+			if format != config.FormatOpen {
+				risk.Rotation = rotationScore(bids)
+			}
+			risk.Shill = shillScore(book, id, usualBids)
+		}
+		risk.Score = math.Max(risk.Correlation, math.Max(risk.Rotation, risk.Shill))
+		risk.Flagged = risk.Score >= report.Threshold
+		report.Bidders = append(report.Bidders, risk)
+	}
+
+	sort.Slice(report.Bidders, func(i, j int) bool {
+		if report.Bidders[i].Score != report.Bidders[j].Score {
+			return report.Bidders[i].Score > report.Bidders[j].Score
+		}
+		return report.Bidders[i].BidderID < report.Bidders[j].BidderID
+	})
+	return report
+}
+
+// pairStats accumulates what two bidders' bids have in common
+type pairStats struct {
+	n                   int
+	sumA, sumB          float64
+	sumAA, sumBB, sumAB float64
+}
+
+// correlatedPairs scores every pair of bidders by the stronger of two
+// signals, and returns the scores by bidder and partner:
+//
+//   - how strongly their bids, relative to their usual bid, move against
+//     each other in the auctions both bid in
+//   - how much less often they bid together than independent bidders would,
+//     as rotation cartels whose members abstain do
+func correlatedPairs(book *bidBook) map[string]map[string]float64 {
+	ids := make([]string, 0, len(book.bidders))
+	typical := make(map[string]float64, len(book.bidders))
+	for id, bids := range book.bidders {
+		ids = append(ids, id)
+		typical[id] = stats.Percentile(bids, 50)
+	}
+	sort.Strings(ids)
+
+	pairs := make(map[[2]string]*pairStats)
+	for _, auction := range book.auctions {
+		present := make([]string, 0, len(auction))
+		for id := range auction {
+			present = append(present, id)
+		}
+		sort.Strings(present)
+		for i, a := range present {
+			for _, b := range present[i+1:] {
+				key := [2]string{a, b}
+				p, ok := pairs[key]
+				if !ok {
+					p = &pairStats{}
+					pairs[key] = p
+				}
+				x, y := auction[a]/typical[a], auction[b]/typical[b]
+				p.n++
+				p.sumA += x
+				p.sumB += y
+				p.sumAA += x * x
+				p.sumBB += y * y
+				p.sumAB += x * y
+			}
+		}
+	}
+
+	scores := make(map[string]map[string]float64)
+	record := func(a, b string, score float64) {
+		if score <= 0 {
+			return
+		}
+		for _, pair := range [][2]string{{a, b}, {b, a}} {
+			if scores[pair[0]] == nil {
+				scores[pair[0]] = make(map[string]float64)
+			}
+			scores[pair[0]][pair[1]] = score
+		}
+	}
+
+	auctions := float64(len(book.auctions))
+	tests := float64(len(ids) * (len(ids) - 1) / 2)
+	for i, a := range ids {
+		for _, b := range ids[i+1:] {
+			p := pairs[[2]string{a, b}]
+			if p == nil {
+				p = &pairStats{}
+			}
+
+			// Bidders that avoid each other: score how unlikely so few
+			// shared auctions are by chance, allowing for the number of
+			// pairs tested, so that a Bonferroni-adjusted p-value of 0.01
+			// scores 1 and 0.1 scores 0.5
+			expected := float64(len(book.bidders[a])) * float64(len(book.bidders[b])) / auctions
+			adjusted := (poissonLogCDF(p.n, expected) + math.Log(tests)) / math.Ln10
+			score := clamp(-adjusted / 2)
+			if p.n >= minCommonBids {
+				n := float64(p.n)
+				cov := p.sumAB/n - p.sumA/n*p.sumB/n
+				varA := p.sumAA/n - p.sumA/n*p.sumA/n
+				varB := p.sumBB/n - p.sumB/n*p.sumB/n
+				if varA > 0 && varB > 0 {
+					// Shrink towards zero, so short histories need a
+					// stronger correlation to count
+					corr := cov / math.Sqrt(varA*varB) * n / (n + minCommonBids)
+					score = math.Max(score, -corr)
+				}
+			}
+			record(a, b, score)
+		}
+	}
+	return scores
+}
+
+// poissonLogCDF returns the log of the probability of at most n events when
+// lambda are expected. The terms are summed in log space, since e^-lambda
+// underflows once lambda passes about 745, as it does for two active
+// bidders in a long run.
+func poissonLogCDF(n int, lambda float64) float64 {
+	if lambda <= 0 {
+		return 0
+	}
+	logLambda := math.Log(lambda)
+	term := -lambda
+	sum := term
+	for i := 1; i <= n; i++ {
+		term += logLambda - math.Log(float64(i))
+		hi, lo := math.Max(sum, term), math.Min(sum, term)
+		sum = hi + math.Log1p(math.Exp(lo-hi))
+	}
+	return math.Min(0, sum)
+}
+
+// rotationScore scores the share of a bidder's bids that are cover bids,
+// well below its high bids. Honest bidders vary their bids a little around
+// one level; cartel members mostly place cover bids and bid high in turn.
+func rotationScore(bids []float64) float64 {
+	high := stats.Percentile(bids, 90)
+	cover := 0
+	for _, bid := range bids {
+		if bid < high*coverRatio {
+			cover++
+		}
+	}
+	share := float64(cover) / float64(len(bids))
+	return clamp((share - 0.2) / 0.4)
+}
+
+// shillScore scores how much a bidder bids like a shill: in more auctions
+// than nearly every other bidder, close below the winning bid, and hardly
+// ever winning. Activity is measured against the other bidders, so that
+// requests lost to the concurrency limit do not count against it.
+func shillScore(book *bidBook, id string, usualBids float64) float64 {
+	bids := len(book.bidders[id])
+	activity := clamp((float64(bids)/usualBids - 1) / 0.2)
+	winless := clamp(1 - float64(book.wins[id])/float64(bids)/0.05)
+
+	lost, closeness := 0, 0.0
+	for i, auction := range book.auctions {
+		amount, ok := auction[id]
+		if !ok || book.winning[i] <= 0 || amount >= book.winning[i] {
+			continue
+		}
+		lost++
+		closeness += amount / book.winning[i]
+	}
+	if lost == 0 {
+		return 0
+	}
+	closeness /= float64(lost)
+	return activity * winless * clamp((closeness-0.75)/0.2)
+}
+
+// Evaluate scores the report against the collusion groups that were
+// simulated, and labels each bidder with its group
+func (r *RiskReport) Evaluate(groups []types.CollusionGroup) {
+	kinds := make(map[string]string)
+	groupOf := make(map[string]string)
+	members := make(map[string]int)
+	for _, group := range groups {
+		for _, member := range group.Members {
+			kinds[member] = group.Kind
+			groupOf[member] = group.ID
+			members[group.Kind]++
+		}
+	}
+
+	eval := &RiskEvaluation{Colluders: len(kinds), RecallByKind: make(map[string]float64)}
+	caught := make(map[string]int)
+
+	var colluderScores, honestScores []float64
+	scored := make(map[string]bool, len(r.Bidders))
+	for i := range r.Bidders {
+		b := &r.Bidders[i]
+		b.Group = groupOf[b.BidderID]
+		scored[b.BidderID] = true
+		kind, colluder := kinds[b.BidderID]
+		switch {
+		case colluder && b.Flagged:
+			eval.TruePositives++
+			caught[kind]++
+		case colluder:
+			eval.FalseNegatives++
+		case b.Flagged:
+			eval.FalsePositives++
+		}
+		if colluder {
+			colluderScores = append(colluderScores, b.Score)
+		} else {
+			honestScores = append(honestScores, b.Score)
+		}
+	}
+	// Colluders that never bid cannot be caught from the bid books
+	for member := range kinds {
+		if !scored[member] {
+			eval.FalseNegatives++
+			colluderScores = append(colluderScores, 0)
+		}
+	}
+
+	if flagged := eval.TruePositives + eval.FalsePositives; flagged > 0 {
+		eval.Precision = float64(eval.TruePositives) / float64(flagged)
+	}
+	if eval.Colluders > 0 {
+		eval.Recall = float64(eval.TruePositives) / float64(eval.Colluders)
+	}
+	if eval.Precision+eval.Recall > 0 {
+		eval.F1 = 2 * eval.Precision * eval.Recall / (eval.Precision + eval.Recall)
+	}
+	eval.AUC = rankAUC(colluderScores, honestScores)
+	for kind, n := range members {
+		eval.RecallByKind[kind] = float64(caught[kind]) / float64(n)
+	}
+	r.Evaluation = eval
+}
+
+// rankAUC returns the probability that a positive outscores a negative,
+// counting ties as half, or 0.5 if either side is empty
+func rankAUC(positives, negatives []float64) float64 {
+	if len(positives) == 0 || len(negatives) == 0 {
+		return 0.5
+	}
+	wins := 0.0
+	for _, p := range positives {
+		for _, n := range negatives {
+			switch {
+			case p > n:
+				wins++
+			case p == n:
+				wins += 0.5
+			}
+		}
+	}
+	return wins / float64(len(positives)*len(negatives))
+}
+
+// clamp limits v to [0, 1]
+func clamp(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+// NewRiskReport runs the collusion detectors over a run's bid books and,
+// if the run simulated collusion groups, evaluates them against those
+func NewRiskReport(report *RunReport) *RiskReport {
+	format := config.FormatSealed
+	if report.Config != nil {
+		format = report.Config.Format
+	}
+	risk := DetectCollusion(report.Results, format)
+	if report.Metrics != nil && len(report.Metrics.Groups) > 0 {
+		risk.Evaluate(report.Metrics.Groups)
+	}
+	return risk
+}
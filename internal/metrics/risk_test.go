@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/types"
+)
+
+func TestPoissonLogCDF(t *testing.T) {
+	tests := []struct {
+		n      int
+		lambda float64
+	}{
+		{0, 0.5}, {3, 2}, {10, 10}, {40, 25}, {5, 60},
+	}
+	for _, tt := range tests {
+		term := math.Exp(-tt.lambda)
+		want := term
+		for i := 1; i <= tt.n; i++ {
+			term *= tt.lambda / float64(i)
+			want += term
+		}
+		if got := math.Exp(poissonLogCDF(tt.n, tt.lambda)); math.Abs(got-want) > 1e-12*math.Max(1, want) {
+			t.Errorf("P(X <= %d | %g) = %g, want %g", tt.n, tt.lambda, got, want)
+		}
+	}
+
+	// Far past where e^-lambda underflows the tail still has a magnitude
+	if got := poissonLogCDF(1500, 1620); math.IsInf(got, 0) || math.IsNaN(got) || got >= 0 {
+		t.Errorf("log P(X <= 1500 | 1620) = %g, want a finite negative number", got)
+	}
+	if got := poissonLogCDF(2000, 1620); got < -1e-9 {
+		t.Errorf("log P(X <= 2000 | 1620) = %g, want about 0", got)
+	}
+}
+
+func TestIndependentBiddersAreNotFlagged(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	const bidders, auctions = 12, 2000
+
+	chance := make([]float64, bidders)
+	base := make([]float64, bidders)
+	for i := range chance {
+		chance[i] = 0.6 + 0.35*rng.Float64()
+		base[i] = 80 + 40*rng.Float64()
+	}
+
+	results := make([]*types.AuctionResult, auctions)
+	for a := range results {
+		result := &types.AuctionResult{AuctionID: fmt.Sprintf("auction-%d", a+1)}
+		for i := range chance {
+			if rng.Float64() > chance[i] {
+				continue
+			}
+			bid := types.Bid{BidderID: fmt.Sprintf("bidder-%d", i+1), Amount: base[i] + 10*rng.NormFloat64()}
+			result.Bids = append(result.Bids, bid)
+			if result.Winner == nil || bid.Amount > result.Winner.Amount {
+				result.Winner = &bid
+			}
+		}
+		if result.Winner != nil {
+			result.ClearingPrice = result.Winner.Amount
+		}
+		results[a] = result
+	}
+
+	report := DetectCollusion(results, config.FormatSealed)
+	for _, b := range report.Bidders {
+		if b.Flagged {
+			t.Errorf("%s flagged with score %.2f (correlation %.2f, rotation %.2f, shill %.2f, partners %v)",
+				b.BidderID, b.Score, b.Correlation, b.Rotation, b.Shill, b.Partners)
+		}
+	}
+}
+
+func TestHonestOpenAuctionsAreNotFlagged(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	const bidders, auctions = 12, 500
+
+	base := make([]float64, bidders)
+	for i := range base {
+		base[i] = 80 + 40*rng.Float64()
+	}
+
+	// Bidders take turns raising the standing bid while it is below their
+	// value, so a bidder's highest bid is wherever the others left it
+	results := make([]*types.AuctionResult, auctions)
+	for a := range results {
+		result := &types.AuctionResult{AuctionID: fmt.Sprintf("auction-%d", a+1)}
+		values := make([]float64, bidders)
+		for i := range values {
+			values[i] = base[i] + 10*rng.NormFloat64()
+		}
+		standing, leader := 10.0, -1
+		for raised := true; raised; {
+			raised = false
+			for _, i := range rng.Perm(bidders) {
+				amount := standing + 1 + 10*rng.Float64()
+				if i == leader || amount > values[i] {
+					continue
+				}
+				bid := types.Bid{BidderID: fmt.Sprintf("bidder-%d", i+1), Amount: amount}
+				result.Bids = append(result.Bids, bid)
+				result.Winner = &bid
+				standing, leader, raised = amount, i, true
+			}
+		}
+		if result.Winner != nil {
+			result.ClearingPrice = result.Winner.Amount
+		}
+		results[a] = result
+	}
+
+	report := DetectCollusion(results, config.FormatOpen)
+	for _, b := range report.Bidders {
+		if b.Flagged {
+			t.Errorf("%s flagged with score %.2f (correlation %.2f, rotation %.2f, shill %.2f, partners %v)",
+				b.BidderID, b.Score, b.Correlation, b.Rotation, b.Shill, b.Partners)
+		}
+	}
+}
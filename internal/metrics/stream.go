@@ -0,0 +1,174 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/stats"
+	"auction-simulator/internal/types"
+)
+
+// StreamMetrics describes a streaming run, where auctions arrive over time
+type StreamMetrics struct {
+	Process         string        `json:"process"`
+	Window          time.Duration `json:"window"`
+	Arrivals        int           `json:"arrivals"`
+	Completed       int           `json:"completed"`
+	OfferedRate     float64       `json:"offered_rate_per_s"`
+	Throughput      float64       `json:"throughput_per_s"`
+	QueueDelayP50MS float64       `json:"queue_delay_p50_ms"`
+	QueueDelayP90MS float64       `json:"queue_delay_p90_ms"`
+	QueueDelayP99MS float64       `json:"queue_delay_p99_ms"`
+	QueueDelayMaxMS float64       `json:"queue_delay_max_ms"`
+	MaxInFlight     int           `json:"max_in_flight"`
+	Steady          SteadyState   `json:"steady_state"`
+}
+
+// SteadyState summarizes auctions that arrived after the warm-up period
+type SteadyState struct {
+	From              time.Duration `json:"from"` // offset of the window start from the first arrival
+	Auctions          int           `json:"auctions"`
+	Throughput        float64       `json:"throughput_per_s"`
+	SuccessRate       float64       `json:"success_rate"`
+	RevenuePerAuction float64       `json:"revenue_per_auction"`
+	BidsPerAuction    float64       `json:"bids_per_auction"`
+	LatencyP50MS      float64       `json:"latency_p50_ms"`
+	LatencyP99MS      float64       `json:"latency_p99_ms"`
+	QueueDelayP50MS   float64       `json:"queue_delay_p50_ms"`
+	QueueDelayP99MS   float64       `json:"queue_delay_p99_ms"`
+}
+
+// ApplyStream fills the streaming statistics of a run. Results must be in
+// arrival order, as returned by the orchestrator.
+func (m *SimulationMetrics) ApplyStream(results []*types.AuctionResult, cfg config.ArrivalConfig) {
+	stream := &StreamMetrics{
+		Process:     cfg.Process,
+		Arrivals:    len(results),
+		MaxInFlight: maxInFlight(results),
+	}
+	m.Stream = stream
+	if len(results) == 0 {
+		return
+	}
+
+	first := arrivalTime(results[0])
+	stream.Window = cfg.Duration
+	if stream.Window <= 0 {
+		stream.Window = arrivalTime(results[len(results)-1]).Sub(first)
+	}
+
+	var delays []float64
+	var lastEnd time.Time
+	for _, result := range results {
+		delays = append(delays, durationMS(result.QueueDelay))
+		if result.Error == nil {
+			stream.Completed++
+		}
+		if result.EndTime.After(lastEnd) {
+			lastEnd = result.EndTime
+		}
+	}
+
+	if secs := stream.Window.Seconds(); secs > 0 {
+		stream.OfferedRate = float64(len(results)) / secs
+	}
+	if secs := lastEnd.Sub(first).Seconds(); secs > 0 {
+		stream.Throughput = float64(stream.Completed) / secs
+	}
+	stream.QueueDelayP50MS = stats.Percentile(delays, 50)
+	stream.QueueDelayP90MS = stats.Percentile(delays, 90)
+	stream.QueueDelayP99MS = stats.Percentile(delays, 99)
+	stream.QueueDelayMaxMS = stats.Percentile(delays, 100)
+
+	stream.Steady = steadyState(results, first, stream.Window, cfg.WarmUp)
+}
+
+// steadyState summarizes the auctions that arrived after the warm-up
+// fraction of the window, when queues and limiters have settled
+func steadyState(results []*types.AuctionResult, first time.Time, window time.Duration, warmUp float64) SteadyState {
+	steady := SteadyState{From: time.Duration(float64(window) * warmUp)}
+	from := first.Add(steady.From)
+	to := first.Add(window)
+
+	var selected []*types.AuctionResult
+	var delays []float64
+	completed := 0
+	revenue := 0.0
+	bids := 0
+	for _, result := range results {
+		if arrivalTime(result).Before(from) {
+			continue
+		}
+		selected = append(selected, result)
+		delays = append(delays, durationMS(result.QueueDelay))
+		if result.Error == nil {
+			completed++
+		}
+		revenue += Revenue(result)
+		bids += result.TotalBids
+	}
+
+	steady.Auctions = len(selected)
+	if steady.Auctions == 0 {
+		return steady
+	}
+
+	finishedInWindow := 0
+	for _, result := range results {
+		if result.Error == nil && !result.EndTime.Before(from) && !result.EndTime.After(to) {
+			finishedInWindow++
+		}
+	}
+	if secs := to.Sub(from).Seconds(); secs > 0 {
+		steady.Throughput = float64(finishedInWindow) / secs
+	}
+
+	steady.SuccessRate = float64(completed) / float64(steady.Auctions)
+	steady.RevenuePerAuction = revenue / float64(steady.Auctions)
+	steady.BidsPerAuction = float64(bids) / float64(steady.Auctions)
+
+	latencies := BidLatenciesMS(selected)
+	steady.LatencyP50MS = stats.Percentile(latencies, 50)
+	steady.LatencyP99MS = stats.Percentile(latencies, 99)
+	steady.QueueDelayP50MS = stats.Percentile(delays, 50)
+	steady.QueueDelayP99MS = stats.Percentile(delays, 99)
+	return steady
+}
+
+// arrivalTime is when an auction arrived, before any queueing
+func arrivalTime(result *types.AuctionResult) time.Time {
+	return result.StartTime.Add(-result.QueueDelay)
+}
+
+// maxInFlight returns the most auctions that were running at the same time
+func maxInFlight(results []*types.AuctionResult) int {
+	type event struct {
+		at    time.Time
+		delta int
+	}
+	events := make([]event, 0, 2*len(results))
+	for _, result := range results {
+		events = append(events, event{result.StartTime, 1}, event{result.EndTime, -1})
+	}
+	// Ends sort before starts at the same instant so back-to-back auctions
+	// do not count as overlapping
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].at.Equal(events[j].at) {
+			return events[i].delta < events[j].delta
+		}
+		return events[i].at.Before(events[j].at)
+	})
+
+	running, peak := 0, 0
+	for _, e := range events {
+		running += e.delta
+		peak = max(peak, running)
+	}
+	return peak
+}
+
+// durationMS converts a duration to fractional milliseconds
+func durationMS(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,184 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"auction-simulator/internal/stats"
+	"auction-simulator/internal/types"
+)
+
+// NewRunID returns a sortable, unique identifier for a simulation run
+func NewRunID() string {
+	return newID("run")
+}
+
+// ApplyResults fills the auction statistics derived from a run's results
+func (m *SimulationMetrics) ApplyResults(results []*types.AuctionResult) {
+	m.SuccessfulAuctions = 0
+	m.TotalBidsReceived = 0
+	m.TotalRevenue = 0
+	m.Faults = nil
+	m.Rejections = nil
+	m.TiedAuctions = 0
+	m.Extensions = 0
+	m.ExtendedAuctions = 0
+
+	for _, result := range results {
+		if result.Tie != nil {
+			m.TiedAuctions++
+		}
+		if result.Extensions > 0 {
+			m.Extensions += result.Extensions
+			m.ExtendedAuctions++
+		}
+		for kind, n := range result.Faults {
+			if m.Faults == nil {
+				m.Faults = make(map[string]int)
+			}
+			m.Faults[kind] += n
+		}
+		for _, rejected := range result.Rejected {
+			if m.Rejections == nil {
+				m.Rejections = make(map[string]int)
+			}
+			m.Rejections[rejected.Reason]++
+		}
+		if result.Error == nil {
+			m.SuccessfulAuctions++
+		}
+		m.TotalBidsReceived += result.TotalBids
+		m.TotalRevenue += Revenue(result)
+	}
+
+	m.FailedAuctions = len(results) - m.SuccessfulAuctions
+	if len(results) > 0 {
+		m.AverageBidsPerAuction = float64(m.TotalBidsReceived) / float64(len(results))
+	}
+
+	latencies := BidLatenciesMS(results)
+	m.LatencyP50MS = stats.Percentile(latencies, 50)
+	m.LatencyP90MS = stats.Percentile(latencies, 90)
+	m.LatencyP99MS = stats.Percentile(latencies, 99)
+}
+
+// NewBatchID returns a unique identifier for a batch of runs
+func NewBatchID() string {
+	return newID("batch")
+}
+
+// newID builds a timestamped identifier with a random suffix so that runs
+// started in the same second, e.g. in parallel, do not collide
+func newID(prefix string) string {
+	return fmt.Sprintf("%s-%s-%08x", prefix, time.Now().Format("20060102-150405"), rand.Uint32())
+}
+
+// Revenue returns the amount collected by the seller for an auction
+func Revenue(result *types.AuctionResult) float64 {
+	if result == nil || result.Winner == nil {
+		return 0
+	}
+	return result.ClearingPrice
+}
+
+// WinShares returns who won an auction and what each of them paid: the
+// winner alone, or every tied bidder of a split allocation
+func WinShares(result *types.AuctionResult) []types.Share {
+	if result == nil || result.Winner == nil {
+		return nil
+	}
+	if result.Tie != nil && len(result.Tie.Shares) > 0 {
+		return result.Tie.Shares
+	}
+	return []types.Share{{BidderID: result.Winner.BidderID, Fraction: 1, Price: Revenue(result)}}
+}
+
+// AllBids flattens the bid books of every auction into a single slice
+func AllBids(results []*types.AuctionResult) []types.Bid {
+	total := 0
+	for _, result := range results {
+		total += len(result.Bids)
+	}
+
+	bids := make([]types.Bid, 0, total)
+	for _, result := range results {
+		bids = append(bids, result.Bids...)
+	}
+	return bids
+}
+
+// AuctionRevenues returns the revenue of each auction in result order
+func AuctionRevenues(results []*types.AuctionResult) []float64 {
+	revenues := make([]float64, len(results))
+	for i, result := range results {
+		revenues[i] = Revenue(result)
+	}
+	return revenues
+}
+
+// BidLatenciesMS returns every bid's response latency in milliseconds
+func BidLatenciesMS(results []*types.AuctionResult) []float64 {
+	var latencies []float64
+	for _, result := range results {
+		for _, bid := range result.Bids {
+			latencies = append(latencies, float64(bid.Latency)/float64(time.Millisecond))
+		}
+	}
+	return latencies
+}
+
+// WinCounts returns how many auctions each bidder won
+func WinCounts(results []*types.AuctionResult) map[string]int {
+	wins := make(map[string]int)
+	for _, result := range results {
+		for _, share := range WinShares(result) {
+			wins[share.BidderID]++
+		}
+	}
+	return wins
+}
+
+// ComputeBidderStats aggregates per-bidder activity across all auctions
+func ComputeBidderStats(results []*types.AuctionResult) []BidderStats {
+	byBidder := make(map[string]*BidderStats)
+	latencyTotals := make(map[string]float64)
+
+	for _, result := range results {
+		for _, bid := range result.Bids {
+			stats, ok := byBidder[bid.BidderID]
+			if !ok {
+				stats = &BidderStats{BidderID: bid.BidderID}
+				byBidder[bid.BidderID] = stats
+			}
+			stats.Bids++
+			stats.AverageBid += bid.Amount
+			stats.MaxBid = math.Max(stats.MaxBid, bid.Amount)
+			latencyTotals[bid.BidderID] += float64(bid.Latency) / float64(time.Millisecond)
+		}
+
+		for _, share := range WinShares(result) {
+			if stats, ok := byBidder[share.BidderID]; ok {
+				stats.Wins++
+				stats.TotalSpend += share.Price
+			}
+		}
+	}
+
+	stats := make([]BidderStats, 0, len(byBidder))
+	for id, s := range byBidder {
+		if s.Bids > 0 {
+			s.AverageBid /= float64(s.Bids)
+			s.AverageLatencyMS = latencyTotals[id] / float64(s.Bids)
+			s.WinRate = float64(s.Wins) / float64(s.Bids)
+		}
+		stats = append(stats, *s)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].BidderID < stats[j].BidderID
+	})
+	return stats
+}
@@ -1,9 +1,17 @@
 package metrics
 
-import "time"
+import (
+	"time"
+
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/types"
+)
 
 // SimulationMetrics holds overall simulation metrics
 type SimulationMetrics struct {
+	RunID         string        `json:"run_id"`
+	BatchID       string        `json:"batch_id,omitempty"`
+	Seed          int64         `json:"seed"`
 	TotalAuctions int           `json:"total_auctions"`
 	TotalBidders  int           `json:"total_bidders"`
 	StartTime     time.Time     `json:"start_time"`
@@ -19,6 +27,56 @@ type SimulationMetrics struct {
 	FailedAuctions        int     `json:"failed_auctions"`
 	TotalBidsReceived     int     `json:"total_bids_received"`
 	AverageBidsPerAuction float64 `json:"average_bids_per_auction"`
+	TotalRevenue          float64 `json:"total_revenue"`
+
+	// Bidder response latency
+	LatencyP50MS float64 `json:"latency_p50_ms"`
+	LatencyP90MS float64 `json:"latency_p90_ms"`
+	LatencyP99MS float64 `json:"latency_p99_ms"`
+
+	// Concurrency limiter range and final value
+	ConcurrencyLimitMin   int `json:"concurrency_limit_min"`
+	ConcurrencyLimitMax   int `json:"concurrency_limit_max"`
+	ConcurrencyLimitFinal int `json:"concurrency_limit_final"`
+
+	// Soft-close extensions of open auctions, and how many auctions had any
+	Extensions       int `json:"extensions,omitempty"`
+	ExtendedAuctions int `json:"extended_auctions,omitempty"`
+
+	// Win, loss and billing notices delivered to bidders, by kind, and the
+	// notices that could not be delivered
+	Notices        map[string]int `json:"notices,omitempty"`
+	NoticeFailures int            `json:"notice_failures,omitempty"`
+
+	// Mean shade learning bidders settled on, by strategy
+	LearnedShades map[string]float64 `json:"learned_shades,omitempty"`
+
+	// Bidder groups that colluded, the ground truth for collusion detection
+	Groups []types.CollusionGroup `json:"groups,omitempty"`
+
+	// Auctions whose top bid was tied
+	TiedAuctions int `json:"tied_auctions,omitempty"`
+
+	// Bids rejected by validation, by reason
+	Rejections map[string]int `json:"rejections,omitempty"`
+
+	// Failed bidder requests by fault kind, and the faults injected for
+	// chaos testing
+	Faults         map[string]int `json:"faults,omitempty"`
+	FaultsInjected map[string]int `json:"faults_injected,omitempty"`
+
+	// Worker processes of a distributed run
+	Workers int `json:"workers,omitempty"`
+
+	// Arrival statistics of streaming runs
+	Stream *StreamMetrics `json:"stream,omitempty"`
+
+	// How the items of a catalog sold, for runs that auction one
+	Inventory *InventoryMetrics `json:"inventory,omitempty"`
+
+	// Set when the run ended early, with the reason
+	Incomplete bool   `json:"incomplete,omitempty"`
+	Failure    string `json:"failure,omitempty"`
 }
 
 // ResourceUsage tracks system resource consumption
@@ -28,3 +86,23 @@ type ResourceUsage struct {
 	MemoryMB       float64   `json:"memory_mb"`
 	CPUPercent     float64   `json:"cpu_percent"`
 }
+
+// BidderStats summarizes a single bidder's activity over a run
+type BidderStats struct {
+	BidderID         string  `json:"bidder_id" parquet:"bidder_id"`
+	Bids             int     `json:"bids" parquet:"bids"`
+	Wins             int     `json:"wins" parquet:"wins"`
+	WinRate          float64 `json:"win_rate" parquet:"win_rate"`
+	TotalSpend       float64 `json:"total_spend" parquet:"total_spend"`
+	AverageBid       float64 `json:"average_bid" parquet:"average_bid"`
+	MaxBid           float64 `json:"max_bid" parquet:"max_bid"`
+	AverageLatencyMS float64 `json:"average_latency_ms" parquet:"average_latency_ms"`
+}
+
+// RunReport bundles everything needed to render a self-contained run report
+type RunReport struct {
+	Config    *config.Config
+	Metrics   *SimulationMetrics
+	Results   []*types.AuctionResult
+	Resources []ResourceUsage
+}
@@ -0,0 +1,245 @@
+package population
+
+import (
+	"math"
+
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/stats"
+)
+
+// distribution is a parameter distribution that can be sampled by
+// inverting its CDF and scored against observed values
+type distribution interface {
+	cdf(x float64) float64
+	quantile(p float64) float64
+	logPDF(x float64) float64
+}
+
+// newDistribution builds the distribution a validated spec describes
+func newDistribution(spec *config.DistributionSpec) distribution {
+	switch spec.Kind {
+	case config.DistNormal:
+		return normal{mu: spec.Mean, sigma: spec.StdDev}
+	case config.DistLogNormal:
+		return logNormal{mu: spec.Mean, sigma: spec.StdDev}
+	case config.DistBeta:
+		return beta{alpha: spec.Alpha, beta: spec.Beta, lo: spec.Min, hi: spec.Max}
+	case config.DistEmpirical:
+		return newHistogram(spec.Bins, spec.Counts)
+	case config.DistMixture:
+		m := mixture{weights: normalized(spec.Weights)}
+		for i := range spec.Components {
+			m.components = append(m.components, bounded(&spec.Components[i]))
+		}
+		return m
+	default:
+		return uniform{lo: spec.Min, hi: spec.Max}
+	}
+}
+
+// bounded is the distribution of a spec with values clamped to its bounds
+func bounded(spec *config.DistributionSpec) distribution {
+	d := newDistribution(spec)
+	if spec.Max <= spec.Min || spec.Kind == config.DistUniform || spec.Kind == config.DistBeta {
+		return d
+	}
+	return clamped{distribution: d, lo: spec.Min, hi: spec.Max}
+}
+
+// clamped moves values of a distribution outside [lo, hi] to the nearest
+// bound
+type clamped struct {
+	distribution
+	lo, hi float64
+}
+
+func (c clamped) quantile(p float64) float64 {
+	return math.Max(c.lo, math.Min(c.hi, c.distribution.quantile(p)))
+}
+
+type uniform struct{ lo, hi float64 }
+
+func (u uniform) cdf(x float64) float64 {
+	return math.Max(0, math.Min(1, (x-u.lo)/(u.hi-u.lo)))
+}
+
+func (u uniform) quantile(p float64) float64 {
+	return u.lo + p*(u.hi-u.lo)
+}
+
+func (u uniform) logPDF(x float64) float64 {
+	if x < u.lo || x > u.hi {
+		return math.Inf(-1)
+	}
+	return -math.Log(u.hi - u.lo)
+}
+
+type normal struct{ mu, sigma float64 }
+
+func (n normal) cdf(x float64) float64 {
+	return 0.5 * math.Erfc(-(x-n.mu)/(n.sigma*math.Sqrt2))
+}
+
+func (n normal) quantile(p float64) float64 {
+	return n.mu + n.sigma*normalQuantile(p)
+}
+
+func (n normal) logPDF(x float64) float64 {
+	z := (x - n.mu) / n.sigma
+	return -z*z/2 - math.Log(n.sigma*math.Sqrt(2*math.Pi))
+}
+
+// logNormal has a normal logarithm with mean mu and deviation sigma
+type logNormal struct{ mu, sigma float64 }
+
+func (l logNormal) cdf(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return normal(l).cdf(math.Log(x))
+}
+
+func (l logNormal) quantile(p float64) float64 {
+	return math.Exp(normal(l).quantile(p))
+}
+
+func (l logNormal) logPDF(x float64) float64 {
+	if x <= 0 {
+		return math.Inf(-1)
+	}
+	return normal(l).logPDF(math.Log(x)) - math.Log(x)
+}
+
+// beta is a beta distribution stretched over [lo, hi]
+type beta struct{ alpha, beta, lo, hi float64 }
+
+func (b beta) cdf(x float64) float64 {
+	return stats.RegularizedBeta((x-b.lo)/(b.hi-b.lo), b.alpha, b.beta)
+}
+
+func (b beta) quantile(p float64) float64 {
+	return bisect(b.cdf, p, b.lo, b.hi)
+}
+
+func (b beta) logPDF(x float64) float64 {
+	// Observed values on the bounds would have no or infinite density
+	z := math.Max(1e-9, math.Min(1-1e-9, (x-b.lo)/(b.hi-b.lo)))
+	la, _ := math.Lgamma(b.alpha)
+	lb, _ := math.Lgamma(b.beta)
+	lab, _ := math.Lgamma(b.alpha + b.beta)
+	return (b.alpha-1)*math.Log(z) + (b.beta-1)*math.Log(1-z) + lab - la - lb - math.Log(b.hi-b.lo)
+}
+
+// histogram spreads each bin's share evenly across the bin
+type histogram struct {
+	edges []float64
+	cum   []float64 // share of values below each edge
+}
+
+func newHistogram(edges, counts []float64) histogram {
+	shares := normalized(counts)
+	h := histogram{edges: edges, cum: make([]float64, len(edges))}
+	for i, share := range shares {
+		h.cum[i+1] = h.cum[i] + share
+	}
+	return h
+}
+
+func (h histogram) cdf(x float64) float64 {
+	if x <= h.edges[0] {
+		return 0
+	}
+	for i := 1; i < len(h.edges); i++ {
+		if x < h.edges[i] {
+			frac := (x - h.edges[i-1]) / (h.edges[i] - h.edges[i-1])
+			return h.cum[i-1] + frac*(h.cum[i]-h.cum[i-1])
+		}
+	}
+	return 1
+}
+
+func (h histogram) quantile(p float64) float64 {
+	for i := 1; i < len(h.edges); i++ {
+		if p <= h.cum[i] && h.cum[i] > h.cum[i-1] {
+			frac := (p - h.cum[i-1]) / (h.cum[i] - h.cum[i-1])
+			return h.edges[i-1] + math.Max(0, frac)*(h.edges[i]-h.edges[i-1])
+		}
+	}
+	return h.edges[len(h.edges)-1]
+}
+
+func (h histogram) logPDF(x float64) float64 {
+	for i := 1; i < len(h.edges); i++ {
+		if x <= h.edges[i] && x >= h.edges[i-1] {
+			return math.Log((h.cum[i] - h.cum[i-1]) / (h.edges[i] - h.edges[i-1]))
+		}
+	}
+	return math.Inf(-1)
+}
+
+// mixture draws from one of its components, picked by weight
+type mixture struct {
+	components []distribution
+	weights    []float64 // sum to 1
+}
+
+func (m mixture) cdf(x float64) float64 {
+	total := 0.0
+	for i, c := range m.components {
+		total += m.weights[i] * c.cdf(x)
+	}
+	return total
+}
+
+func (m mixture) quantile(p float64) float64 {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, c := range m.components {
+		lo = math.Min(lo, c.quantile(1e-9))
+		hi = math.Max(hi, c.quantile(1-1e-9))
+	}
+	return bisect(m.cdf, p, lo, hi)
+}
+
+func (m mixture) logPDF(x float64) float64 {
+	total := 0.0
+	for i, c := range m.components {
+		total += m.weights[i] * math.Exp(c.logPDF(x))
+	}
+	return math.Log(total)
+}
+
+// normalQuantile is the inverse of the standard normal CDF
+func normalQuantile(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
+
+// normalCDF is the standard normal CDF
+func normalCDF(z float64) float64 {
+	return 0.5 * math.Erfc(-z/math.Sqrt2)
+}
+
+// bisect finds x in [lo, hi] with cdf(x) = p
+func bisect(cdf func(float64) float64, p, lo, hi float64) float64 {
+	for i := 0; i < 100 && hi-lo > 1e-9*math.Max(1, math.Abs(hi)); i++ {
+		mid := (lo + hi) / 2
+		if cdf(mid) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// normalized scales weights to sum to 1
+func normalized(weights []float64) []float64 {
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+	shares := make([]float64, len(weights))
+	for i, w := range weights {
+		shares[i] = w / total
+	}
+	return shares
+}
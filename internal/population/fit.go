@@ -0,0 +1,477 @@
+package population
+
+import (
+	"encoding/csv"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/stats"
+	"auction-simulator/internal/types"
+)
+
+// Fit kinds beyond the distributions themselves
+const (
+	FitAuto = "auto" // the distribution with the lowest BIC of normal, lognormal, beta and mixture
+)
+
+// Bid is an entry of a recorded bid log
+type Bid struct {
+	AuctionID string
+	BidderID  string
+	Amount    float64
+	LatencyMS float64
+}
+
+// Bids returns the bids of a run's auctions, every raise of an open
+// auction included
+func Bids(results []*types.AuctionResult) []Bid {
+	var bids []Bid
+	for _, result := range results {
+		for _, bid := range result.Bids {
+			bids = append(bids, Bid{
+				AuctionID: result.AuctionID,
+				BidderID:  bid.BidderID,
+				Amount:    bid.Amount,
+				LatencyMS: float64(bid.Latency) / float64(time.Millisecond),
+			})
+		}
+	}
+	return bids
+}
+
+// LoadBidLog reads a CSV bid log with a header row naming at least the
+// auction_id, bidder_id, amount and latency_ms columns, as bids are
+// exported
+func LoadBidLog(path string) ([]Bid, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open bid log: %w", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("could not read bid log %s: %w", path, err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("bid log %s has no bids", path)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"auction_id", "bidder_id", "amount", "latency_ms"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("bid log %s has no %s column", path, name)
+		}
+	}
+
+	bids := make([]Bid, 0, len(records)-1)
+	for line, record := range records[1:] {
+		amount, err := strconv.ParseFloat(record[columns["amount"]], 64)
+		if err != nil {
+			return nil, fmt.Errorf("bid log %s line %d: invalid amount", path, line+2)
+		}
+		latency, err := strconv.ParseFloat(record[columns["latency_ms"]], 64)
+		if err != nil {
+			return nil, fmt.Errorf("bid log %s line %d: invalid latency", path, line+2)
+		}
+		bids = append(bids, Bid{
+			AuctionID: record[columns["auction_id"]],
+			BidderID:  record[columns["bidder_id"]],
+			Amount:    amount,
+			LatencyMS: latency,
+		})
+	}
+	return bids, nil
+}
+
+// Profile is one bidder's parameters as estimated from its bids
+type Profile struct {
+	BidderID string
+	Bids     int
+	Params   map[string]float64 // only those the bids can tell
+}
+
+// Profiles estimates each bidder's parameters the way the simulator uses
+// them: the share of the log's auctions it bid in, its mean bid, the width
+// of the uniform spread that matches its bids' deviation, and its fastest
+// response, since queueing only ever adds to a response time. A bidder that
+// bid several times in an auction, raising its bid in an open auction,
+// counts once with its highest bid, which is the closest the log comes to
+// what the item was worth to it.
+func Profiles(bids []Bid) []Profile {
+	auctions := make(map[string]bool)
+	byBidder := make(map[string]map[string]Bid) // highest bid per auction
+	for _, bid := range bids {
+		auctions[bid.AuctionID] = true
+		own := byBidder[bid.BidderID]
+		if own == nil {
+			own = make(map[string]Bid)
+			byBidder[bid.BidderID] = own
+		}
+		if prev, ok := own[bid.AuctionID]; ok {
+			bid.Amount = math.Max(bid.Amount, prev.Amount)
+			bid.LatencyMS = math.Min(bid.LatencyMS, prev.LatencyMS)
+		}
+		own[bid.AuctionID] = bid
+	}
+
+	profiles := make([]Profile, 0, len(byBidder))
+	for id, own := range byBidder {
+		amounts := make([]float64, 0, len(own))
+		latencies := make([]float64, 0, len(own))
+		for _, auctionID := range slices.Sorted(maps.Keys(own)) {
+			amounts = append(amounts, own[auctionID].Amount)
+			latencies = append(latencies, own[auctionID].LatencyMS)
+		}
+
+		profile := Profile{BidderID: id, Bids: len(own), Params: map[string]float64{
+			config.ParamBidChance: float64(len(own)) / float64(len(auctions)),
+			config.ParamBaseBid:   stats.Mean(amounts),
+			config.ParamSpeed:     slices.Min(latencies),
+		}}
+		if len(own) > 1 {
+			profile.Params[config.ParamBidRange] = math.Sqrt(12) * stats.StdDev(amounts)
+		}
+		profiles = append(profiles, profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].BidderID < profiles[j].BidderID })
+	return profiles
+}
+
+// ParamFit describes the distribution fitted to a parameter
+type ParamFit struct {
+	Param         string  `json:"param"`
+	Bidders       int     `json:"bidders"`
+	Kind          string  `json:"kind"`
+	LogLikelihood float64 `json:"log_likelihood"`
+	BIC           float64 `json:"bic"`
+}
+
+// Result is a population fitted to bidder profiles
+type Result struct {
+	Population config.PopulationConfig `json:"population"`
+	Bidders    int                     `json:"bidders"`
+	Fits       []ParamFit              `json:"fits"`
+}
+
+// minSamples is the fewest bidders a parameter is fitted to
+const minSamples = 5
+
+// physicalBounds are the values a parameter can take at all
+var physicalBounds = map[string][2]float64{
+	config.ParamBidChance: {0, 1},
+	config.ParamBaseBid:   {0, math.Inf(1)},
+	config.ParamBidRange:  {0, math.Inf(1)},
+	config.ParamSpeed:     {0, math.Inf(1)},
+}
+
+// Fit fits a distribution of the given kind, or FitAuto, to each parameter
+// of the profiled bidders, and the correlations between the parameters.
+// Profiles of several logs can be pooled, as long as each was estimated
+// from its own log. Fitted distributions are bounded a little beyond the
+// observed range, so synthetic bidders stay close to real ones.
+func Fit(profiles []Profile, kind string) (*Result, error) {
+	switch kind {
+	case FitAuto, config.DistUniform, config.DistNormal, config.DistLogNormal,
+		config.DistBeta, config.DistEmpirical, config.DistMixture:
+	default:
+		return nil, fmt.Errorf("unknown distribution %q", kind)
+	}
+
+	result := &Result{
+		Population: config.PopulationConfig{Params: make(map[string]config.DistributionSpec)},
+		Bidders:    len(profiles),
+	}
+
+	for _, param := range config.PopulationParams {
+		var samples []float64
+		for _, profile := range profiles {
+			if v, ok := profile.Params[param]; ok {
+				samples = append(samples, v)
+			}
+		}
+		if len(samples) < minSamples {
+			continue
+		}
+		spec, fit := fitParam(samples, kind, physicalBounds[param])
+		fit.Param = param
+		result.Population.Params[param] = spec
+		result.Fits = append(result.Fits, fit)
+	}
+	if len(result.Fits) == 0 {
+		return nil, fmt.Errorf("too few bidders to fit: need %d, have %d", minSamples, len(profiles))
+	}
+
+	result.Population.Correlations = correlations(profiles, result.Population.Params)
+	if err := consistent(&result.Population); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// fitParam fits samples with a distribution of the given kind, or the
+// best by BIC
+func fitParam(samples []float64, kind string, physical [2]float64) (config.DistributionSpec, ParamFit) {
+	low, high := slices.Min(samples), slices.Max(samples)
+	pad := 0.1 * (high - low)
+	if pad == 0 {
+		pad = math.Max(1e-6, 0.01*math.Abs(low))
+	}
+	lo, hi := math.Max(physical[0], low-pad), math.Min(physical[1], high+pad)
+
+	candidates := []string{kind}
+	if kind == FitAuto {
+		candidates = []string{config.DistNormal, config.DistLogNormal, config.DistBeta, config.DistMixture}
+	}
+
+	var best config.DistributionSpec
+	bestFit := ParamFit{Bidders: len(samples), BIC: math.Inf(1)}
+	for _, candidate := range candidates {
+		spec, params, ok := fitKind(candidate, samples, lo, hi)
+		if !ok {
+			continue
+		}
+		ll := 0.0
+		d := newDistribution(&spec)
+		for _, x := range samples {
+			ll += d.logPDF(x)
+		}
+		bic := float64(params)*math.Log(float64(len(samples))) - 2*ll
+		if bic < bestFit.BIC || bestFit.Kind == "" {
+			best = spec
+			bestFit.Kind, bestFit.LogLikelihood, bestFit.BIC = candidate, ll, bic
+		}
+	}
+	if bestFit.Kind == "" {
+		// Every candidate needs more spread than the samples have
+		best, _, _ = fitKind(config.DistUniform, samples, lo, hi)
+		bestFit.Kind = config.DistUniform
+	}
+	return best, bestFit
+}
+
+// fitKind estimates a distribution of one kind bounded by [lo, hi] and
+// returns how many parameters it estimated, or false if the samples do not
+// suit it
+func fitKind(kind string, samples []float64, lo, hi float64) (config.DistributionSpec, int, bool) {
+	mean, sd := stats.Mean(samples), stats.StdDev(samples)
+	switch kind {
+	case config.DistUniform:
+		return config.DistributionSpec{Kind: kind, Min: lo, Max: hi}, 2, true
+	case config.DistNormal:
+		if sd == 0 {
+			return config.DistributionSpec{}, 0, false
+		}
+		return config.DistributionSpec{Kind: kind, Min: lo, Max: hi, Mean: mean, StdDev: sd}, 2, true
+	case config.DistLogNormal:
+		logs := make([]float64, len(samples))
+		for i, x := range samples {
+			if x <= 0 {
+				return config.DistributionSpec{}, 0, false
+			}
+			logs[i] = math.Log(x)
+		}
+		logSD := stats.StdDev(logs)
+		if logSD == 0 {
+			return config.DistributionSpec{}, 0, false
+		}
+		return config.DistributionSpec{Kind: kind, Min: lo, Max: hi, Mean: stats.Mean(logs), StdDev: logSD}, 2, true
+	case config.DistBeta:
+		// Method of moments on the values rescaled to [0, 1]
+		m, v := (mean-lo)/(hi-lo), (sd/(hi-lo))*(sd/(hi-lo))
+		common := m*(1-m)/v - 1
+		if v == 0 || common <= 0 {
+			return config.DistributionSpec{}, 0, false
+		}
+		return config.DistributionSpec{Kind: kind, Min: lo, Max: hi, Alpha: m * common, Beta: (1 - m) * common}, 2, true
+	case config.DistEmpirical:
+		bins := int(math.Ceil(math.Log2(float64(len(samples))))) + 1
+		spec := config.DistributionSpec{Kind: kind, Min: lo, Max: hi,
+			Bins: make([]float64, bins+1), Counts: make([]float64, bins)}
+		for i := range spec.Bins {
+			spec.Bins[i] = lo + (hi-lo)*float64(i)/float64(bins)
+		}
+		for _, x := range samples {
+			spec.Counts[min(bins-1, int((x-lo)/(hi-lo)*float64(bins)))]++
+		}
+		return spec, bins - 1, true
+	case config.DistMixture:
+		return fitMixture(samples, lo, hi)
+	}
+	return config.DistributionSpec{}, 0, false
+}
+
+// mixtureIterations bounds the EM iterations of a mixture fit
+const mixtureIterations = 200
+
+// fitMixture fits a mixture of two normals by expectation maximization,
+// starting from the halves below and above the median
+func fitMixture(samples []float64, lo, hi float64) (config.DistributionSpec, int, bool) {
+	if len(samples) < 2*minSamples {
+		return config.DistributionSpec{}, 0, false
+	}
+	sorted := slices.Sorted(slices.Values(samples))
+	half := len(sorted) / 2
+	floor := 1e-3 * (sorted[len(sorted)-1] - sorted[0])
+	if floor == 0 {
+		return config.DistributionSpec{}, 0, false
+	}
+
+	weights := []float64{0.5, 0.5}
+	comps := []normal{
+		{mu: stats.Mean(sorted[:half]), sigma: math.Max(floor, stats.StdDev(sorted[:half]))},
+		{mu: stats.Mean(sorted[half:]), sigma: math.Max(floor, stats.StdDev(sorted[half:]))},
+	}
+	resp := make([]float64, len(samples)) // responsibility of the first component
+	prev := math.Inf(-1)
+	for iter := 0; iter < mixtureIterations; iter++ {
+		ll := 0.0
+		for i, x := range samples {
+			a := weights[0] * math.Exp(comps[0].logPDF(x))
+			b := weights[1] * math.Exp(comps[1].logPDF(x))
+			if a+b == 0 {
+				resp[i] = 0.5
+				continue
+			}
+			resp[i] = a / (a + b)
+			ll += math.Log(a + b)
+		}
+
+		for k := range comps {
+			total, sum := 0.0, 0.0
+			for i, x := range samples {
+				r := resp[i]
+				if k == 1 {
+					r = 1 - r
+				}
+				total += r
+				sum += r * x
+			}
+			if total == 0 {
+				return config.DistributionSpec{}, 0, false
+			}
+			mu := sum / total
+			variance := 0.0
+			for i, x := range samples {
+				r := resp[i]
+				if k == 1 {
+					r = 1 - r
+				}
+				variance += r * (x - mu) * (x - mu)
+			}
+			weights[k] = total / float64(len(samples))
+			comps[k] = normal{mu: mu, sigma: math.Max(floor, math.Sqrt(variance/total))}
+		}
+		if ll-prev < 1e-8 {
+			break
+		}
+		prev = ll
+	}
+
+	spec := config.DistributionSpec{Kind: config.DistMixture, Min: lo, Max: hi, Weights: weights}
+	for _, c := range comps {
+		spec.Components = append(spec.Components,
+			config.DistributionSpec{Kind: config.DistNormal, Min: lo, Max: hi, Mean: c.mu, StdDev: c.sigma})
+	}
+	return spec, 5, true
+}
+
+// correlations estimates the copula correlation of each pair of fitted
+// parameters: the correlation of the bidders' normal scores, the normal
+// quantiles of their ranks
+func correlations(profiles []Profile, params map[string]config.DistributionSpec) []config.Correlation {
+	var fitted []string
+	for _, param := range config.PopulationParams {
+		if _, ok := params[param]; ok {
+			fitted = append(fitted, param)
+		}
+	}
+
+	var result []config.Correlation
+	for i, a := range fitted {
+		for _, b := range fitted[i+1:] {
+			var xs, ys []float64
+			for _, profile := range profiles {
+				x, okX := profile.Params[a]
+				y, okY := profile.Params[b]
+				if okX && okY {
+					xs, ys = append(xs, x), append(ys, y)
+				}
+			}
+			if len(xs) < minSamples {
+				continue
+			}
+			rho := pearson(normalScores(xs), normalScores(ys))
+			if math.IsNaN(rho) {
+				continue
+			}
+			rho = math.Max(-0.99, math.Min(0.99, math.Round(rho*1000)/1000))
+			result = append(result, config.Correlation{A: a, B: b, Rho: rho})
+		}
+	}
+	return result
+}
+
+// consistent shrinks estimated correlations towards zero until they can
+// hold together, which pairs estimated from different bidders may not
+func consistent(population *config.PopulationConfig) error {
+	for range 50 {
+		if _, err := NewSampler(population); err == nil {
+			return nil
+		}
+		for i := range population.Correlations {
+			population.Correlations[i].Rho = math.Round(population.Correlations[i].Rho*900) / 1000
+		}
+	}
+	_, err := NewSampler(population)
+	return err
+}
+
+// normalScores replaces values by the standard normal quantiles of their
+// ranks, ties sharing their mean rank
+func normalScores(values []float64) []float64 {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return values[order[a]] < values[order[b]] })
+
+	scores := make([]float64, len(values))
+	n := float64(len(values))
+	for start := 0; start < len(order); {
+		end := start
+		for end+1 < len(order) && values[order[end+1]] == values[order[start]] {
+			end++
+		}
+		rank := float64(start+end)/2 + 1
+		for _, idx := range order[start : end+1] {
+			scores[idx] = normalQuantile(rank / (n + 1))
+		}
+		start = end + 1
+	}
+	return scores
+}
+
+// pearson returns the correlation of two samples
+func pearson(xs, ys []float64) float64 {
+	mx, my := stats.Mean(xs), stats.Mean(ys)
+	var sxy, sxx, syy float64
+	for i := range xs {
+		dx, dy := xs[i]-mx, ys[i]-my
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	return sxy / math.Sqrt(sxx*syy)
+}
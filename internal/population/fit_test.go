@@ -0,0 +1,149 @@
+package population
+
+import (
+	"math"
+	"testing"
+
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/types"
+	"auction-simulator/pkg/utils"
+)
+
+// draw samples n bidders of a population as profiles, as if their
+// parameters had been estimated exactly
+func draw(t *testing.T, cfg *config.PopulationConfig, n int, seed int64) []Profile {
+	t.Helper()
+	sampler, err := NewSampler(cfg)
+	if err != nil {
+		t.Fatalf("sampler: %v", err)
+	}
+	rng := utils.NewRNG(seed)
+	profiles := make([]Profile, n)
+	for i := range profiles {
+		profiles[i] = Profile{Bids: 1, Params: sampler.Draw(rng)}
+	}
+	return profiles
+}
+
+func TestFitRecoversPopulation(t *testing.T) {
+	truth := &config.PopulationConfig{
+		Params: map[string]config.DistributionSpec{
+			config.ParamBidChance: {Kind: config.DistBeta, Min: 0, Max: 1, Alpha: 4, Beta: 2},
+			config.ParamBaseBid:   {Kind: config.DistNormal, Min: 0, Max: 1000, Mean: 120, StdDev: 15},
+			config.ParamSpeed:     {Kind: config.DistLogNormal, Min: 0, Max: 10000, Mean: math.Log(40), StdDev: 0.5},
+		},
+		Correlations: []config.Correlation{
+			{A: config.ParamBaseBid, B: config.ParamSpeed, Rho: -0.6},
+			{A: config.ParamBidChance, B: config.ParamBaseBid, Rho: 0.3},
+		},
+	}
+	profiles := draw(t, truth, 3000, 1)
+
+	result, err := Fit(profiles, FitAuto)
+	if err != nil {
+		t.Fatalf("fit: %v", err)
+	}
+
+	kinds := make(map[string]string)
+	for _, fit := range result.Fits {
+		kinds[fit.Param] = fit.Kind
+	}
+	for param, want := range truth.Params {
+		if kinds[param] != want.Kind {
+			t.Errorf("%s fitted as %s, want %s", param, kinds[param], want.Kind)
+		}
+	}
+
+	params := result.Population.Params
+	if got := params[config.ParamBaseBid]; kinds[config.ParamBaseBid] == config.DistNormal &&
+		(math.Abs(got.Mean-120) > 1.5 || math.Abs(got.StdDev-15) > 1) {
+		t.Errorf("base bid fitted as N(%.2f, %.2f), want N(120, 15)", got.Mean, got.StdDev)
+	}
+	if got := params[config.ParamSpeed]; kinds[config.ParamSpeed] == config.DistLogNormal &&
+		(math.Abs(got.Mean-math.Log(40)) > 0.05 || math.Abs(got.StdDev-0.5) > 0.05) {
+		t.Errorf("speed fitted as logN(%.3f, %.3f), want logN(%.3f, 0.5)", got.Mean, got.StdDev, math.Log(40))
+	}
+	if got := params[config.ParamBidChance]; kinds[config.ParamBidChance] == config.DistBeta {
+		d, want := newDistribution(&got), newDistribution(&config.DistributionSpec{Kind: config.DistBeta, Min: 0, Max: 1, Alpha: 4, Beta: 2})
+		for _, p := range []float64{0.1, 0.5, 0.9} {
+			if math.Abs(d.quantile(p)-want.quantile(p)) > 0.02 {
+				t.Errorf("bid chance quantile %g is %.3f, want %.3f", p, d.quantile(p), want.quantile(p))
+			}
+		}
+	}
+
+	rhos := make(map[[2]string]float64)
+	for _, c := range result.Population.Correlations {
+		rhos[[2]string{c.A, c.B}] = c.Rho
+	}
+	for _, want := range truth.Correlations {
+		if got := rhos[[2]string{want.A, want.B}]; math.Abs(got-want.Rho) > 0.05 {
+			t.Errorf("rho of %s and %s fitted as %.3f, want %.2f", want.A, want.B, got, want.Rho)
+		}
+	}
+	if got := rhos[[2]string{config.ParamBidChance, config.ParamSpeed}]; math.Abs(got) > 0.25 {
+		// Implied by the other two: 0.3 × -0.6
+		t.Errorf("rho of bid chance and speed fitted as %.3f, want about -0.18", got)
+	}
+}
+
+func TestFitMixtureRecoversComponents(t *testing.T) {
+	truth := &config.PopulationConfig{Params: map[string]config.DistributionSpec{
+		config.ParamBaseBid: {Kind: config.DistMixture, Min: 0, Max: 500, Weights: []float64{0.35, 0.65},
+			Components: []config.DistributionSpec{
+				{Kind: config.DistNormal, Min: 0, Max: 500, Mean: 60, StdDev: 8},
+				{Kind: config.DistNormal, Min: 0, Max: 500, Mean: 150, StdDev: 12},
+			}},
+	}}
+	profiles := draw(t, truth, 2000, 2)
+
+	result, err := Fit(profiles, FitAuto)
+	if err != nil {
+		t.Fatalf("fit: %v", err)
+	}
+	if len(result.Fits) != 1 || result.Fits[0].Kind != config.DistMixture {
+		t.Fatalf("fitted %+v, want a mixture chosen by BIC", result.Fits)
+	}
+
+	got := result.Population.Params[config.ParamBaseBid]
+	low, high := 0, 1
+	if got.Components[0].Mean > got.Components[1].Mean {
+		low, high = 1, 0
+	}
+	weights := normalized(got.Weights)
+	for _, c := range []struct {
+		i            int
+		weight, mean float64
+		sd           float64
+	}{{low, 0.35, 60, 8}, {high, 0.65, 150, 12}} {
+		comp := got.Components[c.i]
+		if math.Abs(weights[c.i]-c.weight) > 0.03 || math.Abs(comp.Mean-c.mean) > 1.5 || math.Abs(comp.StdDev-c.sd) > 1.5 {
+			t.Errorf("component %.2f·N(%.1f, %.1f), want %.2f·N(%g, %g)",
+				weights[c.i], comp.Mean, comp.StdDev, c.weight, c.mean, c.sd)
+		}
+	}
+}
+
+func TestProfilesCountHighestBidPerAuction(t *testing.T) {
+	results := []*types.AuctionResult{
+		{AuctionID: "auction-1", Bids: []types.Bid{
+			{BidderID: "bidder-1", Amount: 10}, {BidderID: "bidder-2", Amount: 11},
+			{BidderID: "bidder-1", Amount: 12}, {BidderID: "bidder-2", Amount: 13},
+			{BidderID: "bidder-1", Amount: 100},
+		}},
+		{AuctionID: "auction-2", Bids: []types.Bid{{BidderID: "bidder-1", Amount: 80}}},
+	}
+
+	profiles := Profiles(Bids(results))
+	if len(profiles) != 2 {
+		t.Fatalf("%d profiles, want 2", len(profiles))
+	}
+	first := profiles[0]
+	if first.Bids != 2 || first.Params[config.ParamBaseBid] != 90 || first.Params[config.ParamBidChance] != 1 {
+		t.Errorf("bidder-1 profiled as %d bids %v, want 2 bids averaging 90 in every auction", first.Bids, first.Params)
+	}
+	second := profiles[1]
+	if second.Bids != 1 || second.Params[config.ParamBaseBid] != 13 || second.Params[config.ParamBidChance] != 0.5 {
+		t.Errorf("bidder-2 profiled as %d bids %v, want 1 bid of 13 in half the auctions", second.Bids, second.Params)
+	}
+}
@@ -0,0 +1,99 @@
+// Package population draws synthetic bidder parameters from distributions
+// and fits those distributions to recorded bids
+package population
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"auction-simulator/internal/config"
+	"auction-simulator/pkg/utils"
+)
+
+// Sampler draws the parameters of a population's bidders. Parameters are
+// tied together by a Gaussian copula: correlated standard normal scores
+// are drawn and each is mapped through its parameter's quantile function,
+// so every parameter keeps its own distribution.
+type Sampler struct {
+	params        []string // parameters with a distribution, in config.PopulationParams order
+	distributions []distribution
+	factor        [][]float64 // lower Cholesky factor of the score correlations
+}
+
+// NewSampler creates a sampler for a population
+func NewSampler(cfg *config.PopulationConfig) (*Sampler, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	s := &Sampler{}
+	for _, param := range config.PopulationParams {
+		if spec, ok := cfg.Params[param]; ok {
+			s.params = append(s.params, param)
+			s.distributions = append(s.distributions, bounded(&spec))
+		}
+	}
+
+	n := len(s.params)
+	corr := make([][]float64, n)
+	for i := range corr {
+		corr[i] = make([]float64, n)
+		corr[i][i] = 1
+	}
+	for _, c := range cfg.Correlations {
+		a, b := slices.Index(s.params, c.A), slices.Index(s.params, c.B)
+		corr[a][b], corr[b][a] = c.Rho, c.Rho
+	}
+	factor, ok := cholesky(corr)
+	if !ok {
+		return nil, fmt.Errorf("population correlations are inconsistent: no population has all of them at once")
+	}
+	s.factor = factor
+	return s, nil
+}
+
+// Draw returns one bidder's parameters by name
+func (s *Sampler) Draw(rng *utils.RNG) map[string]float64 {
+	n := len(s.params)
+	z := make([]float64, n)
+	for i := range z {
+		z[i] = rng.NormFloat64()
+	}
+
+	values := make(map[string]float64, n)
+	for i, param := range s.params {
+		score := 0.0
+		for j := 0; j <= i; j++ {
+			score += s.factor[i][j] * z[j]
+		}
+		p := math.Max(1e-12, math.Min(1-1e-12, normalCDF(score)))
+		values[param] = s.distributions[i].quantile(p)
+	}
+	return values
+}
+
+// cholesky returns the lower triangular L with L·Lᵀ = m, or false if m is
+// not positive definite
+func cholesky(m [][]float64) ([][]float64, bool) {
+	n := len(m)
+	l := make([][]float64, n)
+	for i := range l {
+		l[i] = make([]float64, n)
+		for j := 0; j <= i; j++ {
+			sum := m[i][j]
+			for k := 0; k < j; k++ {
+				sum -= l[i][k] * l[j][k]
+			}
+			if i == j {
+				if sum <= 0 {
+					return nil, false
+				}
+				l[i][i] = math.Sqrt(sum)
+			} else {
+				l[i][j] = sum / l[j][j]
+			}
+		}
+	}
+	return l, true
+}
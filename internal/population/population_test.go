@@ -0,0 +1,72 @@
+package population
+
+import (
+	"math"
+	"testing"
+
+	"auction-simulator/internal/config"
+)
+
+func TestCholesky(t *testing.T) {
+	m := [][]float64{
+		{1, 0.6, -0.3},
+		{0.6, 1, 0.2},
+		{-0.3, 0.2, 1},
+	}
+	l, ok := cholesky(m)
+	if !ok {
+		t.Fatal("positive definite matrix rejected")
+	}
+	for i := range m {
+		for j := range m {
+			if j > i && l[i][j] != 0 {
+				t.Errorf("factor has %g above the diagonal at (%d, %d)", l[i][j], i, j)
+			}
+			product := 0.0
+			for k := range m {
+				product += l[i][k] * l[j][k]
+			}
+			if math.Abs(product-m[i][j]) > 1e-12 {
+				t.Errorf("(L·Lᵀ)[%d][%d] = %g, want %g", i, j, product, m[i][j])
+			}
+		}
+	}
+
+	// Correlations no population can have at once
+	inconsistent := [][]float64{
+		{1, 0.9, 0.9},
+		{0.9, 1, -0.9},
+		{0.9, -0.9, 1},
+	}
+	if _, ok := cholesky(inconsistent); ok {
+		t.Error("matrix that is not positive definite accepted")
+	}
+}
+
+func TestBisectedQuantilesInvertCDF(t *testing.T) {
+	tests := []struct {
+		name string
+		spec config.DistributionSpec
+	}{
+		{"beta", config.DistributionSpec{Kind: config.DistBeta, Min: 10, Max: 50, Alpha: 2, Beta: 5}},
+		{"mixture", config.DistributionSpec{Kind: config.DistMixture, Min: 0, Max: 300, Weights: []float64{0.3, 0.7},
+			Components: []config.DistributionSpec{
+				{Kind: config.DistNormal, Min: 0, Max: 300, Mean: 60, StdDev: 10},
+				{Kind: config.DistNormal, Min: 0, Max: 300, Mean: 150, StdDev: 20},
+			}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDistribution(&tt.spec)
+			for _, p := range []float64{0.001, 0.05, 0.25, 0.3, 0.5, 0.75, 0.95, 0.999} {
+				x := d.quantile(p)
+				if x < tt.spec.Min || x > tt.spec.Max {
+					t.Errorf("quantile(%g) = %g outside [%g, %g]", p, x, tt.spec.Min, tt.spec.Max)
+				}
+				if got := d.cdf(x); math.Abs(got-p) > 1e-6 {
+					t.Errorf("cdf(quantile(%g)) = %g", p, got)
+				}
+			}
+		})
+	}
+}
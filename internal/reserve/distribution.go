@@ -0,0 +1,151 @@
+package reserve
+
+import (
+	"fmt"
+	"math"
+
+	"auction-simulator/internal/stats"
+)
+
+// Distribution is a fitted model of bidder values
+type Distribution interface {
+	CDF(x float64) float64
+	PDF(x float64) float64
+	LogLikelihood(samples []float64) float64
+	Upper() float64 // a value well into the upper tail
+	String() string
+}
+
+// Normal is a normal distribution
+type Normal struct {
+	Mu, Sigma float64
+}
+
+// CDF implements Distribution
+func (n Normal) CDF(x float64) float64 {
+	return 0.5 * math.Erfc(-(x-n.Mu)/(n.Sigma*math.Sqrt2))
+}
+
+// PDF implements Distribution
+func (n Normal) PDF(x float64) float64 {
+	z := (x - n.Mu) / n.Sigma
+	return math.Exp(-z*z/2) / (n.Sigma * math.Sqrt(2*math.Pi))
+}
+
+// LogLikelihood implements Distribution
+func (n Normal) LogLikelihood(samples []float64) float64 {
+	ll := 0.0
+	for _, x := range samples {
+		z := (x - n.Mu) / n.Sigma
+		ll += -z*z/2 - math.Log(n.Sigma*math.Sqrt(2*math.Pi))
+	}
+	return ll
+}
+
+// Upper implements Distribution
+func (n Normal) Upper() float64 {
+	return n.Mu + 8*n.Sigma
+}
+
+func (n Normal) String() string {
+	return fmt.Sprintf("normal(mu=%.2f, sigma=%.2f)", n.Mu, n.Sigma)
+}
+
+// LogNormal is a distribution whose logarithm is normal
+type LogNormal struct {
+	Mu, Sigma float64 // parameters of the underlying normal
+}
+
+// CDF implements Distribution
+func (l LogNormal) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return Normal(l).CDF(math.Log(x))
+}
+
+// PDF implements Distribution
+func (l LogNormal) PDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return Normal(l).PDF(math.Log(x)) / x
+}
+
+// LogLikelihood implements Distribution
+func (l LogNormal) LogLikelihood(samples []float64) float64 {
+	ll := 0.0
+	for _, x := range samples {
+		if x <= 0 {
+			return math.Inf(-1)
+		}
+		ll += math.Log(l.PDF(x))
+	}
+	return ll
+}
+
+// Upper implements Distribution
+func (l LogNormal) Upper() float64 {
+	return math.Exp(l.Mu + 8*l.Sigma)
+}
+
+func (l LogNormal) String() string {
+	return fmt.Sprintf("lognormal(mu=%.3f, sigma=%.3f)", l.Mu, l.Sigma)
+}
+
+// Fit estimates normal and log-normal models by maximum likelihood and
+// returns the one that explains the samples better
+func Fit(samples []float64) Distribution {
+	normal := Normal{Mu: stats.Mean(samples), Sigma: stats.StdDev(samples)}
+	if normal.Sigma == 0 {
+		normal.Sigma = math.Max(1e-6, math.Abs(normal.Mu)*1e-6)
+	}
+
+	logs := make([]float64, 0, len(samples))
+	for _, x := range samples {
+		if x <= 0 {
+			return normal
+		}
+		logs = append(logs, math.Log(x))
+	}
+	lognormal := LogNormal{Mu: stats.Mean(logs), Sigma: stats.StdDev(logs)}
+	if lognormal.Sigma == 0 {
+		return normal
+	}
+
+	if lognormal.LogLikelihood(samples) > normal.LogLikelihood(samples) {
+		return lognormal
+	}
+	return normal
+}
+
+// MyersonReserve returns the optimal reserve for independent bidders with
+// values drawn from d: the root of the virtual value r - (1-F(r))/f(r)
+func MyersonReserve(d Distribution) float64 {
+	virtual := func(r float64) float64 {
+		f := d.PDF(r)
+		if f <= 0 {
+			// Far tails: below the support the virtual value is very negative,
+			// above it very positive
+			if d.CDF(r) < 0.5 {
+				return math.Inf(-1)
+			}
+			return math.Inf(1)
+		}
+		return r - (1-d.CDF(r))/f
+	}
+
+	lo, hi := 0.0, d.Upper()
+	if virtual(hi) <= 0 {
+		return hi
+	}
+	for i := 0; i < 200 && hi-lo > 1e-6; i++ {
+		mid := (lo + hi) / 2
+		if virtual(mid) < 0 {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
@@ -0,0 +1,348 @@
+package reserve
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/types"
+)
+
+// Search methods for the revenue-maximizing reserve
+const (
+	MethodGrid    = "grid"    // evenly spaced candidates over the observed bid range
+	MethodGolden  = "golden"  // golden-section search over the observed bid range
+	MethodMyerson = "myerson" // root of the virtual value of a fitted bid distribution
+)
+
+// Scopes at which reserves are set
+const (
+	ScopeGlobal  = "global"  // one reserve for every auction
+	ScopeSegment = "segment" // one reserve per quantile bucket of an attribute
+	ScopeAuction = "auction" // one reserve per auction ID
+)
+
+// Options controls how reserves are optimized
+type Options struct {
+	Method           string
+	Scope            string
+	Segments         int    // buckets in segment scope
+	SegmentAttribute int    // attribute ID that defines the segments
+	GridSteps        int    // candidates in grid search
+	Mechanism        string // pricing of the recorded auctions, a config mechanism; empty is second-price
+}
+
+// DefaultOptions returns a global grid search
+func DefaultOptions() Options {
+	return Options{
+		Method:    MethodGrid,
+		Scope:     ScopeGlobal,
+		Segments:  4,
+		GridSteps: 200,
+		Mechanism: config.SecondPrice,
+	}
+}
+
+// Book is the bid book of one recorded auction
+type Book struct {
+	AuctionID  string
+	Attributes []float64
+	Bids       []float64 // sorted from highest to lowest
+}
+
+// Recommendation is the optimized reserve of one group of auctions
+type Recommendation struct {
+	Group        string  `json:"group"`
+	Auctions     int     `json:"auctions"`
+	Bids         int     `json:"bids"`
+	Reserve      float64 `json:"reserve"`
+	Baseline     float64 `json:"baseline_revenue"` // revenue without a reserve
+	Expected     float64 `json:"expected_revenue"` // revenue at Reserve
+	Distribution string  `json:"distribution,omitempty"`
+}
+
+// Result is the outcome of an optimization
+type Result struct {
+	Method    string               `json:"method"`
+	Scope     string               `json:"scope"`
+	Mechanism string               `json:"mechanism"`
+	Groups    []Recommendation     `json:"groups"`
+	Policy    config.ReservePolicy `json:"policy"`
+	Baseline  float64              `json:"baseline_revenue"`
+	Expected  float64              `json:"expected_revenue"`
+}
+
+// Books extracts the bid books of completed auctions
+func Books(results []*types.AuctionResult) []Book {
+	books := make([]Book, 0, len(results))
+	for _, result := range results {
+		if result == nil || result.Error != nil {
+			continue
+		}
+		book := Book{AuctionID: result.AuctionID}
+		for _, attr := range result.Attributes {
+			book.Attributes = append(book.Attributes, attr.Value)
+		}
+		for _, bid := range result.Bids {
+			book.Bids = append(book.Bids, bid.Amount)
+		}
+		sort.Sort(sort.Reverse(sort.Float64Slice(book.Bids)))
+		books = append(books, book)
+	}
+	return books
+}
+
+// Revenue returns the revenue of the books with reserve r under mechanism.
+// Auctions whose highest bid is below r go unsold. Under first-price the
+// highest bidder pays their bid; under second-price, the larger of the
+// runner-up bid and r.
+func Revenue(books []Book, r float64, mechanism string) float64 {
+	total := 0.0
+	for _, book := range books {
+		if len(book.Bids) == 0 || book.Bids[0] < r {
+			continue
+		}
+		if mechanism == config.FirstPrice {
+			total += book.Bids[0]
+			continue
+		}
+		price := r
+		if len(book.Bids) > 1 && book.Bids[1] > price {
+			price = book.Bids[1]
+		}
+		total += price
+	}
+	return total
+}
+
+// Optimize finds revenue-maximizing reserves for the recorded auctions
+func Optimize(results []*types.AuctionResult, opts Options) (*Result, error) {
+	books := Books(results)
+	if len(books) == 0 {
+		return nil, fmt.Errorf("no completed auctions to optimize over")
+	}
+
+	groups, err := group(books, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Mechanism == "" {
+		opts.Mechanism = config.SecondPrice
+	}
+	result := &Result{Method: opts.Method, Scope: opts.Scope, Mechanism: opts.Mechanism}
+	for _, g := range groups {
+		rec, err := optimizeGroup(g.books, opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", g.name, err)
+		}
+		rec.Group = g.name
+		result.Groups = append(result.Groups, rec)
+		result.Baseline += rec.Baseline
+		result.Expected += rec.Expected
+	}
+
+	result.Policy = policy(result.Groups, groups, opts)
+	return result, nil
+}
+
+// bookGroup is a set of auctions sharing one reserve
+type bookGroup struct {
+	name  string
+	books []Book
+	upper float64 // exclusive upper attribute bound in segment scope
+}
+
+// group splits the books according to the optimization scope
+func group(books []Book, opts Options) ([]bookGroup, error) {
+	switch opts.Scope {
+	case ScopeGlobal:
+		return []bookGroup{{name: "all", books: books}}, nil
+
+	case ScopeAuction:
+		byID := make(map[string][]Book)
+		var ids []string
+		for _, book := range books {
+			if _, ok := byID[book.AuctionID]; !ok {
+				ids = append(ids, book.AuctionID)
+			}
+			byID[book.AuctionID] = append(byID[book.AuctionID], book)
+		}
+		groups := make([]bookGroup, len(ids))
+		for i, id := range ids {
+			groups[i] = bookGroup{name: id, books: byID[id]}
+		}
+		return groups, nil
+
+	case ScopeSegment:
+		if opts.Segments < 1 {
+			return nil, fmt.Errorf("segment scope needs at least one segment")
+		}
+		values := make([]float64, 0, len(books))
+		for _, book := range books {
+			if opts.SegmentAttribute >= len(book.Attributes) {
+				return nil, fmt.Errorf("auction %s has no attribute %d", book.AuctionID, opts.SegmentAttribute)
+			}
+			values = append(values, book.Attributes[opts.SegmentAttribute])
+		}
+		sort.Float64s(values)
+
+		// Quantile bounds so each segment holds about the same number of auctions
+		var bounds []float64
+		for i := 1; i < opts.Segments; i++ {
+			bound := values[i*len(values)/opts.Segments]
+			if len(bounds) == 0 || bound > bounds[len(bounds)-1] {
+				bounds = append(bounds, bound)
+			}
+		}
+
+		segmenter := config.ReservePolicy{SegmentBounds: bounds}
+		groups := make([]bookGroup, len(bounds)+1)
+		for i := range groups {
+			groups[i].upper = math.Inf(1)
+			if i < len(bounds) {
+				groups[i].upper = bounds[i]
+			}
+			lower := math.Inf(-1)
+			if i > 0 {
+				lower = bounds[i-1]
+			}
+			groups[i].name = segmentName(opts.SegmentAttribute, lower, groups[i].upper)
+		}
+		for _, book := range books {
+			idx := segmenter.Segment(book.Attributes[opts.SegmentAttribute])
+			groups[idx].books = append(groups[idx].books, book)
+		}
+		return groups, nil
+
+	default:
+		return nil, fmt.Errorf("unknown reserve scope: %s", opts.Scope)
+	}
+}
+
+// segmentName describes an attribute bucket, e.g. "attr3 in [25.00, 50.00)"
+func segmentName(attribute int, lower, upper float64) string {
+	switch {
+	case math.IsInf(lower, -1) && math.IsInf(upper, 1):
+		return fmt.Sprintf("attr%d any", attribute)
+	case math.IsInf(lower, -1):
+		return fmt.Sprintf("attr%d < %.2f", attribute, upper)
+	case math.IsInf(upper, 1):
+		return fmt.Sprintf("attr%d >= %.2f", attribute, lower)
+	}
+	return fmt.Sprintf("attr%d in [%.2f, %.2f)", attribute, lower, upper)
+}
+
+// policy turns per-group reserves into a config policy
+func policy(recs []Recommendation, groups []bookGroup, opts Options) config.ReservePolicy {
+	var p config.ReservePolicy
+	switch opts.Scope {
+	case ScopeGlobal:
+		p.Price = recs[0].Reserve
+	case ScopeAuction:
+		p.ByAuction = make(map[string]float64, len(recs))
+		for _, rec := range recs {
+			p.ByAuction[rec.Group] = rec.Reserve
+		}
+	case ScopeSegment:
+		p.SegmentAttribute = opts.SegmentAttribute
+		for i, g := range groups {
+			if i < len(groups)-1 {
+				p.SegmentBounds = append(p.SegmentBounds, g.upper)
+			}
+			p.SegmentPrices = append(p.SegmentPrices, recs[i].Reserve)
+		}
+	}
+	return p
+}
+
+// optimizeGroup searches for the best reserve of one group
+func optimizeGroup(books []Book, opts Options) (Recommendation, error) {
+	revenue := func(r float64) float64 { return Revenue(books, r, opts.Mechanism) }
+	rec := Recommendation{Auctions: len(books), Baseline: revenue(0)}
+
+	var bids []float64
+	for _, book := range books {
+		bids = append(bids, book.Bids...)
+	}
+	rec.Bids = len(bids)
+	if len(bids) == 0 {
+		return rec, nil
+	}
+	hi := 0.0
+	for _, b := range bids {
+		hi = math.Max(hi, b)
+	}
+
+	switch opts.Method {
+	case MethodGrid:
+		rec.Reserve = gridSearch(revenue, hi, opts.GridSteps)
+	case MethodGolden:
+		rec.Reserve = goldenSection(revenue, 0, hi)
+	case MethodMyerson:
+		dist := Fit(bids)
+		rec.Reserve = MyersonReserve(dist)
+		rec.Distribution = dist.String()
+	default:
+		return rec, fmt.Errorf("unknown reserve method: %s", opts.Method)
+	}
+
+	rec.Reserve = math.Round(rec.Reserve*100) / 100
+	rec.Expected = revenue(rec.Reserve)
+	return rec, nil
+}
+
+// gridSearch evaluates evenly spaced reserves in [0, hi] and keeps the best
+func gridSearch(revenue func(r float64) float64, hi float64, steps int) float64 {
+	if steps < 2 {
+		steps = 2
+	}
+	best, bestRevenue := 0.0, revenue(0)
+	for i := 1; i < steps; i++ {
+		r := hi * float64(i) / float64(steps-1)
+		if v := revenue(r); v > bestRevenue {
+			best, bestRevenue = r, v
+		}
+	}
+	return best
+}
+
+// goldenSection maximizes revenue over [lo, hi]. Empirical revenue is
+// piecewise linear with flat stretches, so a coarse scan first brackets the
+// best region and golden-section search then refines within it.
+func goldenSection(revenue func(r float64) float64, lo, hi float64) float64 {
+	const invPhi = 0.6180339887498949
+	const tolerance = 0.01
+	const coarseSteps = 20
+
+	step := (hi - lo) / coarseSteps
+	best, bestRevenue := lo, revenue(lo)
+	for i := 1; i <= coarseSteps; i++ {
+		r := lo + float64(i)*step
+		if v := revenue(r); v > bestRevenue {
+			best, bestRevenue = r, v
+		}
+	}
+
+	a, b := math.Max(lo, best-step), math.Min(hi, best+step)
+	c := b - invPhi*(b-a)
+	d := a + invPhi*(b-a)
+	fc, fd := revenue(c), revenue(d)
+	for b-a > tolerance {
+		if fc >= fd {
+			b, d, fd = d, c, fc
+			c = b - invPhi*(b-a)
+			fc = revenue(c)
+		} else {
+			a, c, fc = c, d, fd
+			d = a + invPhi*(b-a)
+			fd = revenue(d)
+		}
+	}
+
+	if refined := (a + b) / 2; revenue(refined) > bestRevenue {
+		return refined
+	}
+	return best
+}
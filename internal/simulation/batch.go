@@ -0,0 +1,157 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"strconv"
+	"sync"
+
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/metrics"
+	"auction-simulator/pkg/utils"
+)
+
+// BatchOptions controls a Monte Carlo batch of repeated runs
+type BatchOptions struct {
+	Runs       int     // number of repetitions
+	Parallel   int     // runs executing at once; <= 0 uses one per vCPU
+	Confidence float64 // confidence level of the aggregated intervals
+
+	// Shared is set when other work runs in this process at the same time
+	// as the batch, which leaves its process-wide metrics out like
+	// parallel runs do
+	Shared bool
+	// Log receives the runs' progress messages; nil uses the standard
+	// logger. It is not sent to cluster workers, which log to their own
+	// output.
+	Log io.Writer
+}
+
+// Batch is the outcome of a Monte Carlo batch
+type Batch struct {
+	Summary *metrics.BatchSummary
+	Runs    []*metrics.RunReport
+}
+
+// RunBatch repeats the simulation for base with a distinct seed per run,
+// derived from base.Seed, and aggregates the results. The concurrent bidder
+// budget is split between runs that execute in parallel.
+//
+// If ctx is cancelled, RunBatch returns the runs that finished or were cut
+// short so far, summarized and marked incomplete, together with ctx.Err().
+func RunBatch(ctx context.Context, base *config.Config, opts BatchOptions) (*Batch, error) {
+	if opts.Runs < 1 {
+		return nil, fmt.Errorf("batch needs at least one run, got %d", opts.Runs)
+	}
+
+	parallel := opts.Parallel
+	if parallel <= 0 {
+		parallel = base.ResourceLimits.MaxVCPUs
+	}
+	if parallel > opts.Runs {
+		parallel = opts.Runs
+	}
+
+	logger := log.Default()
+	if opts.Log != nil {
+		logger = log.New(opts.Log, "", log.LstdFlags)
+	}
+
+	batchID := metrics.NewBatchID()
+	runs := make([]*metrics.RunReport, opts.Runs)
+	errs := make([]error, opts.Runs)
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, parallel)
+
+	logger.Printf("Starting batch %s: %d runs, %d in parallel", batchID, opts.Runs, parallel)
+
+	for i := 0; i < opts.Runs; i++ {
+		wg.Add(1)
+
+		go func(idx int) {
+			defer wg.Done()
+
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				errs[idx] = ctx.Err()
+				return
+			}
+
+			cfg := RunConfig(base, idx, parallel)
+			report, err := run(ctx, cfg, runOptions{logger: logger})
+			if report != nil {
+				report.Metrics.BatchID = batchID
+				runs[idx] = report
+			}
+			if err != nil {
+				errs[idx] = fmt.Errorf("run %d (seed %d): %w", idx, cfg.Seed, err)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	interrupted := ctx.Err()
+	if interrupted == nil {
+		for _, err := range errs {
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var finished []*metrics.RunReport
+	for _, run := range runs {
+		if run != nil {
+			finished = append(finished, run)
+		}
+	}
+	if len(finished) == 0 {
+		return nil, interrupted
+	}
+
+	runMetrics := make([]*metrics.SimulationMetrics, len(finished))
+	for i, run := range finished {
+		runMetrics[i] = run.Metrics
+	}
+
+	// Memory and goroutines are sampled from the whole process, so runs
+	// that share it cannot be told apart
+	summaries := metrics.Summarize(runMetrics, opts.Confidence)
+	if parallel > 1 || opts.Shared {
+		summaries = slices.DeleteFunc(summaries, func(m metrics.MetricSummary) bool {
+			return slices.Contains(metrics.ProcessMetrics, m.Name)
+		})
+	}
+
+	return &Batch{
+		Summary: &metrics.BatchSummary{
+			BatchID:    batchID,
+			BaseSeed:   base.Seed,
+			Runs:       len(finished),
+			Confidence: opts.Confidence,
+			Metrics:    summaries,
+			RunMetrics: runMetrics,
+			Incomplete: interrupted != nil,
+		},
+		Runs: finished,
+	}, interrupted
+}
+
+// RunConfig returns the configuration of the idx-th run of a batch: a copy
+// of base with its own seed and a share of the concurrent bidder budget
+func RunConfig(base *config.Config, idx, parallel int) *config.Config {
+	cfg := *base
+	cfg.Seed = utils.DeriveSeed(base.Seed, "run", strconv.Itoa(idx))
+
+	if parallel > 1 {
+		cfg.ResourceLimits.MaxConcurrentBidders = max(1, base.ResourceLimits.MaxConcurrentBidders/parallel)
+	}
+	return &cfg
+}
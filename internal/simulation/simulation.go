@@ -0,0 +1,349 @@
+package simulation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"auction-simulator/internal/arrival"
+	"auction-simulator/internal/auction"
+	"auction-simulator/internal/bidder"
+	"auction-simulator/internal/catalog"
+	"auction-simulator/internal/checkpoint"
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/metrics"
+	"auction-simulator/internal/types"
+)
+
+// Run executes one complete simulation for cfg and returns everything needed
+// to report on it. The run is reproducible for a given cfg.Seed.
+//
+// If ctx is cancelled or the memory limit is breached, running auctions close
+// with the bids received so far and Run returns the partial report, marked
+// incomplete, together with the reason.
+func Run(ctx context.Context, cfg *config.Config) (*metrics.RunReport, error) {
+	return run(ctx, cfg, runOptions{})
+}
+
+// CheckpointOptions controls periodic checkpoints of a run
+type CheckpointOptions struct {
+	Path     string        // checkpoint file, replaced on every save
+	Interval time.Duration // time between saves
+}
+
+// RunWithCheckpoints is Run, saving the run's progress to a checkpoint so
+// that Resume can finish it if the process is stopped or killed
+func RunWithCheckpoints(ctx context.Context, cfg *config.Config, opts CheckpointOptions) (*metrics.RunReport, error) {
+	if cfg.Arrival.Streaming() {
+		return nil, fmt.Errorf("checkpoints are not supported for streaming runs")
+	}
+	return run(ctx, cfg, runOptions{checkpoint: &opts})
+}
+
+// Resume continues the run saved in cp, running only the auctions that had
+// not completed, and returns the report of the whole run. Progress keeps
+// being saved to opts.Path.
+//
+// Without learning bidders the report is the one an uninterrupted run
+// would have produced. Learners pick up from what they had learned at the
+// save, but they learn in the order auctions complete, so a resumed run
+// with learners can differ from one that was never stopped.
+func Resume(ctx context.Context, cp *checkpoint.Checkpoint, opts CheckpointOptions) (*metrics.RunReport, error) {
+	if cp.Config.Arrival.Streaming() {
+		return nil, fmt.Errorf("checkpoints are not supported for streaming runs")
+	}
+	return run(ctx, cp.Config, runOptions{checkpoint: &opts, resume: cp})
+}
+
+// RunPartition runs only the given auctions of the simulation for cfg, as a
+// worker of a distributed run does. Winners are charged to ledger, which is
+// shared with the other workers.
+func RunPartition(ctx context.Context, cfg *config.Config, auctionIDs []string, ledger bidder.Ledger) (*metrics.RunReport, error) {
+	if cfg.Arrival.Streaming() {
+		return nil, fmt.Errorf("streaming runs cannot be partitioned")
+	}
+
+	partition := make(map[string]bool, len(auctionIDs))
+	for _, id := range auctionIDs {
+		partition[id] = true
+	}
+	return run(ctx, cfg, runOptions{partition: partition, ledger: ledger})
+}
+
+// runOptions selects the optional parts of a run
+type runOptions struct {
+	checkpoint *CheckpointOptions     // save progress periodically
+	resume     *checkpoint.Checkpoint // continue from a checkpoint
+	partition  map[string]bool        // run only these auctions
+	ledger     bidder.Ledger          // budget ledger shared with other processes
+	logger     *log.Logger            // progress messages; nil uses the standard logger
+}
+
+// run executes a simulation with the optional parts selected in opts
+func run(ctx context.Context, cfg *config.Config, opts runOptions) (*metrics.RunReport, error) {
+	ckptOpts, resume := opts.checkpoint, opts.resume
+	logger := opts.logger
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	// Record overall simulation start time
+	simulationStart := time.Now()
+
+	// Initialize components
+	auctionManager := auction.NewManager(cfg)
+	bidderManager := bidder.NewManager(cfg)
+	auctionManager.SetLogger(logger)
+	bidderManager.SetLogger(logger)
+	var items *catalog.Catalog
+	if cfg.Catalog.Path != "" {
+		var err error
+		if items, err = catalog.Open(cfg); err != nil {
+			return nil, err
+		}
+		auctionManager.SetCatalog(items)
+	}
+
+	// Initialize auctions and bidders; streamed auctions are created as
+	// they arrive
+	var arrivals arrival.Process
+	if cfg.Arrival.Process == config.ArrivalReplay {
+		replay, _, err := arrival.LoadReplay(cfg.Arrival.TracePath, cfg.Attributes, cfg.Arrival.Speed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load bid requests: %w", err)
+		}
+		auctionManager.SetReplay(replay)
+		arrivals = replay
+	} else if cfg.Arrival.Streaming() {
+		process, err := arrival.FromConfig(cfg.Arrival, cfg.Seed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure arrivals: %w", err)
+		}
+		arrivals = process
+	} else if err := auctionManager.InitializeAuctions(); err != nil {
+		return nil, fmt.Errorf("failed to initialize auctions: %w", err)
+	}
+
+	// Auctions are regenerated from the seed; a generator that ends up
+	// elsewhere than it was saved means the config or the code has changed
+	if resume != nil {
+		if pos := auctionManager.RNGPosition(); pos != resume.RNG[checkpoint.StreamAuctions] {
+			return nil, fmt.Errorf("checkpoint does not match this build: auction generator at %d, saved at %d",
+				pos, resume.RNG[checkpoint.StreamAuctions])
+		}
+		bidderManager.Restore(resume.Bidders, resume.RNG[checkpoint.StreamBidders])
+		if budgets, ok := bidderManager.Ledger().(*bidder.BudgetLedger); ok {
+			budgets.Restore(resume.Spent)
+		}
+	} else if err := bidderManager.InitializeBidders(); err != nil {
+		return nil, fmt.Errorf("failed to initialize bidders: %w", err)
+	}
+	if opts.ledger != nil {
+		bidderManager.SetLedger(opts.ledger)
+	}
+
+	// Create orchestrator for concurrent auction execution
+	orchestratorCfg := cfg
+	if resume != nil {
+		orchestratorCfg = resumedConfig(cfg, len(resume.Results))
+	}
+	orchestrator, err := auction.NewOrchestrator(orchestratorCfg, auctionManager, bidderManager)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create orchestrator: %w", err)
+	}
+	orchestrator.SetLogger(logger)
+	if resume != nil {
+		if err := orchestrator.RestoreLearners(resume.Learners); err != nil {
+			return nil, fmt.Errorf("could not restore learning bidders: %w", err)
+		}
+	}
+
+	// A partition skips the auctions other workers run
+	if opts.partition != nil {
+		skip := make(map[string]bool)
+		for _, auct := range auctionManager.GetAuctions() {
+			if !opts.partition[auct.ID] {
+				skip[auct.ID] = true
+			}
+		}
+		orchestrator.Skip(skip)
+	}
+
+	// Checkpoint completed auctions as they finish, skipping those that
+	// completed before a resume
+	runID := metrics.NewRunID()
+	var writer *checkpoint.Writer
+	if ckptOpts != nil {
+		cp := &checkpoint.Checkpoint{RunID: runID, Config: cfg}
+		if resume != nil {
+			runID = resume.RunID
+			cp = resume
+			orchestrator.Skip(resume.Completed())
+			logger.Printf("Resuming run %s: %d of %d auctions already completed",
+				runID, len(resume.Results), cfg.TotalAuctions)
+		}
+		writer = checkpoint.NewWriter(ckptOpts.Path, ckptOpts.Interval, cp, func(cp *checkpoint.Checkpoint) {
+			cp.Bidders = bidderManager.GetBidders()
+			if _, ok := bidderManager.Ledger().(*bidder.BudgetLedger); ok {
+				cp.Spent = recordedSpend(cp.Results)
+			}
+			cp.Learners = orchestrator.Learners()
+			cp.RNG = map[string]uint64{
+				checkpoint.StreamAuctions: auctionManager.RNGPosition(),
+				checkpoint.StreamBidders:  bidderManager.RNGPosition(),
+			}
+		})
+		orchestrator.OnResult(writer.Record)
+		writer.Start()
+	}
+
+	// Start metrics collection; a memory limit breach cancels the run
+	metricsCollector := metrics.NewCollector()
+	resourceMonitor := metrics.NewResourceMonitor()
+	resourceMonitor.SetMemoryLimit(cfg.ResourceLimits.MaxMemoryMB)
+	metricsCollector.Start()
+	resourceMonitor.Start()
+
+	runCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	go func() {
+		select {
+		case breach := <-resourceMonitor.Breaches():
+			cancel(breach)
+		case <-runCtx.Done():
+		}
+	}()
+
+	// Run all auctions concurrently, or as they arrive
+	var auctionResults []*types.AuctionResult
+	if arrivals != nil {
+		auctionResults, err = orchestrator.RunStream(runCtx, arrivals)
+	} else {
+		auctionResults, err = orchestrator.RunAllAuctions(runCtx)
+	}
+
+	// Stop metrics and derive run statistics
+	simulationMetrics := metricsCollector.Stop()
+	resourceReadings := resourceMonitor.Stop()
+	if writer != nil {
+		if err := writer.Stop(); err != nil {
+			logger.Printf("Warning: Could not save checkpoint: %v", err)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error running auctions: %w", err)
+	}
+
+	simulationMetrics.RunID = runID
+	simulationMetrics.Seed = cfg.Seed
+	simulationMetrics.TotalDuration = time.Since(simulationStart)
+	if resume != nil {
+		auctionResults = mergeResults(auctionManager.GetAuctions(), resume.Results, auctionResults)
+		simulationMetrics.TotalDuration += resume.Elapsed
+	}
+	simulationMetrics.TotalAuctions = cfg.TotalAuctions
+	simulationMetrics.TotalBidders = cfg.TotalBidders
+	simulationMetrics.Groups = bidderManager.Groups()
+	simulationMetrics.ApplyResults(auctionResults)
+	if arrivals != nil || opts.partition != nil || items != nil {
+		simulationMetrics.TotalAuctions = len(auctionResults)
+	}
+	if items != nil {
+		simulationMetrics.ApplyInventory(auctionResults, items.Quantities())
+		simulationMetrics.Inventory.StockOuts = orchestrator.StockOuts()
+	}
+	if arrivals != nil {
+		simulationMetrics.ApplyStream(auctionResults, cfg.Arrival)
+	}
+	simulationMetrics.ConcurrencyLimitMin, simulationMetrics.ConcurrencyLimitMax = orchestrator.Limiter().Range()
+	simulationMetrics.ConcurrencyLimitFinal = orchestrator.Limiter().Limit()
+	if cfg.Faults.Enabled() {
+		simulationMetrics.FaultsInjected = orchestrator.FaultsInjected()
+	}
+	simulationMetrics.LearnedShades = orchestrator.LearnedShades()
+	simulationMetrics.Notices, simulationMetrics.NoticeFailures = orchestrator.Notices()
+
+	report := &metrics.RunReport{
+		Config:    cfg,
+		Metrics:   simulationMetrics,
+		Results:   auctionResults,
+		Resources: resourceReadings,
+	}
+
+	// A breach or an interrupt stops the run early; return what was collected
+	// so it can still be reported and stored
+	var breach *metrics.MemoryBreach
+	if errors.As(context.Cause(runCtx), &breach) {
+		simulationMetrics.Incomplete = true
+		simulationMetrics.Failure = breach.Error()
+		logger.Printf("Run %s failed: %v", simulationMetrics.RunID, breach)
+		return report, breach
+	}
+	if err := ctx.Err(); err != nil {
+		simulationMetrics.Incomplete = true
+		simulationMetrics.Failure = fmt.Sprintf("interrupted: %v", context.Cause(ctx))
+		logger.Printf("Run %s interrupted after %v", simulationMetrics.RunID,
+			simulationMetrics.TotalDuration.Round(time.Millisecond))
+		return report, err
+	}
+
+	logger.Printf("Run %s finished in %v (seed %d)", simulationMetrics.RunID,
+		simulationMetrics.TotalDuration.Round(time.Millisecond), cfg.Seed)
+
+	return report, nil
+}
+
+// resumedConfig returns the config for resuming a run with completed
+// auctions done. In the original run every auction started at once and
+// shared the bidder concurrency with all the others; the auctions still to
+// run get the same share, so they see the same contention and bidder
+// timeouts as they would have without the interruption.
+func resumedConfig(cfg *config.Config, completed int) *config.Config {
+	if completed == 0 || cfg.TotalAuctions <= completed {
+		return cfg
+	}
+
+	resumed := *cfg
+	limits := &resumed.ResourceLimits
+	pending := cfg.TotalAuctions - completed
+	limits.MaxConcurrentBidders = max(1, (limits.MaxConcurrentBidders*pending+cfg.TotalAuctions-1)/cfg.TotalAuctions)
+	return &resumed
+}
+
+// recordedSpend returns what bidders paid in the recorded auctions. The
+// live ledger also holds the charges of auctions still running, which a
+// resumed run runs again and would charge twice.
+func recordedSpend(results []*types.AuctionResult) map[string]float64 {
+	spent := make(map[string]float64)
+	for _, result := range results {
+		if result.Error != nil {
+			continue
+		}
+		for _, share := range metrics.WinShares(result) {
+			spent[share.BidderID] += share.Price
+		}
+	}
+	return spent
+}
+
+// mergeResults combines the results saved in a checkpoint with those of the
+// resumed auctions, in auction order
+func mergeResults(auctions []*auction.Auction, saved, resumed []*types.AuctionResult) []*types.AuctionResult {
+	byID := make(map[string]*types.AuctionResult, len(saved)+len(resumed))
+	for _, result := range saved {
+		byID[result.AuctionID] = result
+	}
+	for _, result := range resumed {
+		byID[result.AuctionID] = result
+	}
+
+	merged := make([]*types.AuctionResult, 0, len(auctions))
+	for _, auct := range auctions {
+		if result, ok := byID[auct.ID]; ok {
+			merged = append(merged, result)
+		}
+	}
+	return merged
+}
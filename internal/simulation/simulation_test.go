@@ -0,0 +1,163 @@
+package simulation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"auction-simulator/internal/checkpoint"
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/metrics"
+	"auction-simulator/internal/types"
+)
+
+// pacer is a remote bidder that never bids but answers auction-N after N
+// steps, so auctions complete one after another. It can stop the run when
+// auction-N is asked.
+type pacer struct {
+	step time.Duration
+
+	mu        sync.Mutex
+	stopAt    int
+	interrupt context.CancelFunc
+}
+
+func (p *pacer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var request types.BidRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	n, _ := strconv.Atoi(strings.TrimPrefix(request.AuctionID, "auction-"))
+
+	select {
+	case <-time.After(time.Duration(n) * p.step):
+	case <-r.Context().Done():
+		return
+	}
+
+	p.mu.Lock()
+	if p.interrupt != nil && n == p.stopAt {
+		p.interrupt()
+	}
+	p.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// stopRunAt makes the pacer cancel a run when auction-n is asked
+func (p *pacer) stopRunAt(n int, cancel context.CancelFunc) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stopAt, p.interrupt = n, cancel
+}
+
+func testConfig(endpoint string) *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.Seed = 7
+	cfg.TotalAuctions = 8
+	cfg.TotalBidders = 10
+	cfg.AuctionTimeout = 10 * time.Second
+	cfg.Bidders.MinSpeedMS, cfg.Bidders.MaxSpeedMS = 1, 5
+	cfg.Bidders.Budget = 10000
+	cfg.Remote = []config.RemoteBidder{{ID: "pacer", Endpoint: endpoint}}
+	return cfg
+}
+
+func TestResumeMatchesUninterruptedRun(t *testing.T) {
+	pace := &pacer{step: 60 * time.Millisecond}
+	server := httptest.NewServer(pace)
+	defer server.Close()
+	cfg := testConfig(server.URL)
+
+	full, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("uninterrupted run: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	opts := CheckpointOptions{Path: path, Interval: time.Hour}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pace.stopRunAt(4, cancel)
+	if _, err := RunWithCheckpoints(ctx, cfg, opts); !errors.Is(err, context.Canceled) {
+		t.Fatalf("interrupted run returned %v, want context.Canceled", err)
+	}
+	pace.stopRunAt(0, nil)
+
+	cp, err := checkpoint.Load(path)
+	if err != nil {
+		t.Fatalf("loading checkpoint: %v", err)
+	}
+	if n := len(cp.Results); n == 0 || n >= cfg.TotalAuctions {
+		t.Fatalf("checkpoint has %d of %d auctions, want some but not all", n, cfg.TotalAuctions)
+	}
+
+	// Auctions cut short are not charged, and only recorded ones count
+	spent, revenue := 0.0, 0.0
+	for _, amount := range cp.Spent {
+		spent += amount
+	}
+	for _, result := range cp.Results {
+		revenue += metrics.Revenue(result)
+	}
+	if math.Abs(spent-revenue) > 1e-6 {
+		t.Errorf("checkpoint records $%.2f spent, recorded auctions earned $%.2f", spent, revenue)
+	}
+
+	resumed, err := Resume(context.Background(), cp, opts)
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if len(resumed.Results) != len(full.Results) {
+		t.Fatalf("resumed run has %d results, uninterrupted run %d", len(resumed.Results), len(full.Results))
+	}
+	for i, want := range full.Results {
+		if diff := diffResults(resumed.Results[i], want); diff != "" {
+			t.Errorf("%s: %s", want.AuctionID, diff)
+		}
+	}
+	if got, want := resumed.Metrics.TotalRevenue, full.Metrics.TotalRevenue; math.Abs(got-want) > 1e-6 {
+		t.Errorf("resumed revenue $%.2f, uninterrupted $%.2f", got, want)
+	}
+}
+
+// diffResults describes how two results of the same auction differ, ignoring
+// timing
+func diffResults(got, want *types.AuctionResult) string {
+	switch {
+	case got.AuctionID != want.AuctionID:
+		return "auction " + got.AuctionID + " in its place"
+	case (got.Winner == nil) != (want.Winner == nil):
+		return "winner differs"
+	case got.Winner != nil && got.Winner.BidderID != want.Winner.BidderID:
+		return "won by " + got.Winner.BidderID + ", want " + want.Winner.BidderID
+	case math.Abs(got.ClearingPrice-want.ClearingPrice) > 1e-9:
+		return "clearing price " + strconv.FormatFloat(got.ClearingPrice, 'f', 2, 64) +
+			", want " + strconv.FormatFloat(want.ClearingPrice, 'f', 2, 64)
+	case (got.Error == nil) != (want.Error == nil):
+		return "error differs"
+	}
+
+	bids := make(map[string]float64, len(want.Bids))
+	for _, bid := range want.Bids {
+		bids[bid.BidderID] = bid.Amount
+	}
+	if len(got.Bids) != len(want.Bids) {
+		return strconv.Itoa(len(got.Bids)) + " bids, want " + strconv.Itoa(len(want.Bids))
+	}
+	for _, bid := range got.Bids {
+		if amount, ok := bids[bid.BidderID]; !ok || amount != bid.Amount {
+			return "bid of " + bid.BidderID + " differs"
+		}
+	}
+	return ""
+}
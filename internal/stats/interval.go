@@ -0,0 +1,126 @@
+package stats
+
+import "math"
+
+// MeanCI returns a Student-t confidence interval for the mean of a sample
+func MeanCI(sample []float64, confidence float64) Interval {
+	mean := Mean(sample)
+	if len(sample) < 2 {
+		return Interval{Lower: mean, Upper: mean}
+	}
+
+	t := TQuantile(1-(1-confidence)/2, float64(len(sample)-1))
+	halfWidth := t * StdDev(sample) / math.Sqrt(float64(len(sample)))
+	return Interval{Lower: mean - halfWidth, Upper: mean + halfWidth}
+}
+
+// TQuantile returns the p-quantile of Student's t distribution with df
+// degrees of freedom
+func TQuantile(p, df float64) float64 {
+	if p == 0.5 {
+		return 0
+	}
+	if p < 0.5 {
+		return -TQuantile(1-p, df)
+	}
+
+	// Bisection on the CDF; the upper bound is grown until it brackets p
+	lo, hi := 0.0, 1.0
+	for tCDF(hi, df) < p {
+		hi *= 2
+	}
+	for i := 0; i < 100; i++ {
+		mid := (lo + hi) / 2
+		if tCDF(mid, df) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// tCDF is the cumulative distribution function of Student's t
+func tCDF(t, df float64) float64 {
+	x := df / (df + t*t)
+	tail := 0.5 * RegularizedBeta(x, df/2, 0.5)
+	if t >= 0 {
+		return 1 - tail
+	}
+	return tail
+}
+
+// RegularizedBeta computes the regularized incomplete beta function I_x(a, b)
+func RegularizedBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+
+	lbeta := lgamma(a+b) - lgamma(a) - lgamma(b)
+	front := math.Exp(lbeta + a*math.Log(x) + b*math.Log(1-x))
+
+	// The continued fraction converges fastest on this side of the mean
+	if x < (a+1)/(a+b+2) {
+		return front * betaContinuedFraction(x, a, b) / a
+	}
+	return 1 - front*betaContinuedFraction(1-x, b, a)/b
+}
+
+// betaContinuedFraction evaluates the incomplete beta continued fraction
+// using the modified Lentz method
+func betaContinuedFraction(x, a, b float64) float64 {
+	const tiny = 1e-300
+
+	c := 1.0
+	d := 1 - (a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+
+	for m := 1; m < 500; m++ {
+		fm := float64(m)
+
+		// Even step
+		num := fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+
+		// Odd step
+		num = -(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+
+		if math.Abs(delta-1) < 1e-14 {
+			break
+		}
+	}
+	return h
+}
+
+// lgamma returns the natural log of the absolute gamma function
+func lgamma(x float64) float64 {
+	v, _ := math.Lgamma(x)
+	return v
+}
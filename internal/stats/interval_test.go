@@ -0,0 +1,60 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTQuantile(t *testing.T) {
+	// Values from standard t tables
+	tests := []struct {
+		p, df, want float64
+	}{
+		{0.975, 1, 12.7062},
+		{0.975, 2, 4.3027},
+		{0.975, 5, 2.5706},
+		{0.975, 10, 2.2281},
+		{0.975, 30, 2.0423},
+		{0.95, 10, 1.8125},
+		{0.995, 20, 2.8453},
+		{0.9, 4, 1.5332},
+		{0.5, 7, 0},
+		{0.025, 2, -4.3027},
+	}
+	for _, tt := range tests {
+		if got := TQuantile(tt.p, tt.df); math.Abs(got-tt.want) > 5e-4 {
+			t.Errorf("TQuantile(%g, %g) = %.4f, want %.4f", tt.p, tt.df, got, tt.want)
+		}
+	}
+}
+
+func TestRegularizedBeta(t *testing.T) {
+	tests := []struct {
+		x, a, b, want float64
+	}{
+		{0.5, 3, 3, 0.5},           // symmetric
+		{0.3, 1, 1, 0.3},           // uniform
+		{0.6, 2.5, 1, 0.278855},    // x^a
+		{0.4, 2, 3, 0.5248},        // binomial tail: P(Bin(4, 0.4) >= 2)
+		{0.9, 10, 2, 0.697356},     // upper side of the mean
+		{0, 2, 2, 0},               // bounds
+		{1, 2, 2, 1},               //
+		{0.2, 0.5, 0.5, 0.2951672}, // arcsine: 2/π·asin(√x)
+	}
+	for _, tt := range tests {
+		if got := RegularizedBeta(tt.x, tt.a, tt.b); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("I_%g(%g, %g) = %.7f, want %.7f", tt.x, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMeanCI(t *testing.T) {
+	ci := MeanCI([]float64{1, 2, 3, 4, 5}, 0.95)
+	// 3 ± t(0.975, 4)·√2.5/√5 = 3 ± 2.7764·0.7071
+	if math.Abs(ci.Lower-1.03676) > 1e-4 || math.Abs(ci.Upper-4.96324) > 1e-4 {
+		t.Errorf("MeanCI = [%.5f, %.5f], want [1.03676, 4.96324]", ci.Lower, ci.Upper)
+	}
+	if ci := MeanCI([]float64{7}, 0.95); ci.Lower != 7 || ci.Upper != 7 {
+		t.Errorf("single value interval [%g, %g], want [7, 7]", ci.Lower, ci.Upper)
+	}
+}
@@ -0,0 +1,186 @@
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Interval is a two-sided confidence interval
+type Interval struct {
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+// Contains reports whether v lies inside the interval
+func (i Interval) Contains(v float64) bool {
+	return v >= i.Lower && v <= i.Upper
+}
+
+// Statistic reduces a sample to a single value
+type Statistic func(sample []float64) float64
+
+// Mean returns the arithmetic mean, or 0 for an empty sample
+func Mean(sample []float64) float64 {
+	if len(sample) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range sample {
+		sum += v
+	}
+	return sum / float64(len(sample))
+}
+
+// StdDev returns the sample standard deviation (n-1 denominator)
+func StdDev(sample []float64) float64 {
+	if len(sample) < 2 {
+		return 0
+	}
+	mean := Mean(sample)
+	sum := 0.0
+	for _, v := range sample {
+		sum += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sum / float64(len(sample)-1))
+}
+
+// Percentile returns the p-th percentile (0-100) using linear interpolation
+func Percentile(sample []float64, p float64) float64 {
+	if len(sample) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), sample...)
+	sort.Float64s(sorted)
+	return sortedPercentile(sorted, p)
+}
+
+// PercentileStat returns a Statistic computing the p-th percentile
+func PercentileStat(p float64) Statistic {
+	return func(sample []float64) float64 {
+		return Percentile(sample, p)
+	}
+}
+
+// sortedPercentile interpolates a percentile from an already sorted sample
+func sortedPercentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	frac := rank - float64(lo)
+	return sorted[lo] + (sorted[hi]-sorted[lo])*frac
+}
+
+// BootstrapDiff estimates a percentile confidence interval for
+// stat(b) - stat(a) by resampling both samples with replacement
+func BootstrapDiff(a, b []float64, stat Statistic, resamples int, confidence float64, rng *rand.Rand) Interval {
+	if len(a) == 0 || len(b) == 0 || resamples < 1 {
+		return Interval{Lower: math.NaN(), Upper: math.NaN()}
+	}
+
+	diffs := make([]float64, resamples)
+	bufA := make([]float64, len(a))
+	bufB := make([]float64, len(b))
+
+	for i := range diffs {
+		resample(a, bufA, rng)
+		resample(b, bufB, rng)
+		diffs[i] = stat(bufB) - stat(bufA)
+	}
+
+	sort.Float64s(diffs)
+	alpha := (1 - confidence) / 2
+	return Interval{
+		Lower: sortedPercentile(diffs, alpha*100),
+		Upper: sortedPercentile(diffs, (1-alpha)*100),
+	}
+}
+
+// resample fills dst with draws from src taken with replacement
+func resample(src, dst []float64, rng *rand.Rand) {
+	for i := range dst {
+		dst[i] = src[rng.Intn(len(src))]
+	}
+}
+
+// ChiSquareHomogeneity tests whether two count distributions over the same
+// categories differ, returning the statistic, degrees of freedom and p-value
+func ChiSquareHomogeneity(a, b map[string]int) (chi2 float64, df int, pValue float64) {
+	categories := make(map[string]struct{})
+	totalA, totalB := 0, 0
+	for k, v := range a {
+		categories[k] = struct{}{}
+		totalA += v
+	}
+	for k, v := range b {
+		categories[k] = struct{}{}
+		totalB += v
+	}
+
+	total := float64(totalA + totalB)
+	if totalA == 0 || totalB == 0 || len(categories) < 2 {
+		return 0, 0, 1
+	}
+
+	for k := range categories {
+		rowTotal := float64(a[k] + b[k])
+		expectedA := rowTotal * float64(totalA) / total
+		expectedB := rowTotal * float64(totalB) / total
+		chi2 += (float64(a[k]) - expectedA) * (float64(a[k]) - expectedA) / expectedA
+		chi2 += (float64(b[k]) - expectedB) * (float64(b[k]) - expectedB) / expectedB
+	}
+
+	df = len(categories) - 1
+	return chi2, df, 1 - regularizedGammaP(float64(df)/2, chi2/2)
+}
+
+// regularizedGammaP computes the regularized lower incomplete gamma function
+func regularizedGammaP(a, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	lgammaA, _ := math.Lgamma(a)
+
+	if x < a+1 {
+		// Series expansion
+		sum := 1.0 / a
+		term := sum
+		for n := 1; n < 500; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*1e-14 {
+				break
+			}
+		}
+		return sum * math.Exp(-x+a*math.Log(x)-lgammaA)
+	}
+
+	// Continued fraction (modified Lentz) for the upper tail
+	const tiny = 1e-300
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for n := 1; n < 500; n++ {
+		an := -float64(n) * (float64(n) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-14 {
+			break
+		}
+	}
+	return 1 - math.Exp(-x+a*math.Log(x)-lgammaA)*h
+}
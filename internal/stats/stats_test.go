@@ -0,0 +1,74 @@
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestRegularizedGammaP(t *testing.T) {
+	tests := []struct {
+		a, x, want float64
+	}{
+		{1, 2, 1 - math.Exp(-2)}, // exponential
+		{0.5, 1.920729, 0.95},    // chi-square df 1 at 3.8415
+		{1, 2.995732, 0.95},      // chi-square df 2 at 5.9915
+		{5, 9.153519, 0.95},      // chi-square df 10 at 18.307
+		{3, 0.5, 0.01438768},     // series side
+		{3, 8, 0.9862460},        // continued fraction side
+	}
+	for _, tt := range tests {
+		if got := regularizedGammaP(tt.a, tt.x); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("P(%g, %g) = %.7f, want %.7f", tt.a, tt.x, got, tt.want)
+		}
+	}
+}
+
+func TestChiSquareHomogeneity(t *testing.T) {
+	// 2x2 table with 15 expected in every cell
+	chi2, df, p := ChiSquareHomogeneity(map[string]int{"x": 10, "y": 20}, map[string]int{"x": 20, "y": 10})
+	if math.Abs(chi2-20.0/3) > 1e-9 || df != 1 || math.Abs(p-0.009823) > 1e-5 {
+		t.Errorf("got chi2 %.4f df %d p %.6f, want 6.6667 df 1 p 0.009823", chi2, df, p)
+	}
+
+	if chi2, df, p := ChiSquareHomogeneity(map[string]int{"x": 5, "y": 5}, map[string]int{"x": 5, "y": 5}); chi2 != 0 || df != 1 || p != 1 {
+		t.Errorf("identical counts: chi2 %g df %d p %g, want 0, 1, 1", chi2, df, p)
+	}
+	if _, df, p := ChiSquareHomogeneity(map[string]int{"x": 5}, nil); df != 0 || p != 1 {
+		t.Errorf("empty side: df %d p %g, want 0 and 1", df, p)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sample := []float64{4, 1, 3, 2}
+	for _, tt := range []struct{ p, want float64 }{{0, 1}, {50, 2.5}, {100, 4}, {25, 1.75}} {
+		if got := Percentile(sample, tt.p); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("Percentile(%g) = %g, want %g", tt.p, got, tt.want)
+		}
+	}
+	if sample[0] != 4 {
+		t.Error("Percentile sorted its input")
+	}
+}
+
+func TestBootstrapDiff(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	a, b := make([]float64, 200), make([]float64, 200)
+	for i := range a {
+		a[i] = 10 + rng.NormFloat64()
+		b[i] = 12 + rng.NormFloat64()
+	}
+	ci := BootstrapDiff(a, b, Mean, 2000, 0.95, rng)
+	diff := Mean(b) - Mean(a)
+	if !ci.Contains(diff) || ci.Contains(0) {
+		t.Errorf("interval [%.3f, %.3f] should hold the observed %.3f and exclude 0", ci.Lower, ci.Upper, diff)
+	}
+	// Normal theory: ±1.96·√(2/200)
+	if width := ci.Upper - ci.Lower; math.Abs(width-2*1.96*0.1) > 0.06 {
+		t.Errorf("interval width %.3f, want about %.3f", width, 2*1.96*0.1)
+	}
+
+	if ci := BootstrapDiff(nil, b, Mean, 100, 0.95, rng); !math.IsNaN(ci.Lower) || !math.IsNaN(ci.Upper) {
+		t.Errorf("empty sample interval [%g, %g], want NaN", ci.Lower, ci.Upper)
+	}
+}
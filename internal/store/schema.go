@@ -0,0 +1,146 @@
+package store
+
+// columnMigrations lists columns added after the original schema; Open adds
+// any that an existing database is missing
+var columnMigrations = []struct {
+	table, name, definition string
+}{
+	{"auctions", "reserve_price", "REAL NOT NULL DEFAULT 0"},
+	{"auctions", "attributes_json", "TEXT NOT NULL DEFAULT 'null'"},
+	{"runs", "failure", "TEXT NOT NULL DEFAULT ''"},
+	{"runs", "incomplete", "INTEGER NOT NULL DEFAULT 0"},
+	{"auctions", "aborted", "INTEGER NOT NULL DEFAULT 0"},
+	{"auctions", "rejected_json", "TEXT NOT NULL DEFAULT 'null'"},
+	{"auctions", "tie_json", "TEXT NOT NULL DEFAULT 'null'"},
+	{"auctions", "extensions", "INTEGER NOT NULL DEFAULT 0"},
+	{"auctions", "item_id", "TEXT NOT NULL DEFAULT ''"},
+}
+
+// schema creates the tables and convenience views; every statement is
+// idempotent so it runs on each Open
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	run_id              TEXT PRIMARY KEY,
+	start_time          TEXT NOT NULL,
+	end_time            TEXT NOT NULL,
+	duration_ms         REAL NOT NULL,
+	total_auctions      INTEGER NOT NULL,
+	total_bidders       INTEGER NOT NULL,
+	successful_auctions INTEGER NOT NULL,
+	failed_auctions     INTEGER NOT NULL,
+	total_bids          INTEGER NOT NULL,
+	max_goroutines      INTEGER NOT NULL,
+	memory_mb           REAL NOT NULL,
+	config_json         TEXT NOT NULL,
+	metrics_json        TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS auctions (
+	run_id      TEXT NOT NULL REFERENCES runs(run_id) ON DELETE CASCADE,
+	auction_id  TEXT NOT NULL,
+	winner_id   TEXT NOT NULL,
+	winning_bid REAL NOT NULL,
+	revenue     REAL NOT NULL,
+	total_bids  INTEGER NOT NULL,
+	duration_ms REAL NOT NULL,
+	success     INTEGER NOT NULL,
+	error       TEXT NOT NULL,
+	start_time  TEXT NOT NULL,
+	end_time    TEXT NOT NULL,
+	PRIMARY KEY (run_id, auction_id)
+);
+
+CREATE TABLE IF NOT EXISTS bids (
+	run_id     TEXT NOT NULL REFERENCES runs(run_id) ON DELETE CASCADE,
+	auction_id TEXT NOT NULL,
+	bidder_id  TEXT NOT NULL,
+	amount     REAL NOT NULL,
+	latency_ms REAL NOT NULL,
+	won        INTEGER NOT NULL,
+	timestamp  TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS bids_run_auction ON bids (run_id, auction_id);
+CREATE INDEX IF NOT EXISTS bids_run_bidder ON bids (run_id, bidder_id);
+
+CREATE TABLE IF NOT EXISTS resource_usage (
+	run_id     TEXT NOT NULL REFERENCES runs(run_id) ON DELETE CASCADE,
+	timestamp  TEXT NOT NULL,
+	goroutines INTEGER NOT NULL,
+	memory_mb  REAL NOT NULL
+);
+CREATE INDEX IF NOT EXISTS resource_usage_run ON resource_usage (run_id);
+
+CREATE TABLE IF NOT EXISTS batches (
+	batch_id     TEXT PRIMARY KEY,
+	created_at   TEXT NOT NULL,
+	runs         INTEGER NOT NULL,
+	base_seed    INTEGER NOT NULL,
+	config_json  TEXT NOT NULL,
+	summary_json TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS batch_runs (
+	batch_id  TEXT NOT NULL REFERENCES batches(batch_id) ON DELETE CASCADE,
+	run_index INTEGER NOT NULL,
+	run_id    TEXT NOT NULL REFERENCES runs(run_id),
+	seed      INTEGER NOT NULL,
+	PRIMARY KEY (batch_id, run_index)
+);
+
+-- One row per run with headline metrics
+CREATE VIEW IF NOT EXISTS run_summary AS
+SELECT
+	r.run_id,
+	r.start_time,
+	r.duration_ms,
+	r.total_auctions,
+	r.total_bidders,
+	r.successful_auctions,
+	r.total_bids,
+	COALESCE((SELECT SUM(a.revenue) FROM auctions a WHERE a.run_id = r.run_id), 0) AS total_revenue,
+	CAST(r.successful_auctions AS REAL) / NULLIF(r.total_auctions, 0) AS success_rate,
+	CAST(r.total_bids AS REAL) / NULLIF(r.total_auctions, 0) AS bids_per_auction,
+	(SELECT AVG(b.latency_ms) FROM bids b WHERE b.run_id = r.run_id) AS avg_latency_ms,
+	r.memory_mb,
+	r.max_goroutines
+FROM runs r;
+
+-- Per-bidder activity within each run
+CREATE VIEW IF NOT EXISTS bidder_performance AS
+SELECT
+	run_id,
+	bidder_id,
+	COUNT(*) AS bids,
+	SUM(won) AS wins,
+	CAST(SUM(won) AS REAL) / COUNT(*) AS win_rate,
+	SUM(CASE WHEN won THEN amount ELSE 0 END) AS total_spend,
+	AVG(amount) AS avg_bid,
+	MAX(amount) AS max_bid,
+	AVG(latency_ms) AS avg_latency_ms
+FROM bids
+GROUP BY run_id, bidder_id;
+
+-- Daily trend of revenue, success rate and latency across all runs
+CREATE VIEW IF NOT EXISTS daily_trend AS
+SELECT
+	DATE(start_time) AS day,
+	COUNT(*) AS runs,
+	AVG(total_revenue) AS avg_revenue,
+	AVG(total_revenue / NULLIF(total_auctions, 0)) AS avg_revenue_per_auction,
+	AVG(success_rate) AS avg_success_rate,
+	AVG(avg_latency_ms) AS avg_latency_ms
+FROM run_summary
+GROUP BY DATE(start_time);
+
+-- How often each bidder wins across the whole history
+CREATE VIEW IF NOT EXISTS bidder_history AS
+SELECT
+	bidder_id,
+	COUNT(DISTINCT run_id) AS runs,
+	SUM(bids) AS bids,
+	SUM(wins) AS wins,
+	CAST(SUM(wins) AS REAL) / SUM(bids) AS win_rate,
+	SUM(total_spend) AS total_spend
+FROM bidder_performance
+GROUP BY bidder_id;
+`
@@ -0,0 +1,449 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/metrics"
+	"auction-simulator/internal/types"
+)
+
+// DefaultPath is where run history is kept unless overridden
+const DefaultPath = "output/history.db"
+
+// ErrRunNotFound is returned when a run ID is not in the store
+var ErrRunNotFound = errors.New("run not found")
+
+// Store persists simulation runs in a local SQLite database
+type Store struct {
+	db *sql.DB
+}
+
+// RunSummary is one row of the run history listing
+type RunSummary struct {
+	RunID              string
+	StartTime          time.Time
+	TotalDuration      time.Duration
+	TotalAuctions      int
+	TotalBidders       int
+	SuccessfulAuctions int
+	TotalBids          int
+	TotalRevenue       float64
+}
+
+// Open opens or creates the database at path and ensures the schema exists
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("could not create database directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open database: %w", err)
+	}
+	// SQLite allows a single writer; serialize access through one connection
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not initialize schema: %w", err)
+	}
+
+	st := &Store{db: db}
+	if err := st.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return st, nil
+}
+
+// migrate adds columns introduced after a database was first created
+func (s *Store) migrate() error {
+	for _, col := range columnMigrations {
+		if err := s.ensureColumn(col.table, col.name, col.definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureColumn adds a column to table unless it already exists
+func (s *Store) ensureColumn(table, name, definition string) error {
+	rows, err := s.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("could not inspect table %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var colName, colType string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &colName, &colType, &notNull, &dflt, &pk); err != nil {
+			return fmt.Errorf("could not inspect table %s: %w", table, err)
+		}
+		if colName == name {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	if _, err := s.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, name, definition)); err != nil {
+		return fmt.Errorf("could not add column %s.%s: %w", table, name, err)
+	}
+	return nil
+}
+
+// Close releases the underlying database handle
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// SaveRun persists a complete run keyed by its metrics run ID. An earlier,
+// incomplete save of the same run, as left by an interrupted run that was
+// later resumed, is replaced.
+func (s *Store) SaveRun(report *metrics.RunReport) error {
+	if report.Metrics == nil || report.Metrics.RunID == "" {
+		return fmt.Errorf("run has no ID")
+	}
+	runID := report.Metrics.RunID
+
+	configJSON, err := json.Marshal(report.Config)
+	if err != nil {
+		return fmt.Errorf("could not encode config: %w", err)
+	}
+	metricsJSON, err := json.Marshal(report.Metrics)
+	if err != nil {
+		return fmt.Errorf("could not encode metrics: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var incomplete bool
+	switch err := tx.QueryRow(`SELECT incomplete FROM runs WHERE run_id = ?`, runID).Scan(&incomplete); {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		return fmt.Errorf("could not look up run: %w", err)
+	case !incomplete:
+		return fmt.Errorf("run %s is already saved", runID)
+	default:
+		for _, table := range []string{"bids", "auctions", "resource_usage", "runs"} {
+			if _, err := tx.Exec(`DELETE FROM `+table+` WHERE run_id = ?`, runID); err != nil {
+				return fmt.Errorf("could not replace incomplete run: %w", err)
+			}
+		}
+	}
+
+	m := report.Metrics
+	if _, err := tx.Exec(`INSERT INTO runs (run_id, start_time, end_time, duration_ms, total_auctions,
+		total_bidders, successful_auctions, failed_auctions, total_bids, max_goroutines, memory_mb,
+		config_json, metrics_json, failure, incomplete) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		runID, formatTime(m.StartTime), formatTime(m.EndTime), durationMS(m.TotalDuration),
+		m.TotalAuctions, m.TotalBidders, m.SuccessfulAuctions, m.FailedAuctions,
+		m.TotalBidsReceived, m.MaxGoroutines, m.MemoryUsageMB,
+		string(configJSON), string(metricsJSON), m.Failure, m.Incomplete); err != nil {
+		return fmt.Errorf("could not insert run: %w", err)
+	}
+
+	auctionStmt, err := tx.Prepare(`INSERT INTO auctions (run_id, auction_id, winner_id, winning_bid,
+		reserve_price, revenue, total_bids, duration_ms, success, aborted, extensions, error, start_time,
+		end_time, attributes_json, rejected_json, tie_json, item_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("could not prepare auction insert: %w", err)
+	}
+	defer auctionStmt.Close()
+
+	for i, row := range metrics.AuctionRows(report.Results) {
+		attributesJSON, err := json.Marshal(report.Results[i].Attributes)
+		if err != nil {
+			return fmt.Errorf("could not encode attributes of %s: %w", row.AuctionID, err)
+		}
+		rejectedJSON, err := json.Marshal(report.Results[i].Rejected)
+		if err != nil {
+			return fmt.Errorf("could not encode rejected bids of %s: %w", row.AuctionID, err)
+		}
+		tieJSON, err := json.Marshal(report.Results[i].Tie)
+		if err != nil {
+			return fmt.Errorf("could not encode tie of %s: %w", row.AuctionID, err)
+		}
+		if _, err := auctionStmt.Exec(runID, row.AuctionID, row.WinnerID, row.WinningBid,
+			row.ReservePrice, row.Revenue, row.TotalBids, row.DurationMS, row.Success, row.Aborted, row.Extensions, row.Error,
+			formatTime(row.StartTime), formatTime(row.EndTime), string(attributesJSON),
+			string(rejectedJSON), string(tieJSON), row.ItemID); err != nil {
+			return fmt.Errorf("could not insert auction %s: %w", row.AuctionID, err)
+		}
+	}
+
+	bidStmt, err := tx.Prepare(`INSERT INTO bids (run_id, auction_id, bidder_id, amount, latency_ms,
+		won, timestamp) VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("could not prepare bid insert: %w", err)
+	}
+	defer bidStmt.Close()
+
+	for _, row := range metrics.BidRows(report.Results) {
+		if _, err := bidStmt.Exec(runID, row.AuctionID, row.BidderID, row.Amount,
+			row.LatencyMS, row.Won, formatTime(row.Timestamp)); err != nil {
+			return fmt.Errorf("could not insert bid: %w", err)
+		}
+	}
+
+	resourceStmt, err := tx.Prepare(`INSERT INTO resource_usage (run_id, timestamp, goroutines,
+		memory_mb) VALUES (?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("could not prepare resource insert: %w", err)
+	}
+	defer resourceStmt.Close()
+
+	for _, reading := range report.Resources {
+		if _, err := resourceStmt.Exec(runID, formatTime(reading.Timestamp),
+			reading.GoroutineCount, reading.MemoryMB); err != nil {
+			return fmt.Errorf("could not insert resource reading: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit run: %w", err)
+	}
+	return nil
+}
+
+// SaveBatch records a batch summary and links it to its runs, which must
+// already have been saved with SaveRun
+func (s *Store) SaveBatch(summary *metrics.BatchSummary, cfg *config.Config) error {
+	configJSON, err := json.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("could not encode config: %w", err)
+	}
+	summaryJSON, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("could not encode batch summary: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT INTO batches (batch_id, created_at, runs, base_seed, config_json,
+		summary_json) VALUES (?, ?, ?, ?, ?, ?)`,
+		summary.BatchID, formatTime(time.Now()), summary.Runs, summary.BaseSeed,
+		string(configJSON), string(summaryJSON)); err != nil {
+		return fmt.Errorf("could not insert batch: %w", err)
+	}
+
+	for i, run := range summary.RunMetrics {
+		if _, err := tx.Exec(`INSERT INTO batch_runs (batch_id, run_index, run_id, seed)
+			VALUES (?, ?, ?, ?)`, summary.BatchID, i, run.RunID, run.Seed); err != nil {
+			return fmt.Errorf("could not link run %s: %w", run.RunID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit batch: %w", err)
+	}
+	return nil
+}
+
+// ListRuns returns the most recent runs first; limit <= 0 returns all runs
+func (s *Store) ListRuns(limit int) ([]RunSummary, error) {
+	query := `SELECT run_id, start_time, duration_ms, total_auctions, total_bidders,
+		successful_auctions, total_bids, total_revenue FROM run_summary ORDER BY start_time DESC`
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("could not list runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []RunSummary
+	for rows.Next() {
+		var run RunSummary
+		var start string
+		var durationMS float64
+		if err := rows.Scan(&run.RunID, &start, &durationMS, &run.TotalAuctions, &run.TotalBidders,
+			&run.SuccessfulAuctions, &run.TotalBids, &run.TotalRevenue); err != nil {
+			return nil, fmt.Errorf("could not read run: %w", err)
+		}
+		run.StartTime = parseTime(start)
+		run.TotalDuration = time.Duration(durationMS * float64(time.Millisecond))
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// LoadRun reconstructs a stored run including its results and bid books
+func (s *Store) LoadRun(runID string) (*metrics.RunReport, error) {
+	var configJSON, metricsJSON string
+	err := s.db.QueryRow(`SELECT config_json, metrics_json FROM runs WHERE run_id = ?`, runID).
+		Scan(&configJSON, &metricsJSON)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrRunNotFound, runID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not load run: %w", err)
+	}
+
+	report := &metrics.RunReport{
+		Config:  &config.Config{},
+		Metrics: &metrics.SimulationMetrics{},
+	}
+	if err := json.Unmarshal([]byte(configJSON), report.Config); err != nil {
+		return nil, fmt.Errorf("could not decode config: %w", err)
+	}
+	if err := json.Unmarshal([]byte(metricsJSON), report.Metrics); err != nil {
+		return nil, fmt.Errorf("could not decode metrics: %w", err)
+	}
+
+	if report.Results, err = s.loadResults(runID); err != nil {
+		return nil, err
+	}
+	if report.Resources, err = s.loadResources(runID); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// loadResults rebuilds auction results and attaches their bid books
+func (s *Store) loadResults(runID string) ([]*types.AuctionResult, error) {
+	rows, err := s.db.Query(`SELECT auction_id, winner_id, reserve_price, revenue, total_bids,
+		duration_ms, aborted, extensions, error, start_time, end_time, attributes_json, rejected_json, tie_json, item_id FROM auctions
+		WHERE run_id = ? ORDER BY rowid`, runID)
+	if err != nil {
+		return nil, fmt.Errorf("could not load auctions: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*types.AuctionResult
+	byID := make(map[string]*types.AuctionResult)
+	winners := make(map[string]string)
+
+	for rows.Next() {
+		var result types.AuctionResult
+		var winnerID, errText, start, end, attributesJSON, rejectedJSON, tieJSON string
+		var durationMS float64
+		if err := rows.Scan(&result.AuctionID, &winnerID, &result.ReservePrice,
+			&result.ClearingPrice, &result.TotalBids, &durationMS, &result.Aborted, &result.Extensions, &errText, &start, &end,
+			&attributesJSON, &rejectedJSON, &tieJSON, &result.ItemID); err != nil {
+			return nil, fmt.Errorf("could not read auction: %w", err)
+		}
+		if err := json.Unmarshal([]byte(attributesJSON), &result.Attributes); err != nil {
+			return nil, fmt.Errorf("could not decode attributes of %s: %w", result.AuctionID, err)
+		}
+		if err := json.Unmarshal([]byte(rejectedJSON), &result.Rejected); err != nil {
+			return nil, fmt.Errorf("could not decode rejected bids of %s: %w", result.AuctionID, err)
+		}
+		if err := json.Unmarshal([]byte(tieJSON), &result.Tie); err != nil {
+			return nil, fmt.Errorf("could not decode tie of %s: %w", result.AuctionID, err)
+		}
+		result.Duration = time.Duration(durationMS * float64(time.Millisecond))
+		result.StartTime = parseTime(start)
+		result.EndTime = parseTime(end)
+		if errText != "" {
+			result.Error = errors.New(errText)
+		}
+
+		results = append(results, &result)
+		byID[result.AuctionID] = &result
+		winners[result.AuctionID] = winnerID
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	bidRows, err := s.db.Query(`SELECT auction_id, bidder_id, amount, latency_ms, won, timestamp
+		FROM bids WHERE run_id = ? ORDER BY rowid`, runID)
+	if err != nil {
+		return nil, fmt.Errorf("could not load bids: %w", err)
+	}
+	defer bidRows.Close()
+
+	winningIndex := make(map[string]int)
+	for bidRows.Next() {
+		var auctionID, timestamp string
+		var latencyMS float64
+		var won bool
+		var bid types.Bid
+		if err := bidRows.Scan(&auctionID, &bid.BidderID, &bid.Amount, &latencyMS, &won,
+			&timestamp); err != nil {
+			return nil, fmt.Errorf("could not read bid: %w", err)
+		}
+		bid.Latency = time.Duration(latencyMS * float64(time.Millisecond))
+		bid.Timestamp = parseTime(timestamp)
+
+		result, ok := byID[auctionID]
+		if !ok {
+			continue
+		}
+		if won && bid.BidderID == winners[auctionID] {
+			winningIndex[auctionID] = len(result.Bids)
+		}
+		result.Bids = append(result.Bids, bid)
+	}
+	if err := bidRows.Err(); err != nil {
+		return nil, err
+	}
+
+	for auctionID, idx := range winningIndex {
+		result := byID[auctionID]
+		result.Winner = &result.Bids[idx]
+	}
+	return results, nil
+}
+
+// loadResources returns the resource timeline recorded for a run
+func (s *Store) loadResources(runID string) ([]metrics.ResourceUsage, error) {
+	rows, err := s.db.Query(`SELECT timestamp, goroutines, memory_mb FROM resource_usage
+		WHERE run_id = ? ORDER BY timestamp`, runID)
+	if err != nil {
+		return nil, fmt.Errorf("could not load resource usage: %w", err)
+	}
+	defer rows.Close()
+
+	var readings []metrics.ResourceUsage
+	for rows.Next() {
+		var reading metrics.ResourceUsage
+		var timestamp string
+		if err := rows.Scan(&timestamp, &reading.GoroutineCount, &reading.MemoryMB); err != nil {
+			return nil, fmt.Errorf("could not read resource usage: %w", err)
+		}
+		reading.Timestamp = parseTime(timestamp)
+		readings = append(readings, reading)
+	}
+	return readings, rows.Err()
+}
+
+// formatTime stores timestamps as sortable RFC 3339 text
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// parseTime reads a timestamp written by formatTime
+func parseTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, s)
+	return t
+}
+
+// durationMS converts a duration to fractional milliseconds
+func durationMS(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
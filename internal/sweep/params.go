@@ -0,0 +1,245 @@
+package sweep
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"auction-simulator/internal/config"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Dimension is one swept configuration field with either a list of
+// discrete values or a numeric range
+type Dimension struct {
+	Field  string   // dotted path inside config.Config, e.g. "Bidders.MaxBaseBid"
+	Values []string // discrete values; empty when the dimension is a range
+
+	Min   float64 // range bounds, in nanoseconds for durations
+	Max   float64
+	Steps int  // grid points across the range
+	Log   bool // space grid points logarithmically
+
+	fieldType reflect.Type
+}
+
+// IsRange reports whether the dimension is a numeric range
+func (d *Dimension) IsRange() bool {
+	return len(d.Values) == 0
+}
+
+// ParseDimension parses a "Field=spec" flag value. spec is either a
+// comma-separated list ("10,100,1000") or a range "min..max:steps" with an
+// optional ":log" suffix ("50ms..2s:5", "10..1000:4:log"). Field may be a
+// dotted path or an unambiguous leaf name such as "MaxBaseBid".
+func ParseDimension(arg string) (*Dimension, error) {
+	name, spec, ok := strings.Cut(arg, "=")
+	if !ok || name == "" || spec == "" {
+		return nil, fmt.Errorf("invalid sweep parameter %q, expected Field=values", arg)
+	}
+
+	path, fieldType, err := resolveField(name)
+	if err != nil {
+		return nil, err
+	}
+	dim := &Dimension{Field: path, fieldType: fieldType}
+
+	lo, rest, isRange := strings.Cut(spec, "..")
+	if !isRange {
+		dim.Values = strings.Split(spec, ",")
+		for _, v := range dim.Values {
+			if _, err := parseNumber(v, fieldType); err != nil && !isTextual(fieldType) {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+		}
+		return dim, nil
+	}
+
+	if !isNumeric(fieldType) {
+		return nil, fmt.Errorf("%s: ranges need a numeric or duration field", path)
+	}
+
+	parts := strings.Split(rest, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("%s: range must look like min..max:steps[:log]", path)
+	}
+	if dim.Min, err = parseNumber(lo, fieldType); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if dim.Max, err = parseNumber(parts[0], fieldType); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if dim.Steps, err = strconv.Atoi(parts[1]); err != nil || dim.Steps < 1 {
+		return nil, fmt.Errorf("%s: invalid step count %q", path, parts[1])
+	}
+	if len(parts) == 3 {
+		if parts[2] != "log" {
+			return nil, fmt.Errorf("%s: unknown range option %q", path, parts[2])
+		}
+		if dim.Min <= 0 {
+			return nil, fmt.Errorf("%s: log ranges need a positive minimum", path)
+		}
+		dim.Log = true
+	}
+	return dim, nil
+}
+
+// GridValues expands the dimension into its discrete grid values
+func (d *Dimension) GridValues() []string {
+	if !d.IsRange() {
+		return d.Values
+	}
+
+	values := make([]string, d.Steps)
+	for i := range values {
+		frac := 0.0
+		if d.Steps > 1 {
+			frac = float64(i) / float64(d.Steps-1)
+		}
+		values[i] = d.format(d.interpolate(frac))
+	}
+	return values
+}
+
+// SampleValue maps a unit-interval position to a value of the dimension
+func (d *Dimension) SampleValue(u float64) string {
+	if !d.IsRange() {
+		idx := int(u * float64(len(d.Values)))
+		if idx >= len(d.Values) {
+			idx = len(d.Values) - 1
+		}
+		return d.Values[idx]
+	}
+	return d.format(d.interpolate(u))
+}
+
+// interpolate returns the range value at fraction frac of the way from Min to Max
+func (d *Dimension) interpolate(frac float64) float64 {
+	if d.Log {
+		return math.Exp(math.Log(d.Min) + frac*(math.Log(d.Max)-math.Log(d.Min)))
+	}
+	return d.Min + frac*(d.Max-d.Min)
+}
+
+// format renders a numeric range value in the field's native syntax
+func (d *Dimension) format(v float64) string {
+	switch {
+	case d.fieldType == durationType:
+		return time.Duration(math.Round(v)).String()
+	case d.fieldType.Kind() == reflect.Float64:
+		return strconv.FormatFloat(v, 'g', 6, 64)
+	default:
+		return strconv.FormatInt(int64(math.Round(v)), 10)
+	}
+}
+
+// Apply sets a field of cfg from its string representation
+func Apply(cfg *config.Config, path, value string) error {
+	field := reflect.ValueOf(cfg).Elem()
+	for _, part := range strings.Split(path, ".") {
+		field = field.FieldByName(part)
+		if !field.IsValid() {
+			return fmt.Errorf("unknown config field %s", path)
+		}
+	}
+
+	switch {
+	case field.Type() == durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		field.SetInt(int64(d))
+	case field.Kind() == reflect.Int || field.Kind() == reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		field.SetInt(n)
+	case field.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		field.SetFloat(f)
+	case field.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		field.SetBool(b)
+	case field.Kind() == reflect.String:
+		field.SetString(value)
+	default:
+		return fmt.Errorf("%s: unsupported field type %s", path, field.Type())
+	}
+	return nil
+}
+
+// resolveField finds a config field by dotted path or unique leaf name,
+// matching names case-insensitively
+func resolveField(name string) (string, reflect.Type, error) {
+	var matches []string
+	types := make(map[string]reflect.Type)
+
+	var walk func(prefix string, t reflect.Type)
+	walk = func(prefix string, t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			path := f.Name
+			if prefix != "" {
+				path = prefix + "." + f.Name
+			}
+			if f.Type.Kind() == reflect.Struct {
+				walk(path, f.Type)
+				continue
+			}
+			if strings.EqualFold(path, name) || strings.EqualFold(f.Name, name) {
+				matches = append(matches, path)
+				types[path] = f.Type
+			}
+		}
+	}
+	walk("", reflect.TypeOf(config.Config{}))
+
+	switch len(matches) {
+	case 0:
+		return "", nil, fmt.Errorf("unknown config field %q", name)
+	case 1:
+		return matches[0], types[matches[0]], nil
+	}
+	for _, m := range matches {
+		if strings.EqualFold(m, name) {
+			return m, types[m], nil
+		}
+	}
+	return "", nil, fmt.Errorf("ambiguous config field %q matches %s", name, strings.Join(matches, ", "))
+}
+
+// parseNumber reads a numeric field value, durations as nanoseconds
+func parseNumber(s string, t reflect.Type) (float64, error) {
+	s = strings.TrimSpace(s)
+	if t == durationType {
+		d, err := time.ParseDuration(s)
+		return float64(d), err
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// isNumeric reports whether a field type can be swept over a range
+func isNumeric(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int64, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// isTextual reports whether values of a field type are not parsed as numbers
+func isTextual(t reflect.Type) bool {
+	return t.Kind() == reflect.String || t.Kind() == reflect.Bool
+}
@@ -0,0 +1,294 @@
+package sweep
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"sync"
+
+	"auction-simulator/internal/config"
+	"auction-simulator/internal/metrics"
+	"auction-simulator/internal/simulation"
+	"auction-simulator/pkg/utils"
+)
+
+// Sampling modes for combining dimensions into points
+const (
+	ModeGrid           = "grid" // full Cartesian product
+	ModeLatinHypercube = "lhs"  // Latin-hypercube sample of Samples points
+)
+
+// Assignment sets one config field to a value
+type Assignment struct {
+	Field string
+	Value string
+}
+
+// Point is one parameter combination of a sweep
+type Point []Assignment
+
+// Options controls how a sweep samples and evaluates points
+type Options struct {
+	Mode        string
+	Samples     int // points drawn in Latin-hypercube mode
+	Repetitions int // runs per point
+	Parallel    int // runs executing at once within a point
+	Confidence  float64
+
+	// Points evaluated at once, each with its own RunBatch; <= 1 runs them
+	// one after another
+	Concurrency int
+	// RunBatch evaluates one point; nil runs it in this process with
+	// simulation.RunBatch
+	RunBatch func(ctx context.Context, cfg *config.Config, opts simulation.BatchOptions) (*simulation.Batch, error)
+	// Log receives progress messages; nil uses the standard logger
+	Log io.Writer
+}
+
+// Row is the evaluated outcome of one point
+type Row struct {
+	Point   Point
+	Summary *metrics.BatchSummary // nil for an invalid point
+	Invalid error                 // why the point's config cannot be run
+}
+
+// Points combines the dimensions into the parameter combinations to run
+func Points(dims []*Dimension, opts Options, seed int64) ([]Point, error) {
+	switch opts.Mode {
+	case ModeGrid:
+		return gridPoints(dims), nil
+	case ModeLatinHypercube:
+		if opts.Samples < 1 {
+			return nil, fmt.Errorf("latin hypercube sampling needs at least one sample")
+		}
+		return latinHypercubePoints(dims, opts.Samples, utils.NewRNG(utils.DeriveSeed(seed, "sweep"))), nil
+	default:
+		return nil, fmt.Errorf("unknown sweep mode: %s", opts.Mode)
+	}
+}
+
+// gridPoints returns the Cartesian product of every dimension's grid values
+func gridPoints(dims []*Dimension) []Point {
+	points := []Point{{}}
+	for _, dim := range dims {
+		var next []Point
+		for _, point := range points {
+			for _, value := range dim.GridValues() {
+				extended := append(append(Point(nil), point...), Assignment{Field: dim.Field, Value: value})
+				next = append(next, extended)
+			}
+		}
+		points = next
+	}
+	return points
+}
+
+// latinHypercubePoints draws n points so that each dimension's range is
+// split into n strata and every stratum is used exactly once
+func latinHypercubePoints(dims []*Dimension, n int, rng *utils.RNG) []Point {
+	points := make([]Point, n)
+	for _, dim := range dims {
+		strata := make([]int, n)
+		for i := range strata {
+			strata[i] = i
+		}
+		for i := n - 1; i > 0; i-- {
+			j := rng.Intn(i + 1)
+			strata[i], strata[j] = strata[j], strata[i]
+		}
+
+		for i := range points {
+			u := (float64(strata[i]) + rng.Float64()) / float64(n)
+			points[i] = append(points[i], Assignment{Field: dim.Field, Value: dim.SampleValue(u)})
+		}
+	}
+	return points
+}
+
+// Run evaluates every point against base. All points share base.Seed so
+// differences between rows come from the parameters, not the random draws.
+// A point whose config is invalid, such as a minimum above its maximum, is
+// not run and gets a row with the reason instead.
+//
+// If ctx is cancelled, the rows of the points finished so far are returned
+// with ctx.Err(); a partially run point is left out.
+func Run(ctx context.Context, base *config.Config, points []Point, opts Options) ([]Row, error) {
+	configs := make([]*config.Config, len(points))
+	invalid := make([]error, len(points))
+	for i, point := range points {
+		cfg, err := pointConfig(base, point)
+		if err != nil {
+			return nil, err
+		}
+		configs[i] = cfg
+		invalid[i] = cfg.Validate()
+	}
+
+	runBatch := opts.RunBatch
+	if runBatch == nil {
+		runBatch = simulation.RunBatch
+	}
+	concurrency := max(1, opts.Concurrency)
+	logger := log.Default()
+	if opts.Log != nil {
+		logger = log.New(opts.Log, "", log.LstdFlags)
+	}
+
+	summaries := make([]*metrics.BatchSummary, len(points))
+	errs := make([]error, len(points))
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+
+	for i, point := range points {
+		if invalid[i] != nil {
+			logger.Printf("Sweep point %d/%d: %v skipped: %v", i+1, len(points), point, invalid[i])
+			continue
+		}
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(idx int, point Point) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			logger.Printf("Sweep point %d/%d: %v", idx+1, len(points), point)
+			batch, err := runBatch(ctx, configs[idx], simulation.BatchOptions{
+				Runs:       opts.Repetitions,
+				Parallel:   opts.Parallel,
+				Confidence: opts.Confidence,
+				Shared:     concurrency > 1 && opts.RunBatch == nil,
+				Log:        opts.Log,
+			})
+			if err != nil {
+				errs[idx] = err
+				return
+			}
+			summaries[idx] = batch.Summary
+		}(i, point)
+	}
+	wg.Wait()
+
+	rows := make([]Row, 0, len(points))
+	for i, point := range points {
+		if errs[i] != nil && ctx.Err() == nil {
+			return nil, fmt.Errorf("sweep point %v: %w", point, errs[i])
+		}
+		if invalid[i] != nil {
+			rows = append(rows, Row{Point: point, Invalid: invalid[i]})
+		}
+		if summaries[i] != nil && errs[i] == nil {
+			rows = append(rows, Row{Point: point, Summary: summaries[i]})
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return rows, err
+	}
+	return rows, nil
+}
+
+// pointConfig applies a point to a copy of base. The concurrent bidder limit
+// follows the number of bidders and auctions, as it does for the defaults,
+// unless the point sets it.
+func pointConfig(base *config.Config, point Point) (*config.Config, error) {
+	cfg := *base
+	rederive := false
+	for _, assignment := range point {
+		if err := Apply(&cfg, assignment.Field, assignment.Value); err != nil {
+			return nil, err
+		}
+		switch assignment.Field {
+		case "TotalBidders", "TotalAuctions":
+			rederive = true
+		}
+	}
+	if rederive && !point.Sets("ResourceLimits.MaxConcurrentBidders") {
+		cfg.ResourceLimits.MaxConcurrentBidders = config.ConcurrentBidderLimit(
+			cfg.ResourceLimits.MaxVCPUs, cfg.TotalBidders, cfg.TotalAuctions)
+	}
+	return &cfg, nil
+}
+
+// Sets reports whether the point assigns field
+func (p Point) Sets(field string) bool {
+	for _, assignment := range p {
+		if assignment.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteCSV writes the sweep as a tidy table: one row per point with the
+// parameter values followed by the mean and confidence interval of every
+// headline metric, and why the point could not be run if it was invalid
+func WriteCSV(w io.Writer, dims []*Dimension, rows []Row) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{"point"}
+	for _, dim := range dims {
+		header = append(header, dim.Field)
+	}
+	header = append(header, "runs")
+	for _, name := range metrics.HeadlineMetrics {
+		header = append(header, name+"_mean", name+"_ci_lower", name+"_ci_upper")
+	}
+	header = append(header, "error")
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("could not write sweep header: %w", err)
+	}
+
+	for i, row := range rows {
+		record := []string{strconv.Itoa(i + 1)}
+		for _, assignment := range row.Point {
+			record = append(record, assignment.Value)
+		}
+		if row.Invalid != nil {
+			record = append(record, "0")
+			for range metrics.HeadlineMetrics {
+				record = append(record, "", "", "")
+			}
+			record = append(record, row.Invalid.Error())
+			if err := writer.Write(record); err != nil {
+				return fmt.Errorf("could not write sweep row: %w", err)
+			}
+			continue
+		}
+
+		record = append(record, strconv.Itoa(row.Summary.Runs))
+		summaries := make(map[string]metrics.MetricSummary, len(row.Summary.Metrics))
+		for _, m := range row.Summary.Metrics {
+			summaries[m.Name] = m
+		}
+		// Metrics a summary left out, such as the memory of parallel runs,
+		// stay empty
+		for _, name := range metrics.HeadlineMetrics {
+			m, ok := summaries[name]
+			if !ok {
+				record = append(record, "", "", "")
+				continue
+			}
+			record = append(record, formatFloat(m.Mean), formatFloat(m.CI.Lower), formatFloat(m.CI.Upper))
+		}
+		record = append(record, "")
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("could not write sweep row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// formatFloat prints floats in the shortest form that round-trips
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
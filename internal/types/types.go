@@ -2,6 +2,8 @@ package types
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"time"
 )
 
@@ -16,6 +18,83 @@ type BidRequest struct {
 	Attributes []float64     `json:"attributes"`
 	Timeout    time.Duration `json:"timeout"`
 	Timestamp  time.Time     `json:"timestamp"`
+
+	// Open auctions only
+	Round     int       `json:"round,omitempty"`
+	MinBid    float64   `json:"min_bid,omitempty"`    // lowest acceptable bid
+	Leader    string    `json:"leader,omitempty"`     // bidder holding the standing high bid
+	Deadline  time.Time `json:"deadline,omitempty"`   // current close, which late bids may extend
+	NextRound time.Time `json:"next_round,omitempty"` // when a bidder that passes is asked again; zero in the last round
+}
+
+// Open reports whether the request is a round of an open auction
+func (r *BidRequest) Open() bool {
+	return r.Round > 0
+}
+
+// Notice kinds, after the win (nurl), loss (lurl) and billing (burl)
+// notices of OpenRTB
+const (
+	NoticeWin     = "win"
+	NoticeLoss    = "loss"
+	NoticeBilling = "billing"
+)
+
+// NoticeKinds lists the notice kinds in the order they are sent
+var NoticeKinds = []string{NoticeWin, NoticeLoss, NoticeBilling}
+
+// Reasons a valid bid lost; a bid that failed validation loses with its
+// Reject reason
+const (
+	LossHigherBid    = "higher_bid"    // another bidder bid more
+	LossTieBreak     = "tie_break"     // tied for the win and lost the tie-break
+	LossBelowReserve = "below_reserve" // under the auction's reserve price
+	LossBudget       = "budget"        // won, but the bidder's budget could not cover the price
+	LossNoSale       = "no_sale"       // the auction closed without charging anyone
+)
+
+// Notice tells a bidder the outcome of its bid in an auction
+type Notice struct {
+	Kind          string    `json:"kind"`
+	AuctionID     string    `json:"auction_id"`
+	BidderID      string    `json:"bidder_id"`
+	Bid           float64   `json:"bid"`              // the bidder's highest bid
+	Price         float64   `json:"price,omitempty"`  // what the bidder pays; win and billing only
+	ClearingPrice float64   `json:"clearing_price"`   // what the winner paid; 0 if nobody won
+	Reason        string    `json:"reason,omitempty"` // loss only
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// WinListener is implemented by bidders that want to hear when they win
+type WinListener interface {
+	NotifyWin(ctx context.Context, notice *Notice) error
+}
+
+// LossListener is implemented by bidders that want to hear when and why
+// they lose
+type LossListener interface {
+	NotifyLoss(ctx context.Context, notice *Notice) error
+}
+
+// BillingListener is implemented by bidders that want to hear when they
+// are charged for a win
+type BillingListener interface {
+	NotifyBilling(ctx context.Context, notice *Notice) error
+}
+
+// Wrapper is implemented by bidders that wrap another bidder, like a fault
+// injector, without changing which notices it listens for. Notices go to
+// the innermost bidder.
+type Wrapper interface {
+	Unwrap() Bidder
+}
+
+// Forgetter is implemented by bidders that keep state about an auction
+// until they hear how it ended. Forget is called instead of any notice when
+// the bidder will not hear, like when its bid was lost or the auction was
+// cut short.
+type Forgetter interface {
+	Forget(auctionID string)
 }
 
 // BidResponse contains a bidder's response
@@ -24,28 +103,149 @@ type BidResponse struct {
 	AuctionID string    `json:"auction_id"`
 	Amount    float64   `json:"amount"`
 	Timestamp time.Time `json:"timestamp"`
+	Withdrawn bool      `json:"withdrawn,omitempty"` // leaves an open auction; not a bid
+
+	// Notice URLs of remote bidders, fetched with OpenRTB macros filled in
+	NURL string `json:"nurl,omitempty"`
+	LURL string `json:"lurl,omitempty"`
+	BURL string `json:"burl,omitempty"`
 }
 
 // Attribute represents an auction object characteristic
 type Attribute struct {
 	ID    int     `json:"id"`
-	Value float64 `json:"value"`
+	Name  string  `json:"name,omitempty"`
+	Value float64 `json:"value"`           // the category's index for categorical attributes, 1 or 0 for booleans
+	Label string  `json:"label,omitempty"` // the category, or true or false
 }
 
 // Bid represents a bid from a bidder
 type Bid struct {
-	BidderID  string    `json:"bidder_id"`
-	Amount    float64   `json:"amount"`
-	Timestamp time.Time `json:"timestamp"`
+	BidderID  string        `json:"bidder_id"`
+	Amount    float64       `json:"amount"`
+	Timestamp time.Time     `json:"timestamp"`
+	Latency   time.Duration `json:"latency"`
+}
+
+// Reasons a bid is rejected
+const (
+	RejectAuctionMismatch = "auction_mismatch" // addressed to another auction
+	RejectInvalidAmount   = "invalid_amount"   // not positive and finite
+	RejectBelowMin        = "below_min"
+	RejectAboveMax        = "above_max"
+	RejectOffIncrement    = "off_increment" // not a whole multiple of the increment
+	RejectOverBudget      = "over_budget"
+	RejectLate            = "late" // arrived after the auction closed
+	RejectUnregistered    = "unregistered"
+	RejectBidderMismatch  = "bidder_mismatch" // answered in another registered bidder's name
+	RejectDuplicate       = "duplicate"
+	RejectOutbid          = "outbid" // an open auction's standing bid is higher
+)
+
+// RejectReasons lists the rejection reasons in the order bids are checked
+var RejectReasons = []string{
+	RejectAuctionMismatch, RejectInvalidAmount, RejectUnregistered, RejectBidderMismatch, RejectLate,
+	RejectBelowMin, RejectAboveMax, RejectOffIncrement, RejectOverBudget, RejectDuplicate,
+	RejectOutbid,
+}
+
+// RejectedBid is a bid response that did not pass validation
+type RejectedBid struct {
+	BidderID  string        `json:"bidder_id"`
+	AuctionID string        `json:"auction_id"` // as sent by the bidder
+	Amount    float64       `json:"amount"`     // 0 if not finite, see RawAmount
+	RawAmount string        `json:"raw_amount,omitempty"`
+	Timestamp time.Time     `json:"timestamp"`
+	Latency   time.Duration `json:"latency"`
+	Reason    string        `json:"reason"`
+}
+
+// Bidder faults observed while collecting bids
+const (
+	FaultTimeout   = "timeout"   // no answer before the auction closed
+	FaultError     = "error"     // the bidder returned an error
+	FaultPanic     = "panic"     // the bidder panicked
+	FaultMalformed = "malformed" // the response could not be used as a bid
+)
+
+// FaultKinds lists the observed fault kinds in report order
+var FaultKinds = []string{FaultTimeout, FaultError, FaultPanic, FaultMalformed}
+
+// CollusionGroup is a set of bidders acting together, the ground truth
+// that collusion detection is scored against
+type CollusionGroup struct {
+	ID       string   `json:"id"`
+	Kind     string   `json:"kind"`
+	Strategy string   `json:"strategy"`
+	Members  []string `json:"members"` // a suppression ring's leader first
+}
+
+// Tie records bids that tied for the win and how the tie was broken
+type Tie struct {
+	Policy  string   `json:"policy"`
+	Amount  float64  `json:"amount"`
+	Bidders []string `json:"bidders"`          // in the order the policy ranked them
+	Shares  []Share  `json:"shares,omitempty"` // split allocations only
+}
+
+// Share is one bidder's part of an item split between tied bidders
+type Share struct {
+	BidderID string  `json:"bidder_id"`
+	Fraction float64 `json:"fraction"`
+	Price    float64 `json:"price"`
 }
 
 // AuctionResult contains the final outcome of an auction
 type AuctionResult struct {
-	AuctionID string        `json:"auction_id"`
-	Winner    *Bid          `json:"winner,omitempty"`
-	TotalBids int           `json:"total_bids"`
-	Duration  time.Duration `json:"duration"`
-	Error     error         `json:"error,omitempty"`
-	StartTime time.Time     `json:"start_time"`
-	EndTime   time.Time     `json:"end_time"`
+	AuctionID     string         `json:"auction_id"`
+	ItemID        string         `json:"item_id,omitempty"` // catalog item auctioned, if any
+	Attributes    []Attribute    `json:"attributes,omitempty"`
+	ReservePrice  float64        `json:"reserve_price"`
+	Winner        *Bid           `json:"winner,omitempty"`
+	ClearingPrice float64        `json:"clearing_price"`
+	Tie           *Tie           `json:"tie,omitempty"`
+	Extensions    int            `json:"extensions,omitempty"` // soft-close extensions of an open auction
+	Bids          []Bid          `json:"bids,omitempty"`
+	Rejected      []RejectedBid  `json:"rejected,omitempty"` // bids that failed validation
+	TotalBids     int            `json:"total_bids"`
+	Duration      time.Duration  `json:"duration"`
+	QueueDelay    time.Duration  `json:"queue_delay,omitempty"`
+	Aborted       bool           `json:"aborted,omitempty"` // closed early because the run was stopped
+	Faults        map[string]int `json:"faults,omitempty"`  // failed bidder requests by fault kind
+	Error         error          `json:"error,omitempty"`   // encoded as its message
+	StartTime     time.Time      `json:"start_time"`
+	EndTime       time.Time      `json:"end_time"`
+}
+
+// auctionResultJSON is the encoding of an AuctionResult, with the error
+// replaced by its message since error values do not survive JSON
+type auctionResultJSON struct {
+	*plainAuctionResult
+	Error string `json:"error,omitempty"`
+}
+
+// plainAuctionResult has the fields of AuctionResult without its methods
+type plainAuctionResult AuctionResult
+
+// MarshalJSON implements json.Marshaler
+func (r AuctionResult) MarshalJSON() ([]byte, error) {
+	encoded := auctionResultJSON{plainAuctionResult: (*plainAuctionResult)(&r)}
+	if r.Error != nil {
+		encoded.Error = r.Error.Error()
+	}
+	return json.Marshal(encoded)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. A decoded error keeps only its
+// message, like the errors of stored runs.
+func (r *AuctionResult) UnmarshalJSON(data []byte) error {
+	decoded := auctionResultJSON{plainAuctionResult: (*plainAuctionResult)(r)}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	r.Error = nil
+	if decoded.Error != "" {
+		r.Error = errors.New(decoded.Error)
+	}
+	return nil
 }
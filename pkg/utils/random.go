@@ -5,11 +5,6 @@ import (
 	"time"
 )
 
-// InitRandom seeds the random number generator
-func InitRandom() {
-	rand.New(rand.NewSource(time.Now().UnixNano()))
-}
-
 // RandomFloat returns a random float between min and max
 func RandomFloat(min, max float64) float64 {
 	return min + rand.Float64()*(max-min)
@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sync"
+)
+
+// RNG is a seeded random source that is safe for concurrent use. It counts
+// the values drawn from it, so its position in the stream can be saved and
+// restored.
+type RNG struct {
+	mu     sync.Mutex
+	seed   int64
+	source *countingSource
+	rand   *rand.Rand
+}
+
+// NewRNG creates a random source with a fixed seed
+func NewRNG(seed int64) *RNG {
+	source := &countingSource{src: rand.NewSource(seed).(rand.Source64)}
+	return &RNG{seed: seed, source: source, rand: rand.New(source)}
+}
+
+// countingSource counts the values drawn from the underlying source
+type countingSource struct {
+	src   rand.Source64
+	drawn uint64
+}
+
+func (s *countingSource) Int63() int64 {
+	s.drawn++
+	return s.src.Int63()
+}
+
+func (s *countingSource) Uint64() uint64 {
+	s.drawn++
+	return s.src.Uint64()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.drawn = 0
+}
+
+// Position returns the number of values drawn from the stream so far
+func (r *RNG) Position() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.source.drawn
+}
+
+// Seek moves the stream to pos, as if pos values had been drawn since it
+// was seeded
+func (r *RNG) Seek(pos uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if pos < r.source.drawn {
+		r.source.Seed(r.seed)
+		r.rand = rand.New(r.source)
+	}
+	for r.source.drawn < pos {
+		r.source.Int63()
+	}
+}
+
+// DeriveSeed mixes a base seed with labels into an independent stream seed,
+// so components can draw from their own sequence without sharing state
+func DeriveSeed(seed int64, labels ...string) int64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	for i := range buf {
+		buf[i] = byte(seed >> (8 * i))
+	}
+	h.Write(buf[:])
+	for _, label := range labels {
+		h.Write([]byte{0})
+		h.Write([]byte(label))
+	}
+	return int64(mix64(h.Sum64()))
+}
+
+// mix64 is the splitmix64 finalizer; it spreads small input differences
+// across all output bits
+func mix64(z uint64) uint64 {
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Float64 returns a random float in [0.0, 1.0)
+func (r *RNG) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.Float64()
+}
+
+// Intn returns a random integer in [0, n)
+func (r *RNG) Intn(n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.Intn(n)
+}
+
+// Perm returns a random permutation of the integers [0, n)
+func (r *RNG) Perm(n int) []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.Perm(n)
+}
+
+// NormFloat64 returns a standard normally distributed float
+func (r *RNG) NormFloat64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.NormFloat64()
+}
+
+// ExpFloat64 returns an exponentially distributed float with rate 1
+func (r *RNG) ExpFloat64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.ExpFloat64()
+}
+
+// RandomFloat returns a random float between min and max
+func (r *RNG) RandomFloat(min, max float64) float64 {
+	return min + r.Float64()*(max-min)
+}
+
+// RandomInt returns a random integer between min and max
+func (r *RNG) RandomInt(min, max int) int {
+	return min + r.Intn(max-min+1)
+}
+
+// RandomChance returns true with the given probability (0.0 to 1.0)
+func (r *RNG) RandomChance(probability float64) bool {
+	return r.Float64() < probability
+}